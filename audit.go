@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"loanpro-mcp-server/audit"
+	"loanpro-mcp-server/auth"
+	"loanpro-mcp-server/redact"
+	"loanpro-mcp-server/requestid"
+	"loanpro-mcp-server/tools"
+	"loanpro-mcp-server/transport"
+)
+
+// toolAuditor records a tenant's tool calls in the audit log
+type toolAuditor struct {
+	log    *audit.Log
+	tenant string
+}
+
+// AuditToolCall implements tools.Auditor
+func (a *toolAuditor) AuditToolCall(ctx context.Context, call tools.Invocation) error {
+	return a.log.Append(newAuditEntry(ctx, a.tenant, call))
+}
+
+// newAuditEntry describes a tool call for the audit log. Arguments are masked with
+// every redaction rule, like logs.
+func newAuditEntry(ctx context.Context, tenant string, call tools.Invocation) *audit.Entry {
+	entry := &audit.Entry{
+		Session:     transport.SessionFromContext(ctx),
+		RequestID:   requestid.FromContext(ctx),
+		Tenant:      tenant,
+		Tool:        call.Tool,
+		LoanIDs:     call.LoanIDs,
+		CustomerIDs: call.CustomerIDs,
+		Status:      audit.StatusOK,
+		LatencyMS:   float64(call.Duration.Microseconds()) / 1000,
+	}
+	if arguments, ok := redact.Default().Value(call.Arguments).(map[string]any); ok && len(arguments) > 0 {
+		entry.Arguments = arguments
+	}
+	if principal := auth.PrincipalFromContext(ctx); principal != nil {
+		entry.Principal, entry.AuthMethod, entry.Role = principal.Subject, principal.Method, principal.Role
+	}
+	if policy := tools.PolicyFromContext(ctx); policy != nil {
+		entry.Role = policy.Role
+	}
+	if call.Error != nil {
+		entry.Status = audit.StatusError
+		if call.Error.Code == errorCodeForbidden {
+			entry.Status = audit.StatusDenied
+		}
+		entry.ErrorCode, entry.Error = call.Error.Code, call.Error.Message
+	}
+	return entry
+}
+
+// SetAuditLog records every tool call, including rejected ones, in log
+func (s *MCPServer) SetAuditLog(log *audit.Log) {
+	s.auditLog = log
+	for _, name := range s.tenants.Names() {
+		tenant, _ := s.tenants.Get(name)
+		tenant.toolManager.SetAuditor(&toolAuditor{log: log, tenant: name})
+	}
+}
+
+// rejectTool refuses a tool call before it reaches a tenant, logging and auditing
+// the rejection
+func (s *MCPServer) rejectTool(ctx context.Context, req transport.MCPRequest, tenant string, code int, err error) transport.MCPResponse {
+	toolName, _ := req.Params["name"].(string)
+	arguments, _ := req.Params["arguments"].(map[string]any)
+	slog.WarnContext(ctx, "Rejected tool call", "tool", toolName, "tenant", tenant, "principal", principalName(ctx), "error", err)
+
+	response := transport.MCPResponse{
+		JSONRPC: "2.0",
+		Error:   &transport.MCPError{Code: code, Message: err.Error()},
+		ID:      req.ID,
+	}
+	if s.auditLog != nil {
+		call := tools.Invocation{Tool: toolName, Arguments: arguments, Error: &tools.MCPError{Code: code, Message: err.Error()}}
+		if err := s.auditLog.Append(newAuditEntry(ctx, tenant, call)); err != nil {
+			slog.ErrorContext(ctx, "Failed to record rejected tool call in the audit log", "tool", toolName, "error", err)
+		}
+	}
+	return response
+}
+
+// loadAuditLog opens the audit log named by MCP_AUDIT_LOG_FILE, or returns nil when
+// auditing is off
+func loadAuditLog() (*audit.Log, error) {
+	path := os.Getenv("MCP_AUDIT_LOG_FILE")
+	if path == "" {
+		return nil, nil
+	}
+	return audit.Open(path)
+}
+
+// verifyAudit implements the verify-audit subcommand, which checks the hash chain of
+// an audit log
+func verifyAudit(args []string) error {
+	flags := flag.NewFlagSet("verify-audit", flag.ContinueOnError)
+	path := flags.String("file", os.Getenv("MCP_AUDIT_LOG_FILE"), "Audit log to check; MCP_AUDIT_LOG_FILE by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return fmt.Errorf("no audit log: pass -file or set MCP_AUDIT_LOG_FILE")
+	}
+
+	start := time.Now()
+	count, last, err := audit.VerifyFile(*path)
+	if err != nil {
+		return fmt.Errorf("%s: %w (%d entries verified before it)", *path, err, count)
+	}
+	fmt.Printf("%s: %d entries verified in %s\nLast hash: %s\n", *path, count, time.Since(start).Round(time.Millisecond), last)
+	return nil
+}
@@ -0,0 +1,225 @@
+// Package audit writes a tamper-evident record of tool calls: an append-only file
+// of JSON lines, each holding the SHA-256 hash of the line before it, so editing,
+// removing or reordering entries breaks the chain and is caught by Verify.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry statuses
+const (
+	StatusOK     = "ok"     // The call succeeded
+	StatusError  = "error"  // The call failed, e.g. the loan wasn't found
+	StatusDenied = "denied" // The caller wasn't allowed to make the call
+)
+
+// ErrBrokenChain is returned by Verify when an entry was changed, removed or reordered
+var ErrBrokenChain = errors.New("audit chain broken")
+
+// Entry is one tool call in the audit log
+type Entry struct {
+	Seq         int64          `json:"seq"`                    // Position in the log, from 1
+	Time        time.Time      `json:"time"`                   // When the call finished, in UTC
+	Principal   string         `json:"principal,omitempty"`    // Authenticated caller, empty when authentication is off
+	AuthMethod  string         `json:"auth_method,omitempty"`  // "api_key", "hmac" or "oauth"
+	Role        string         `json:"role,omitempty"`         // Caller's role in the access policy
+	Session     string         `json:"session,omitempty"`      // MCP session the call was made in
+	RequestID   string         `json:"request_id,omitempty"`   // MCP request the call was made in, as in the logs
+	Tenant      string         `json:"tenant,omitempty"`       // LoanPro tenant queried
+	Tool        string         `json:"tool"`                   // Tool name
+	Arguments   map[string]any `json:"arguments,omitempty"`    // Tool arguments, with personal data masked
+	LoanIDs     []string       `json:"loan_ids,omitempty"`     // Loans whose data was returned
+	CustomerIDs []string       `json:"customer_ids,omitempty"` // Customers whose data was returned
+	Status      string         `json:"status"`                 // StatusOK, StatusError or StatusDenied
+	ErrorCode   int            `json:"error_code,omitempty"`   // JSON-RPC error code of failed calls
+	Error       string         `json:"error,omitempty"`        // Error message of failed calls
+	LatencyMS   float64        `json:"latency_ms"`             // Time taken by the call
+	PrevHash    string         `json:"prev_hash"`              // Hash of the previous entry, empty for the first
+	Hash        string         `json:"hash"`                   // SHA-256 of this entry with Hash empty, hex encoded
+}
+
+// computeHash returns the hash of the entry's JSON encoding with Hash left empty
+func (e Entry) computeHash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Log appends entries to an audit log file. It is safe for concurrent use.
+type Log struct {
+	mu       sync.Mutex
+	file     *os.File
+	seq      int64
+	lastHash string
+}
+
+// Open opens the audit log at path for appending, creating it if needed. An existing
+// log is continued from its last entry.
+func Open(path string) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	l := &Log{file: file}
+	last, err := lastEntry(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("invalid audit log %s: %w", path, err)
+	}
+	if last != nil {
+		l.seq, l.lastHash = last.Seq, last.Hash
+	}
+	return l, nil
+}
+
+// lastEntry returns the final entry of a log, or nil when it is empty
+func lastEntry(r io.Reader) (*Entry, error) {
+	var last []byte
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			last = line
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if last == nil {
+		return nil, nil
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(last, &entry); err != nil {
+		return nil, fmt.Errorf("last entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// Append completes an entry's sequence number, time and hashes, and writes it. The
+// entry is synced to disk before Append returns.
+func (l *Log) Append(entry *Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Time = entry.Time.UTC()
+	entry.Seq = l.seq + 1
+	entry.PrevHash = l.lastHash
+	hash, err := entry.computeHash()
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	entry.Hash = hash
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	l.seq, l.lastHash = entry.Seq, entry.Hash
+	return nil
+}
+
+// Close closes the log file
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// Verify checks the hash chain of an audit log and returns the number of entries and
+// the hash of the last. Entries cut from the end of a log leave a valid chain, so
+// keep the last hash somewhere else to catch that.
+func Verify(r io.Reader) (int, string, error) {
+	count := 0
+	var prev Entry
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return count, prev.Hash, err
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			entry, verr := decodeEntry(data)
+			if verr != nil {
+				return count, prev.Hash, fmt.Errorf("%w at line %d: %v", ErrBrokenChain, line, verr)
+			}
+			if verr := verifyEntry(entry, prev); verr != nil {
+				return count, prev.Hash, fmt.Errorf("%w at line %d (seq %d): %v", ErrBrokenChain, line, entry.Seq, verr)
+			}
+			prev = entry
+			count++
+		}
+		if err == io.EOF {
+			return count, prev.Hash, nil
+		}
+	}
+}
+
+// VerifyFile checks the hash chain of the audit log at path
+func VerifyFile(path string) (int, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+	return Verify(file)
+}
+
+// decodeEntry decodes an entry so that it encodes back to the bytes that were
+// hashed: numbers keep their text and unknown fields are rejected.
+func decodeEntry(data []byte) (Entry, error) {
+	var entry Entry
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&entry); err != nil {
+		return entry, fmt.Errorf("invalid entry: %w", err)
+	}
+	return entry, nil
+}
+
+// verifyEntry checks an entry's hash and its link to the entry before it, which is
+// the zero Entry for the first
+func verifyEntry(entry, prev Entry) error {
+	hash, err := entry.computeHash()
+	if err != nil {
+		return err
+	}
+	switch {
+	case hash != entry.Hash:
+		return errors.New("entry hash doesn't match its contents")
+	case entry.Seq != prev.Seq+1:
+		return fmt.Errorf("expected seq %d", prev.Seq+1)
+	case entry.PrevHash != prev.Hash:
+		return errors.New("prev_hash doesn't match the previous entry")
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// writeLog appends entries for the tools named to a new log and returns its path
+func writeLog(t *testing.T, toolNames ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	log, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	for _, tool := range toolNames {
+		entry := &Entry{
+			Principal: "agent-7",
+			Tool:      tool,
+			Arguments: map[string]any{"loan_id": "101", "limit": float64(50), "email": "[redacted:email]"},
+			LoanIDs:   []string{"101"},
+			Status:    StatusOK,
+			LatencyMS: 12.5,
+		}
+		if err := log.Append(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestLog_Append(t *testing.T) {
+	path := writeLog(t, "get_loan", "get_loan_payments")
+
+	// Reopening continues the chain
+	log, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &Entry{Tool: "get_customer", CustomerIDs: []string{"3"}, Status: StatusError, ErrorCode: -1, Error: "not found"}
+	if err := log.Append(entry); err != nil {
+		t.Fatal(err)
+	}
+	log.Close()
+	if entry.Seq != 3 || entry.PrevHash == "" || entry.Time.IsZero() {
+		t.Errorf("Expected the third entry to be linked to the second, got %+v", entry)
+	}
+
+	count, last, err := VerifyFile(path)
+	if err != nil {
+		t.Fatalf("Expected a valid chain, got %v", err)
+	}
+	if count != 3 || last != entry.Hash {
+		t.Errorf("Expected 3 entries ending in %s, got %d ending in %s", entry.Hash, count, last)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected the log to be readable by its owner only, got %v", info.Mode().Perm())
+	}
+}
+
+func TestLog_AppendConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	log, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := log.Append(&Entry{Tool: "get_loan", Status: StatusOK}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	log.Close()
+
+	if count, _, err := VerifyFile(path); err != nil || count != 20 {
+		t.Errorf("Expected 20 chained entries, got %d: %v", count, err)
+	}
+}
+
+func TestVerify_Tampering(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(lines []string) []string
+		expected string
+	}{
+		{"edited field", func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], `"principal":"agent-7"`, `"principal":"someone-else"`, 1)
+			return lines
+		}, "line 2 (seq 2): entry hash doesn't match"},
+		{"edited number", func(lines []string) []string {
+			lines[0] = strings.Replace(lines[0], `"limit":50`, `"limit":500`, 1)
+			return lines
+		}, "line 1 (seq 1): entry hash doesn't match"},
+		{"removed entry", func(lines []string) []string {
+			return append(lines[:1], lines[2:]...)
+		}, "line 2 (seq 3): expected seq 2"},
+		{"removed first entry", func(lines []string) []string {
+			return lines[1:]
+		}, "line 1 (seq 2): expected seq 1"},
+		{"reordered entries", func(lines []string) []string {
+			lines[1], lines[2] = lines[2], lines[1]
+			return lines
+		}, "line 2 (seq 3): expected seq 2"},
+		{"added field", func(lines []string) []string {
+			lines[2] = strings.Replace(lines[2], `{"seq"`, `{"note":"x","seq"`, 1)
+			return lines
+		}, `line 3: invalid entry`},
+		{"rehashed entry", func(lines []string) []string {
+			// Recomputing an edited entry's hash breaks the link from the next one
+			entry, _ := decodeEntry([]byte(lines[0]))
+			entry.Principal = "someone-else"
+			entry.Hash, _ = entry.computeHash()
+			data, _ := json.Marshal(entry)
+			lines[0] = string(data)
+			return lines
+		}, "line 2 (seq 2): prev_hash doesn't match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeLog(t, "get_loan", "get_loan_payments", "get_loan_transactions")
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := tt.tamper(strings.Split(strings.TrimSpace(string(data)), "\n"))
+
+			_, _, err = Verify(strings.NewReader(strings.Join(lines, "\n") + "\n"))
+			if !errors.Is(err, ErrBrokenChain) || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected broken chain error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestOpen_InvalidLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	if err := os.WriteFile(path, []byte("not json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil || !strings.Contains(err.Error(), "invalid audit log") {
+		t.Errorf("Expected an invalid audit log error, got %v", err)
+	}
+}
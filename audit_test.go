@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"loanpro-mcp-server/audit"
+	"loanpro-mcp-server/auth"
+	"loanpro-mcp-server/loanpro"
+	"loanpro-mcp-server/loanpro/fake"
+	"loanpro-mcp-server/requestid"
+	"loanpro-mcp-server/transport"
+)
+
+func TestMCPServer_AuditLog(t *testing.T) {
+	fakeServer := httptest.NewServer(fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID))
+	defer fakeServer.Close()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := audit.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewMCPServer(loanpro.NewClient(fakeServer.URL, fake.DefaultAPIKey, fake.DefaultTenantID))
+	server.SetPolicy(&PolicyFile{DefaultRole: "collections", Roles: map[string]*Role{
+		"collections": {Tools: []string{"get_loan", "search_customers"}},
+	}})
+	server.SetAuditLog(auditLog)
+
+	ctx := transport.WithSession(auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "dialer", Method: "api_key"}), "session-1")
+	ctx = requestid.WithID(ctx, "request-1")
+	call := func(tool string, arguments map[string]any) {
+		server.HandleMCPRequestContext(ctx, transport.MCPRequest{
+			JSONRPC: "2.0",
+			Method:  "tools/call",
+			Params:  map[string]any{"name": tool, "arguments": arguments},
+			ID:      1,
+		})
+	}
+	call("get_loan", map[string]any{"loan_id": "103"})
+	call("search_customers", map[string]any{"email": "maria.garcia@example.com"})
+	call("get_loan_payments", map[string]any{"loan_id": "101"})
+	call("get_customer", map[string]any{"customer_id": "3"})
+	call("get_loan", map[string]any{"loan_id": "103", "tenant": "cards"})
+	auditLog.Close()
+
+	expected := []struct {
+		tool      string
+		status    string
+		loans     string
+		customers string
+	}{
+		{"get_loan", audit.StatusOK, "103", ""},
+		{"search_customers", audit.StatusOK, "", "3"},
+		{"get_loan_payments", audit.StatusDenied, "", ""},
+		{"get_customer", audit.StatusDenied, "", ""},
+		{"get_loan", audit.StatusError, "", ""},
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var entries []audit.Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry audit.Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+		if strings.Contains(scanner.Text(), "maria.garcia@example.com") {
+			t.Errorf("Expected arguments to be redacted, got %s", scanner.Text())
+		}
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i, want := range expected {
+		entry := entries[i]
+		if entry.Tool != want.tool || entry.Status != want.status ||
+			strings.Join(entry.LoanIDs, ",") != want.loans || strings.Join(entry.CustomerIDs, ",") != want.customers {
+			t.Errorf("Entry %d: expected %+v, got %+v", i+1, want, entry)
+		}
+		if entry.Principal != "dialer" || entry.AuthMethod != "api_key" || entry.Session != "session-1" || entry.RequestID != "request-1" || entry.Role != "collections" {
+			t.Errorf("Entry %d: expected the caller, session, request and role to be recorded, got %+v", i+1, entry)
+		}
+	}
+	if entries[0].Tenant != defaultTenantName || entries[4].Tenant != "cards" {
+		t.Errorf("Expected the tenants queried to be recorded, got %q and %q", entries[0].Tenant, entries[4].Tenant)
+	}
+
+	if err := verifyAudit([]string{"-file", path}); err != nil {
+		t.Errorf("Expected the audit log to verify, got %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	tampered := filepath.Join(t.TempDir(), "tampered.log")
+	os.WriteFile(tampered, []byte(strings.Replace(string(data), `"loan_ids":["103"]`, `"loan_ids":["104"]`, 1)), 0o600)
+	if err := verifyAudit([]string{"-file", tampered}); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected the tampered entry to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// APIKey is a static API key. Only the SHA-256 of the key is stored, so the keys
+// file doesn't hold usable credentials.
+type APIKey struct {
+	Name   string   `json:"name"`
+	SHA256 string   `json:"sha256"` // Hex SHA-256 of the key, see HashAPIKey
+	Tenant string   `json:"tenant,omitempty"`
+	Role   string   `json:"role,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// APIKeysFile is the format of the file named by MCP_AUTH_KEYS_FILE
+type APIKeysFile struct {
+	Keys []APIKey `json:"keys"`
+}
+
+// APIKeys authenticates static API keys against their hashes
+type APIKeys struct {
+	keys   []APIKey
+	hashes [][]byte
+}
+
+// HashAPIKey returns the hex SHA-256 of a key, as stored in the keys file
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewAPIKeys creates an authenticator for the given keys
+func NewAPIKeys(keys []APIKey) (*APIKeys, error) {
+	a := &APIKeys{keys: keys}
+	names := make(map[string]bool)
+	for _, key := range keys {
+		hash, err := hex.DecodeString(key.SHA256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("API key %q: sha256 must be 64 hex digits", key.Name)
+		}
+		if key.Name == "" || names[key.Name] {
+			return nil, fmt.Errorf("API key %q: names must be unique and non-empty", key.Name)
+		}
+		names[key.Name] = true
+		a.hashes = append(a.hashes, hash)
+	}
+	return a, nil
+}
+
+// LoadAPIKeys reads API keys from a JSON keys file
+func LoadAPIKeys(path string) (*APIKeys, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys file: %w", err)
+	}
+	var file APIKeysFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid API keys file %s: %w", path, err)
+	}
+	keys, err := NewAPIKeys(file.Keys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API keys file %s: %w", path, err)
+	}
+	return keys, nil
+}
+
+// Authenticate returns the principal for a known key. Every stored hash is compared
+// in constant time so response times don't reveal which keys exist.
+func (a *APIKeys) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	sum := sha256.Sum256([]byte(token))
+	match := -1
+	for i, hash := range a.hashes {
+		if subtle.ConstantTimeCompare(sum[:], hash) == 1 {
+			match = i
+		}
+	}
+	if match < 0 {
+		return nil, ErrInvalidToken
+	}
+
+	key := a.keys[match]
+	return &Principal{Subject: key.Name, Method: "api_key", Tenant: key.Tenant, Role: key.Role, Scopes: key.Scopes}, nil
+}
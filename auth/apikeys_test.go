@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAPIKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string // Error substring, empty for success
+	}{
+		{"valid", `{"keys": [{"name": "bot", "sha256": "` + HashAPIKey("k") + `", "tenant": "auto", "scopes": ["loans:read"]}]}`, ""},
+		{"invalid JSON", `{"keys": [`, "invalid API keys file"},
+		{"plaintext key", `{"keys": [{"name": "bot", "sha256": "k"}]}`, "64 hex digits"},
+		{"duplicate name", `{"keys": [{"name": "bot", "sha256": "` + HashAPIKey("a") + `"}, {"name": "bot", "sha256": "` + HashAPIKey("b") + `"}]}`, "unique"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keys.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			keys, err := LoadAPIKeys(path)
+			if tt.expected != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expected) {
+					t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			principal, err := keys.Authenticate(context.Background(), "k")
+			if err != nil {
+				t.Fatalf("Expected key to authenticate, got %v", err)
+			}
+			if principal.Subject != "bot" || principal.Tenant != "auto" || len(principal.Scopes) != 1 {
+				t.Errorf("Expected bot principal, got %+v", principal)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// DefaultRealm is the realm advertised in WWW-Authenticate challenges
+const DefaultRealm = "loanpro-mcp-server"
+
+var (
+	// ErrNoCredentials is returned when a request carries no bearer token
+	ErrNoCredentials = errors.New("missing bearer token")
+	// ErrInvalidToken is returned when a bearer token is not recognised
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrExpiredToken is returned when a signed token has expired
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject string   // Key name or token subject, e.g. "collections-bot"
+	Method  string   // How the caller authenticated: "api_key", "hmac" or "oauth"
+	Tenant  string   // LoanPro tenant the caller is restricted to, empty for any
+	Role    string   // Role in the access policy, empty for the policy's default role
+	Scopes  []string // Granted scopes, nil when the credential isn't limited by scope
+}
+
+// HasScope reports whether the principal was granted a scope. Principals whose
+// credential carries no scopes, such as API keys listed without any, have them all.
+func (p *Principal) HasScope(scope string) bool {
+	return p.Scopes == nil || slices.Contains(p.Scopes, scope)
+}
+
+// Authenticator validates the bearer token of a request
+type Authenticator interface {
+	// Authenticate returns the principal for a token, ErrInvalidToken when the token
+	// isn't one this authenticator issued, or another error when it is but is unusable
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
+
+// Chain tries each authenticator in turn, so API keys and signed tokens can be
+// accepted side by side
+type Chain []Authenticator
+
+// Authenticate returns the first principal accepted by an authenticator in the
+// chain. When all reject the token, the most specific reason given is returned.
+func (c Chain) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	reason := ErrInvalidToken
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(ctx, token)
+		if !errors.Is(err, ErrInvalidToken) {
+			return principal, err
+		}
+		if err != ErrInvalidToken {
+			reason = err
+		}
+	}
+	return nil, reason
+}
+
+// principalContextKey is the context key for the authenticated principal
+type principalContextKey struct{}
+
+// WithPrincipal attaches the authenticated principal to ctx
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated principal, or nil for
+// unauthenticated requests such as those over stdio
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return principal
+}
+
+// BearerToken returns the token from an "Authorization: Bearer" header
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// Options configures the WWW-Authenticate challenge sent by Middleware
+type Options struct {
+	Realm string // DefaultRealm when empty
+	// ResourceMetadata is the URL of the protected resource metadata, which tells
+	// OAuth clients where to get a token
+	ResourceMetadata string
+}
+
+// Middleware rejects requests without a valid bearer token with 401 and a
+// WWW-Authenticate challenge, and attaches the principal to the context of the
+// rest. CORS preflight requests pass through since browsers send them without
+// credentials.
+func Middleware(authenticator Authenticator, opts Options) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := BearerToken(r)
+			if !ok {
+				Challenge(w, opts, ErrNoCredentials)
+				return
+			}
+
+			principal, err := authenticator.Authenticate(r.Context(), token)
+			if err != nil {
+				slog.Warn("Rejected request with invalid credentials", "path", r.URL.Path, "remote", r.RemoteAddr, "error", err)
+				Challenge(w, opts, err)
+				return
+			}
+
+			slog.Debug("Authenticated request", "subject", principal.Subject, "method", principal.Method)
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// Challenge writes a 401 response with an RFC 6750 WWW-Authenticate header. Requests
+// that sent no token get a bare challenge; the rest are told the token is invalid.
+func Challenge(w http.ResponseWriter, opts Options, err error) {
+	realm := opts.Realm
+	if realm == "" {
+		realm = DefaultRealm
+	}
+	challenge := fmt.Sprintf("Bearer realm=%q", realm)
+	if opts.ResourceMetadata != "" {
+		challenge += fmt.Sprintf(", resource_metadata=%q", opts.ResourceMetadata)
+	}
+	if !errors.Is(err, ErrNoCredentials) {
+		challenge += fmt.Sprintf(", error=\"invalid_token\", error_description=%q", err.Error())
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	keys, err := NewAPIKeys([]APIKey{{Name: "bot", SHA256: HashAPIKey("secret-key"), Tenant: "auto"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var principal *Principal
+	handler := Middleware(keys, Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal = PrincipalFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		status        int
+		challenge     string
+		subject       string
+	}{
+		{"valid key", "POST", "Bearer secret-key", http.StatusOK, "", "bot"},
+		{"lower-case scheme", "POST", "bearer secret-key", http.StatusOK, "", "bot"},
+		{"missing header", "POST", "", http.StatusUnauthorized, `Bearer realm="loanpro-mcp-server"`, ""},
+		{"wrong scheme", "POST", "Basic c2VjcmV0LWtleQ==", http.StatusUnauthorized, `Bearer realm="loanpro-mcp-server"`, ""},
+		{"unknown key", "POST", "Bearer other-key", http.StatusUnauthorized, `error="invalid_token"`, ""},
+		{"preflight", "OPTIONS", "", http.StatusOK, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal = nil
+			req := httptest.NewRequest(tt.method, "/mcp", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if challenge := w.Header().Get("WWW-Authenticate"); !strings.Contains(challenge, tt.challenge) || (tt.challenge == "") != (challenge == "") {
+				t.Errorf("Expected challenge containing %q, got %q", tt.challenge, challenge)
+			}
+			if tt.subject != "" && (principal == nil || principal.Subject != tt.subject || principal.Tenant != "auto") {
+				t.Errorf("Expected principal %s in context, got %+v", tt.subject, principal)
+			}
+			if tt.subject == "" && principal != nil {
+				t.Errorf("Expected no principal, got %+v", principal)
+			}
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	keys, _ := NewAPIKeys([]APIKey{{Name: "bot", SHA256: HashAPIKey("secret-key")}})
+	tokens, _ := NewHMACTokens([]byte(strings.Repeat("s", 32)))
+	token, _ := tokens.Sign(TokenClaims{Subject: "analyst", ExpiresAt: 1})
+	chain := Chain{keys, tokens}
+
+	if principal, err := chain.Authenticate(context.Background(), "secret-key"); err != nil || principal.Method != "api_key" {
+		t.Errorf("Expected API key to be accepted, got %+v, %v", principal, err)
+	}
+	// A token recognised by one authenticator stops the chain with its error
+	if _, err := chain.Authenticate(context.Background(), token); err != ErrExpiredToken {
+		t.Errorf("Expected ErrExpiredToken, got %v", err)
+	}
+	if _, err := chain.Authenticate(context.Background(), "nope"); err != ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+}
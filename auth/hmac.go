@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// tokenPrefix marks signed tokens so they are told apart from API keys
+const tokenPrefix = "mcp1."
+
+// minSecretLength is the shortest HMAC secret accepted, so secrets can't be guessed
+const minSecretLength = 32
+
+// TokenClaims are the claims carried by a signed token
+type TokenClaims struct {
+	Subject   string   `json:"sub"`
+	Tenant    string   `json:"tenant,omitempty"`
+	Role      string   `json:"role,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	IssuedAt  int64    `json:"iat"`
+	ExpiresAt int64    `json:"exp,omitempty"` // Unix seconds, zero for no expiry
+}
+
+// HMACTokens issues and verifies tokens signed with a shared secret. A token is
+// "mcp1.<claims>.<signature>", with base64url JSON claims and an HMAC-SHA256
+// signature over the prefix and claims.
+type HMACTokens struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewHMACTokens creates a token issuer and verifier
+func NewHMACTokens(secret []byte) (*HMACTokens, error) {
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("HMAC secret must be at least %d bytes", minSecretLength)
+	}
+	return &HMACTokens{secret: secret, now: time.Now}, nil
+}
+
+// Sign returns a signed token for the claims. IssuedAt defaults to now.
+func (h *HMACTokens) Sign(claims TokenClaims) (string, error) {
+	if claims.Subject == "" {
+		return "", fmt.Errorf("token subject is required")
+	}
+	if claims.IssuedAt == 0 {
+		claims.IssuedAt = h.now().Unix()
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := tokenPrefix + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(h.sign(unsigned)), nil
+}
+
+// Authenticate verifies a signed token and returns its principal
+func (h *HMACTokens) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	if !strings.HasPrefix(token, tokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+	unsigned, signature, ok := strings.Cut(token[len(tokenPrefix):], ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	unsigned = tokenPrefix + unsigned
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, h.sign(unsigned)) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(unsigned[len(tokenPrefix):])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims TokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	if claims.ExpiresAt != 0 && h.now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+
+	return &Principal{Subject: claims.Subject, Method: "hmac", Tenant: claims.Tenant, Role: claims.Role, Scopes: claims.Scopes}, nil
+}
+
+// sign returns the HMAC-SHA256 of data
+func (h *HMACTokens) sign(data string) []byte {
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
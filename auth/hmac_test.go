@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHMACTokens(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tokens, err := NewHMACTokens([]byte(strings.Repeat("a", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens.now = func() time.Time { return now }
+	other, _ := NewHMACTokens([]byte(strings.Repeat("b", 32)))
+
+	valid, _ := tokens.Sign(TokenClaims{Subject: "analyst", Tenant: "cards", ExpiresAt: now.Add(time.Hour).Unix()})
+	expired, _ := tokens.Sign(TokenClaims{Subject: "analyst", ExpiresAt: now.Unix()})
+	forged, _ := other.Sign(TokenClaims{Subject: "analyst"})
+	claims, signature, _ := strings.Cut(strings.TrimPrefix(valid, tokenPrefix), ".")
+	tampered := tokenPrefix + claims + "x." + signature
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"valid", valid, nil},
+		{"expired", expired, ErrExpiredToken},
+		{"other secret", forged, ErrInvalidToken},
+		{"tampered claims", tampered, ErrInvalidToken},
+		{"no signature", tokenPrefix + claims, ErrInvalidToken},
+		{"not a signed token", "plain-api-key", ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := tokens.Authenticate(context.Background(), tt.token)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && (principal.Subject != "analyst" || principal.Tenant != "cards" || principal.Method != "hmac") {
+				t.Errorf("Expected analyst principal for cards, got %+v", principal)
+			}
+		})
+	}
+}
+
+func TestNewHMACTokens_ShortSecret(t *testing.T) {
+	if _, err := NewHMACTokens([]byte("short")); err == nil {
+		t.Error("Expected error for a short secret")
+	}
+}
@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultJWKSRefresh is how often a JWKS URL is fetched again to pick up new keys
+	DefaultJWKSRefresh = time.Hour
+	// minJWKSRefetch limits fetches triggered by tokens signed with unknown keys, so
+	// forged key IDs can't be used to flood the authorization server
+	minJWKSRefetch = time.Minute
+)
+
+// jsonWebKey is a public key in a JWKS document
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// KeySet holds the public keys used to verify access tokens. Keys come from a JWKS
+// file, read once, or a URL, fetched again periodically and when a token names a key
+// that isn't in the set.
+type KeySet struct {
+	url     string
+	refresh time.Duration
+	client  *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	fetching  chan struct{} // Closed when the fetch in progress ends, nil when there is none
+}
+
+// LoadKeySetFile reads a JWKS document from a file
+func LoadKeySetFile(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWKS file %s: %w", path, err)
+	}
+	return &KeySet{keys: keys}, nil
+}
+
+// NewKeySetURL creates a key set fetched from a JWKS URL. The first fetch happens
+// immediately so misconfiguration is reported at startup.
+func NewKeySetURL(ctx context.Context, url string, refresh time.Duration) (*KeySet, error) {
+	if refresh <= 0 {
+		refresh = DefaultJWKSRefresh
+	}
+	s := &KeySet{url: url, refresh: refresh, client: &http.Client{Timeout: 10 * time.Second}}
+	keys, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	return s, nil
+}
+
+// key returns the key with the given ID. A token without a key ID is accepted when
+// the set holds exactly one key. Known keys are served from the current set while it
+// is refreshed in the background; an unknown key waits for the refresh, which may
+// bring it, until ctx is done.
+func (s *KeySet) key(ctx context.Context, kid string) (crypto.PublicKey, bool) {
+	s.mu.Lock()
+	key, ok := s.lookup(kid)
+	if s.url == "" {
+		s.mu.Unlock()
+		return key, ok
+	}
+	since := time.Since(s.fetchedAt)
+	if since >= s.refresh || (!ok && since >= minJWKSRefetch) {
+		s.startFetch()
+	}
+	fetching := s.fetching
+	s.mu.Unlock()
+
+	if ok || fetching == nil {
+		return key, ok
+	}
+	select {
+	case <-fetching:
+	case <-ctx.Done():
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookup(kid)
+}
+
+// startFetch refreshes the key set in the background unless a refresh is already
+// running. The fetch isn't tied to the request that started it, so it completes for
+// every waiter even when that request ends first. s.mu must be held.
+func (s *KeySet) startFetch() {
+	if s.fetching != nil {
+		return
+	}
+	s.fetchedAt = time.Now()
+	done := make(chan struct{})
+	s.fetching = done
+
+	go func() {
+		defer close(done)
+		keys, err := s.fetch(context.Background())
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.fetching = nil
+		if err != nil {
+			// Keep serving the keys we have; the authorization server may be briefly down
+			slog.Error("Failed to refresh JWKS", "url", s.url, "error", err)
+			return
+		}
+		s.keys = keys
+	}()
+}
+
+// lookup finds a key in the current set. s.mu must be held.
+func (s *KeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// fetch reads the key set from the URL, bounded by the client's timeout
+func (s *KeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWKS URL: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: %s returned status %d", s.url, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWKS from %s: %w", s.url, err)
+	}
+	slog.Debug("Fetched JWKS", "url", s.url, "keys", len(keys))
+	return keys, nil
+}
+
+// parseJWKS decodes the signing keys of a JWKS document. Keys of unsupported types
+// are skipped so a set can mix in keys this server doesn't use.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			slog.Warn("Skipping JWKS key", "kid", jwk.Kid, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no usable signing keys")
+	}
+	return keys, nil
+}
+
+// publicKey decodes an RSA, EC or Ed25519 public key
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, fmt.Errorf("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// decodeBigInt decodes a base64url big-endian integer
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadKeySetFile(t *testing.T) {
+	keys := newTestKeys(t)
+	set := writeJWKS(t, keys.jwks())
+
+	for _, kid := range []string{"rsa", "ec", "ed"} {
+		if _, ok := set.key(context.Background(), kid); !ok {
+			t.Errorf("Expected key %s", kid)
+		}
+	}
+	// Symmetric keys can't verify tokens issued to third parties and are skipped
+	if _, ok := set.key(context.Background(), "shared"); ok {
+		t.Error("Expected oct key to be skipped")
+	}
+	// Without a key ID a token can only match a single-key set
+	if _, ok := set.key(context.Background(), ""); ok {
+		t.Error("Expected no key without a kid in a multi-key set")
+	}
+
+	path := filepath.Join(t.TempDir(), "empty.json")
+	os.WriteFile(path, []byte(`{"keys": [{"kty": "oct", "k": "c2VjcmV0"}]}`), 0o600)
+	if _, err := LoadKeySetFile(path); err == nil {
+		t.Error("Expected error for a JWKS without signing keys")
+	}
+}
+
+func TestKeySetURL_RefetchOnUnknownKey(t *testing.T) {
+	keys := newTestKeys(t)
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write(keys.jwks())
+	}))
+	defer server.Close()
+
+	set, err := NewKeySetURL(context.Background(), server.URL, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := set.key(context.Background(), "rsa"); !ok || fetches.Load() != 1 {
+		t.Errorf("Expected known key from the first fetch, got %d fetches", fetches.Load())
+	}
+
+	// An unknown key triggers a refetch, but not more than once a minute
+	set.fetchedAt = time.Now().Add(-2 * minJWKSRefetch)
+	set.key(context.Background(), "rotated")
+	set.key(context.Background(), "rotated")
+	if fetches.Load() != 2 {
+		t.Errorf("Expected 1 refetch for unknown keys, got %d fetches", fetches.Load())
+	}
+
+	// A failed refresh keeps the keys already fetched
+	server.Close()
+	set.fetchedAt = time.Now().Add(-2 * time.Hour)
+	if _, ok := set.key(context.Background(), "ec"); !ok {
+		t.Error("Expected existing keys to survive a failed refresh")
+	}
+}
+
+func TestKeySetURL_RefreshInBackground(t *testing.T) {
+	keys := newTestKeys(t)
+	// The set starts with the RSA key only, and the refresh brings the rest
+	var document struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	json.Unmarshal(keys.jwks(), &document)
+	first, _ := json.Marshal(map[string]any{"keys": document.Keys[:1]})
+
+	var slow atomic.Bool
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !slow.Load() {
+			w.Write(first)
+			return
+		}
+		<-release
+		w.Write(keys.jwks())
+	}))
+	defer server.Close()
+
+	set, err := NewKeySetURL(context.Background(), server.URL, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow.Store(true)
+
+	// A due refresh doesn't hold up lookups of known keys, even if the request that
+	// started it goes away
+	set.mu.Lock()
+	set.fetchedAt = time.Now().Add(-2 * time.Hour)
+	set.mu.Unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan bool)
+	go func() {
+		_, ok := set.key(ctx, "rsa")
+		done <- ok
+	}()
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Error("Expected the known key during the refresh")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a known key without waiting for the refresh")
+	}
+	cancel()
+
+	// Unknown keys wait for the refresh in progress, which completes for them
+	found := make(chan bool)
+	go func() {
+		_, ok := set.key(context.Background(), "ec")
+		found <- ok
+	}()
+	go func() {
+		_, ok := set.key(context.Background(), "ed")
+		found <- ok
+	}()
+	close(release)
+	for range 2 {
+		if !<-found {
+			t.Error("Expected keys from the refresh")
+		}
+	}
+
+	// A waiter whose request ends stops waiting
+	set.mu.Lock()
+	set.fetching = make(chan struct{})
+	set.mu.Unlock()
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, ok := set.key(ctx, "rotated"); ok {
+		t.Error("Expected no key once the request ended")
+	}
+}
+
+func TestNewKeySetURL_Unreachable(t *testing.T) {
+	if _, err := NewKeySetURL(context.Background(), "http://127.0.0.1:1/jwks", time.Hour); err == nil {
+		t.Error("Expected startup error for an unreachable JWKS URL")
+	}
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultLeeway is the clock skew tolerated when checking token times
+const DefaultLeeway = time.Minute
+
+// JWTConfig configures access token validation
+type JWTConfig struct {
+	Issuer      string        // Required value of the iss claim
+	Audience    string        // Value the aud claim must contain, usually the resource URL
+	TenantClaim string        // Claim naming the LoanPro tenant, "tenant" by default
+	RoleClaim   string        // Claim naming the caller's role, "role" by default
+	Leeway      time.Duration // Clock skew tolerance, DefaultLeeway by default
+}
+
+// JWTValidator validates OAuth 2.1 access tokens issued as signed JWTs
+type JWTValidator struct {
+	config JWTConfig
+	keys   *KeySet
+	now    func() time.Time
+}
+
+// NewJWTValidator creates a validator checking tokens against keys
+func NewJWTValidator(config JWTConfig, keys *KeySet) (*JWTValidator, error) {
+	if config.Issuer == "" || config.Audience == "" {
+		return nil, fmt.Errorf("JWT validation requires an issuer and an audience")
+	}
+	if config.TenantClaim == "" {
+		config.TenantClaim = "tenant"
+	}
+	if config.RoleClaim == "" {
+		config.RoleClaim = "role"
+	}
+	if config.Leeway == 0 {
+		config.Leeway = DefaultLeeway
+	}
+	return &JWTValidator{config: config, keys: keys, now: time.Now}, nil
+}
+
+// jwtHeader is the JOSE header of a JWT
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// Authenticate verifies a JWT's signature, issuer, audience and validity period and
+// returns its principal. Tokens that aren't JWTs are passed over with ErrInvalidToken.
+func (v *JWTValidator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg == "" {
+		return nil, ErrInvalidToken
+	}
+
+	key, ok := v.keys.key(ctx, header.Kid)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, header.Kid)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims["sub"].(string)
+	tenant, _ := claims[v.config.TenantClaim].(string)
+	role, _ := claims[v.config.RoleClaim].(string)
+	return &Principal{Subject: subject, Method: "oauth", Tenant: tenant, Role: role, Scopes: tokenScopes(claims)}, nil
+}
+
+// checkClaims checks the registered claims of a verified token
+func (v *JWTValidator) checkClaims(claims map[string]any) error {
+	if iss, _ := claims["iss"].(string); iss != v.config.Issuer {
+		return fmt.Errorf("%w: issuer %q is not trusted", ErrInvalidToken, iss)
+	}
+	if !slices.Contains(stringOrList(claims["aud"]), v.config.Audience) {
+		return fmt.Errorf("%w: token is not for audience %q", ErrInvalidToken, v.config.Audience)
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	now := v.now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("%w: missing expiry", ErrInvalidToken)
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(v.config.Leeway)) {
+		return ErrExpiredToken
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(v.config.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+	return nil
+}
+
+// tokenScopes returns the scopes of a token from the space-separated scope claim or
+// the scp list some authorization servers use. Tokens without scopes get an empty,
+// non-nil list so they are granted nothing rather than everything.
+func tokenScopes(claims map[string]any) []string {
+	scopes := []string{}
+	if scope, ok := claims["scope"].(string); ok {
+		scopes = append(scopes, strings.Fields(scope)...)
+	}
+	scopes = append(scopes, stringOrList(claims["scp"])...)
+	return scopes
+}
+
+// stringOrList reads a claim that may be a string or a list of strings
+func stringOrList(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var list []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// decodeSegment decodes a base64url JSON segment of a JWT
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// verifySignature checks a JWS signature. The algorithm must match the key type,
+// so a token can't pick a weaker algorithm than its key was issued for; "none" and
+// the shared-secret HS algorithms are never accepted.
+func verifySignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	var h hash.Hash
+	var hashFunc crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		h, hashFunc = sha256.New(), crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		h, hashFunc = sha512.New384(), crypto.SHA384
+	case "RS512", "PS512":
+		h, hashFunc = sha512.New(), crypto.SHA512
+	case "EdDSA":
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") && !strings.HasPrefix(alg, "PS") {
+			break
+		}
+		h.Write([]byte(signed))
+		if strings.HasPrefix(alg, "PS") {
+			return rsa.VerifyPSS(key, hashFunc, h.Sum(nil), signature, nil)
+		}
+		return rsa.VerifyPKCS1v15(key, hashFunc, h.Sum(nil), signature)
+
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") || key.Curve.Params().BitSize != hashFunc.Size()*8 {
+			break
+		}
+		h.Write([]byte(signed))
+		size := hashFunc.Size()
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, h.Sum(nil), r, s) {
+			return fmt.Errorf("signature verification failed")
+		}
+		return nil
+
+	case ed25519.PublicKey:
+		if alg != "EdDSA" {
+			break
+		}
+		if !ed25519.Verify(key, []byte(signed), signature) {
+			return fmt.Errorf("signature verification failed")
+		}
+		return nil
+	}
+	return fmt.Errorf("algorithm %q does not match the signing key", alg)
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testKeys holds signing keys and writes the matching JWKS
+type testKeys struct {
+	rsa     *rsa.PrivateKey
+	ec      *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testKeys{rsa: rsaKey, ec: ecKey, ed25519: edKey}
+}
+
+// jwks returns a JWKS document with the public keys under kids "rsa", "ec" and "ed"
+func (k *testKeys) jwks() []byte {
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	document := map[string]any{"keys": []map[string]any{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(k.rsa.N.Bytes()), "e": b64(big.NewInt(int64(k.rsa.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(k.ec.X.FillBytes(make([]byte, 32))), "y": b64(k.ec.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(k.ed25519.Public().(ed25519.PublicKey))},
+		{"kty": "oct", "kid": "shared", "k": "c2VjcmV0"},
+	}}
+	data, _ := json.Marshal(document)
+	return data
+}
+
+// sign returns a JWT signed with the key for kid, using alg in the header
+func (k *testKeys) sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+	b64 := base64.RawURLEncoding.EncodeToString
+	header, _ := json.Marshal(map[string]any{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	var err error
+	switch kid {
+	case "rsa":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, digest[:])
+	case "ec":
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k.ec, digest[:])
+		if err == nil {
+			signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		}
+	case "ed":
+		signature = ed25519.Sign(k.ed25519, []byte(signed))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + b64(signature)
+}
+
+func writeJWKS(t *testing.T, data []byte) *KeySet {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := LoadKeySetFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func TestJWTValidator(t *testing.T) {
+	keys := newTestKeys(t)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	validator, err := NewJWTValidator(JWTConfig{
+		Issuer:   "https://auth.example.com",
+		Audience: "https://mcp.example.com/mcp",
+	}, writeJWKS(t, keys.jwks()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator.now = func() time.Time { return now }
+
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{
+			"iss":    "https://auth.example.com",
+			"aud":    "https://mcp.example.com/mcp",
+			"sub":    "user-42",
+			"exp":    now.Add(time.Hour).Unix(),
+			"scope":  "loans:read customers:read",
+			"tenant": "cards",
+			"role":   "collections",
+		}
+		for key, value := range overrides {
+			if value == nil {
+				delete(c, key)
+			} else {
+				c[key] = value
+			}
+		}
+		return c
+	}
+	valid := keys.sign(t, "RS256", "rsa", claims(nil))
+	header, payload, _ := strings.Cut(valid, ".")
+	forgedPayload := base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"https://auth.example.com","aud":"https://mcp.example.com/mcp","sub":"admin","exp":9999999999}`))
+	tampered := header + "." + forgedPayload + "." + strings.SplitN(payload, ".", 2)[1]
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + forgedPayload + "."
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"RS256", valid, nil},
+		{"ES256", keys.sign(t, "ES256", "ec", claims(nil)), nil},
+		{"EdDSA", keys.sign(t, "EdDSA", "ed", claims(nil)), nil},
+		{"audience list", keys.sign(t, "RS256", "rsa", claims(map[string]any{"aud": []string{"other", "https://mcp.example.com/mcp"}})), nil},
+		{"within leeway", keys.sign(t, "RS256", "rsa", claims(map[string]any{"exp": now.Add(-30 * time.Second).Unix()})), nil},
+		{"expired", keys.sign(t, "RS256", "rsa", claims(map[string]any{"exp": now.Add(-time.Hour).Unix()})), ErrExpiredToken},
+		{"not yet valid", keys.sign(t, "RS256", "rsa", claims(map[string]any{"nbf": now.Add(time.Hour).Unix()})), ErrInvalidToken},
+		{"missing expiry", keys.sign(t, "RS256", "rsa", claims(map[string]any{"exp": nil})), ErrInvalidToken},
+		{"wrong issuer", keys.sign(t, "RS256", "rsa", claims(map[string]any{"iss": "https://evil.example.com"})), ErrInvalidToken},
+		{"wrong audience", keys.sign(t, "RS256", "rsa", claims(map[string]any{"aud": "https://other.example.com"})), ErrInvalidToken},
+		{"algorithm mismatch", keys.sign(t, "ES256", "rsa", claims(nil)), ErrInvalidToken},
+		{"unknown key", strings.Replace(valid, header, base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","kid":"gone"}`)), 1), ErrInvalidToken},
+		{"tampered claims", tampered, ErrInvalidToken},
+		{"alg none", unsigned, ErrInvalidToken},
+		{"not a JWT", "plain-api-key", ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := validator.Authenticate(context.Background(), tt.token)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if principal.Subject != "user-42" || principal.Tenant != "cards" || principal.Role != "collections" || principal.Method != "oauth" {
+				t.Errorf("Expected user-42 collections principal for cards, got %+v", principal)
+			}
+			if !slices.Equal(principal.Scopes, []string{"loans:read", "customers:read"}) {
+				t.Errorf("Expected scopes from the scope claim, got %v", principal.Scopes)
+			}
+		})
+	}
+}
+
+func TestTokenScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   map[string]any
+		expected []string
+	}{
+		{"scope string", map[string]any{"scope": "loans:read  customers:read"}, []string{"loans:read", "customers:read"}},
+		{"scp list", map[string]any{"scp": []any{"loans:read"}}, []string{"loans:read"}},
+		{"no scopes", map[string]any{}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scopes := tokenScopes(tt.claims)
+			if scopes == nil || !slices.Equal(scopes, tt.expected) {
+				t.Errorf("Expected %v, got %#v", tt.expected, scopes)
+			}
+			principal := &Principal{Scopes: scopes}
+			if principal.HasScope("portfolios:read") {
+				t.Error("Expected a token without the scope not to have it")
+			}
+		})
+	}
+}
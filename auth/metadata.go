@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// wellKnownMetadata is the well-known path prefix for protected resource metadata
+const wellKnownMetadata = "/.well-known/oauth-protected-resource"
+
+// ProtectedResourceMetadata is the OAuth 2.0 protected resource metadata (RFC 9728)
+// that tells MCP clients which authorization server issues tokens for this server
+type ProtectedResourceMetadata struct {
+	Resource               string   `json:"resource"`
+	AuthorizationServers   []string `json:"authorization_servers"`
+	ScopesSupported        []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported"`
+	ResourceName           string   `json:"resource_name,omitempty"`
+}
+
+// MetadataPath returns the path the metadata for a resource URL is served at. For
+// "https://mcp.example.com/mcp" it is "/.well-known/oauth-protected-resource/mcp".
+func MetadataPath(resource string) (string, error) {
+	u, err := url.Parse(resource)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("resource %q must be an absolute URL", resource)
+	}
+	return wellKnownMetadata + strings.TrimSuffix(u.Path, "/"), nil
+}
+
+// MetadataURL returns the absolute URL of the metadata for a resource URL
+func MetadataURL(resource string) (string, error) {
+	path, err := MetadataPath(resource)
+	if err != nil {
+		return "", err
+	}
+	u, _ := url.Parse(resource)
+	return u.Scheme + "://" + u.Host + path, nil
+}
+
+// ServeHTTP serves the metadata as JSON. Browser-based clients fetch it cross-origin.
+func (m *ProtectedResourceMetadata) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Cache-Control", "max-age=3600")
+	json.NewEncoder(w).Encode(m)
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetadataURL(t *testing.T) {
+	tests := []struct {
+		resource string
+		path     string
+		url      string
+	}{
+		{"https://mcp.example.com/mcp", "/.well-known/oauth-protected-resource/mcp", "https://mcp.example.com/.well-known/oauth-protected-resource/mcp"},
+		{"https://mcp.example.com", "/.well-known/oauth-protected-resource", "https://mcp.example.com/.well-known/oauth-protected-resource"},
+		{"http://localhost:8080/", "/.well-known/oauth-protected-resource", "http://localhost:8080/.well-known/oauth-protected-resource"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resource, func(t *testing.T) {
+			path, err := MetadataPath(tt.resource)
+			if err != nil || path != tt.path {
+				t.Errorf("Expected path %s, got %s (%v)", tt.path, path, err)
+			}
+			url, err := MetadataURL(tt.resource)
+			if err != nil || url != tt.url {
+				t.Errorf("Expected URL %s, got %s (%v)", tt.url, url, err)
+			}
+		})
+	}
+
+	if _, err := MetadataPath("/mcp"); err == nil {
+		t.Error("Expected error for a relative resource")
+	}
+}
+
+func TestProtectedResourceMetadata_ServeHTTP(t *testing.T) {
+	metadata := &ProtectedResourceMetadata{
+		Resource:               "https://mcp.example.com/mcp",
+		AuthorizationServers:   []string{"https://auth.example.com"},
+		ScopesSupported:        []string{"loans:read"},
+		BearerMethodsSupported: []string{"header"},
+	}
+
+	w := httptest.NewRecorder()
+	metadata.ServeHTTP(w, httptest.NewRequest("GET", "/.well-known/oauth-protected-resource/mcp", nil))
+
+	var served map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil {
+		t.Fatal(err)
+	}
+	if served["resource"] != "https://mcp.example.com/mcp" || served["authorization_servers"].([]any)[0] != "https://auth.example.com" {
+		t.Errorf("Unexpected metadata %v", served)
+	}
+}
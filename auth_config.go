@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"loanpro-mcp-server/auth"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultToolScopes maps each tool to the scope a caller needs to invoke it
+var defaultToolScopes = map[string]string{
+	"get_loan":              "loans:read",
+	"search_loans":          "loans:read",
+	"get_loan_payments":     "loans:read",
+	"get_loan_transactions": "loans:read",
+	"get_customer":          "customers:read",
+	"search_customers":      "customers:read",
+	"list_portfolios":       "portfolios:read",
+}
+
+// loadToolScopes returns the tool scope mapping, with overrides from MCP_TOOL_SCOPES,
+// e.g. "get_customer=pii:read,search_customers=pii:read"
+func loadToolScopes() map[string]string {
+	scopes := maps.Clone(defaultToolScopes)
+	if overrides := os.Getenv("MCP_TOOL_SCOPES"); overrides != "" {
+		for _, pair := range strings.Split(overrides, ",") {
+			tool, scope, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || strings.TrimSpace(tool) == "" || strings.TrimSpace(scope) == "" {
+				slog.Error("Invalid MCP_TOOL_SCOPES entry, skipping", "entry", pair)
+				continue
+			}
+			scopes[strings.TrimSpace(tool)] = strings.TrimSpace(scope)
+		}
+	}
+	return scopes
+}
+
+// scopeNames returns the distinct scopes of a tool scope mapping in sorted order
+func scopeNames(toolScopes map[string]string) []string {
+	names := slices.Collect(maps.Values(toolScopes))
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// authConfig is the authentication setup for the HTTP and SSE transports
+type authConfig struct {
+	authenticator auth.Authenticator // nil when authentication is off
+	options       auth.Options
+	metadata      *auth.ProtectedResourceMetadata // nil unless OAuth is configured
+}
+
+// loadAuthConfig builds the authenticator for the HTTP and SSE transports from the
+// environment: API keys, HMAC-signed tokens and OAuth access tokens, in that order
+func loadAuthConfig(toolScopes map[string]string) (*authConfig, error) {
+	config := &authConfig{}
+	var chain auth.Chain
+
+	if path := os.Getenv("MCP_AUTH_KEYS_FILE"); path != "" {
+		keys, err := auth.LoadAPIKeys(path)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, keys)
+	}
+
+	tokens, err := loadHMACTokens()
+	if err != nil {
+		return nil, err
+	}
+	if tokens != nil {
+		chain = append(chain, tokens)
+	}
+
+	if issuer := os.Getenv("MCP_OAUTH_ISSUER"); issuer != "" {
+		validator, metadata, err := loadOAuth(issuer, toolScopes)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, validator)
+		config.metadata = metadata
+		if config.options.ResourceMetadata, err = auth.MetadataURL(metadata.Resource); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(chain) > 0 {
+		config.authenticator = chain
+	}
+	return config, nil
+}
+
+// loadOAuth configures OAuth resource server mode: access tokens are JWTs from
+// issuer, checked against its JWKS
+func loadOAuth(issuer string, toolScopes map[string]string) (*auth.JWTValidator, *auth.ProtectedResourceMetadata, error) {
+	resource := os.Getenv("MCP_OAUTH_RESOURCE")
+	if resource == "" {
+		return nil, nil, fmt.Errorf("MCP_OAUTH_RESOURCE is required with MCP_OAUTH_ISSUER, e.g. https://mcp.example.com/mcp")
+	}
+	audience := os.Getenv("MCP_OAUTH_AUDIENCE")
+	if audience == "" {
+		audience = resource
+	}
+
+	var keys *auth.KeySet
+	var err error
+	switch jwksURL, jwksFile := os.Getenv("MCP_OAUTH_JWKS_URL"), os.Getenv("MCP_OAUTH_JWKS_FILE"); {
+	case jwksFile != "":
+		keys, err = auth.LoadKeySetFile(jwksFile)
+	case jwksURL != "":
+		keys, err = auth.NewKeySetURL(context.Background(), jwksURL, auth.DefaultJWKSRefresh)
+	default:
+		err = fmt.Errorf("MCP_OAUTH_JWKS_URL or MCP_OAUTH_JWKS_FILE is required with MCP_OAUTH_ISSUER")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	validator, err := auth.NewJWTValidator(auth.JWTConfig{
+		Issuer:      issuer,
+		Audience:    audience,
+		TenantClaim: os.Getenv("MCP_OAUTH_TENANT_CLAIM"),
+		RoleClaim:   os.Getenv("MCP_OAUTH_ROLE_CLAIM"),
+	}, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadata := &auth.ProtectedResourceMetadata{
+		Resource:               resource,
+		AuthorizationServers:   []string{issuer},
+		ScopesSupported:        scopeNames(toolScopes),
+		BearerMethodsSupported: []string{"header"},
+		ResourceName:           "LoanPro MCP Server",
+	}
+	slog.Info("OAuth resource server mode enabled", "issuer", issuer, "audience", audience, "resource", resource)
+	return validator, metadata, nil
+}
+
+// loadHMACTokens reads the token signing secret from MCP_AUTH_HMAC_SECRET or
+// MCP_AUTH_HMAC_SECRET_FILE. It returns nil when neither is set.
+func loadHMACTokens() (*auth.HMACTokens, error) {
+	secret, _, err := lookupSecret("MCP_AUTH_HMAC_SECRET")
+	if err != nil || secret == "" {
+		return nil, err
+	}
+	tokens, err := auth.NewHMACTokens([]byte(secret))
+	if err != nil {
+		return nil, fmt.Errorf("MCP_AUTH_HMAC_SECRET: %w", err)
+	}
+	return tokens, nil
+}
+
+// protect wraps an MCP endpoint so requests are authenticated, when authentication
+// is configured, before their tenant is resolved
+func (c *authConfig) protect(handler http.HandlerFunc) http.Handler {
+	next := tenantMiddleware(handler)
+	if c.authenticator == nil {
+		return next
+	}
+	return auth.Middleware(c.authenticator, c.options)(next)
+}
+
+// route registers the protected resource metadata endpoints when OAuth is configured.
+// The metadata is served at its RFC 9728 path and at the bare well-known path, which
+// some clients try first.
+func (c *authConfig) route(r *mux.Router) {
+	if c.metadata == nil {
+		return
+	}
+	path, _ := auth.MetadataPath(c.metadata.Resource)
+	r.Handle(path, c.metadata).Methods("GET")
+	if bare := "/.well-known/oauth-protected-resource"; path != bare {
+		r.Handle(bare, c.metadata).Methods("GET")
+	}
+}
+
+// warnIfUnauthenticated logs a warning when the MCP endpoints are open to anyone
+func (c *authConfig) warnIfUnauthenticated() {
+	if c.authenticator == nil {
+		slog.Warn("Authentication is disabled; anyone who can reach this port can read loan and customer data. Set MCP_AUTH_KEYS_FILE, MCP_AUTH_HMAC_SECRET or MCP_OAUTH_ISSUER.")
+	}
+}
+
+// issueToken implements the issue-token subcommand, which prints a token signed with
+// MCP_AUTH_HMAC_SECRET
+func issueToken(args []string) error {
+	flags := flag.NewFlagSet("issue-token", flag.ContinueOnError)
+	subject := flags.String("subject", "", "Caller the token identifies, e.g. collections-bot (required)")
+	tenant := flags.String("tenant", "", "LoanPro tenant the token is restricted to")
+	role := flags.String("role", "", "Role in the access policy; the policy's default role when empty")
+	scopes := flags.String("scopes", "", "Space-separated scopes the token grants, e.g. \"loans:read\"; all tools when empty")
+	ttl := flags.Duration("ttl", 30*24*time.Hour, "How long the token is valid, 0 for no expiry")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	tokens, err := loadHMACTokens()
+	if err != nil {
+		return err
+	}
+	if tokens == nil {
+		return fmt.Errorf("MCP_AUTH_HMAC_SECRET is not set")
+	}
+
+	claims := auth.TokenClaims{Subject: *subject, Tenant: *tenant, Role: *role}
+	if *scopes != "" {
+		claims.Scopes = strings.Fields(*scopes)
+	}
+	if *ttl > 0 {
+		claims.ExpiresAt = time.Now().Add(*ttl).Unix()
+	}
+	token, err := tokens.Sign(claims)
+	if err != nil {
+		return err
+	}
+	fmt.Println(token)
+	return nil
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"loanpro-mcp-server/auth"
+	"loanpro-mcp-server/loanpro"
+	"loanpro-mcp-server/transport"
+
+	"github.com/gorilla/mux"
+)
+
+func TestLoadAuthenticator(t *testing.T) {
+	keysFile := filepath.Join(t.TempDir(), "keys.json")
+	keys := `{"keys": [{"name": "bot", "sha256": "` + auth.HashAPIKey("bot-key") + `"}]}`
+	if err := os.WriteFile(keysFile, []byte(keys), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("MCP_AUTH_KEYS_FILE", "")
+	t.Setenv("MCP_AUTH_HMAC_SECRET", "")
+	if config, err := loadAuthConfig(defaultToolScopes); err != nil || config.authenticator != nil {
+		t.Fatalf("Expected no authenticator without configuration, got %v, %v", config, err)
+	}
+
+	t.Setenv("MCP_AUTH_KEYS_FILE", keysFile)
+	t.Setenv("MCP_AUTH_HMAC_SECRET", strings.Repeat("x", 32))
+	config, err := loadAuthConfig(defaultToolScopes)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	authenticator := config.authenticator
+
+	tokens, _ := loadHMACTokens()
+	token, _ := tokens.Sign(auth.TokenClaims{Subject: "analyst"})
+	for credential, subject := range map[string]string{"bot-key": "bot", token: "analyst"} {
+		principal, err := authenticator.Authenticate(context.Background(), credential)
+		if err != nil || principal.Subject != subject {
+			t.Errorf("Expected %s to authenticate, got %+v, %v", subject, principal, err)
+		}
+	}
+
+	t.Setenv("MCP_AUTH_HMAC_SECRET", "short")
+	if _, err := loadAuthConfig(defaultToolScopes); err == nil {
+		t.Error("Expected error for a short HMAC secret")
+	}
+}
+
+func TestProtect(t *testing.T) {
+	keys, err := auth.NewAPIKeys([]auth.APIKey{
+		{Name: "cards-bot", SHA256: auth.HashAPIKey("cards-key"), Tenant: "cards"},
+		{Name: "admin", SHA256: auth.HashAPIKey("admin-key")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewMCPServerWithTenants(NewTenantRegistry("auto",
+		NewTenant("auto", &loanpro.Client{}),
+		NewTenant("cards", &loanpro.Client{}),
+	))
+	handler := (&authConfig{authenticator: keys}).protect(transport.NewHTTPTransport(server).HandleMCP)
+
+	tests := []struct {
+		name   string
+		key    string
+		header string
+		status int
+		pinned bool // Whether tools/list omits the tenant argument
+	}{
+		{"no token", "", "", http.StatusUnauthorized, false},
+		{"tenant key", "cards-key", "", http.StatusOK, true},
+		{"tenant key with matching header", "cards-key", "cards", http.StatusOK, true},
+		{"tenant key with other tenant header", "cards-key", "auto", http.StatusForbidden, false},
+		{"unrestricted key", "admin-key", "", http.StatusOK, false},
+		{"unrestricted key with header", "admin-key", "auto", http.StatusOK, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(transport.MCPRequest{JSONRPC: "2.0", Method: "tools/list", ID: 1})
+			req := httptest.NewRequest("POST", "/mcp", bytes.NewReader(body))
+			if tt.key != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.key)
+			}
+			if tt.header != "" {
+				req.Header.Set(TenantHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected a WWW-Authenticate challenge")
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Result struct {
+					Tools []struct {
+						InputSchema struct {
+							Properties map[string]any `json:"properties"`
+						} `json:"inputSchema"`
+					} `json:"tools"`
+				} `json:"result"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			_, advertised := response.Result.Tools[0].InputSchema.Properties["tenant"]
+			if advertised == tt.pinned {
+				t.Errorf("Expected pinned=%v, got tenant argument advertised=%v", tt.pinned, advertised)
+			}
+		})
+	}
+}
+
+func TestLoadToolScopes(t *testing.T) {
+	t.Setenv("MCP_TOOL_SCOPES", "get_customer=pii:read, search_customers = pii:read,broken")
+	scopes := loadToolScopes()
+
+	expected := map[string]string{
+		"get_customer":     "pii:read",
+		"search_customers": "pii:read",
+		"get_loan":         "loans:read",
+	}
+	for tool, scope := range expected {
+		if scopes[tool] != scope {
+			t.Errorf("Expected %s to require %s, got %s", tool, scope, scopes[tool])
+		}
+	}
+	if defaultToolScopes["get_customer"] != "customers:read" {
+		t.Error("Expected overrides not to modify the defaults")
+	}
+}
+
+func TestMCPServer_ToolsCall_Scopes(t *testing.T) {
+	apiURL, apiKey, tenantID := startFakeLoanPro()
+	server := NewMCPServer(loanpro.NewClient(apiURL, apiKey, tenantID))
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		tool      string
+		allowed   bool
+	}{
+		{"unauthenticated", nil, "get_customer", true},
+		{"unscoped credential", &auth.Principal{Subject: "admin"}, "get_customer", true},
+		{"granted scope", &auth.Principal{Subject: "analyst", Scopes: []string{"loans:read"}}, "get_loan", true},
+		{"missing scope", &auth.Principal{Subject: "analyst", Scopes: []string{"loans:read"}}, "get_customer", false},
+		{"no scopes", &auth.Principal{Subject: "analyst", Scopes: []string{}}, "get_loan", false},
+		{"unmapped tool", &auth.Principal{Subject: "analyst", Scopes: []string{"loans:read"}}, "delete_loan", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, tt.principal)
+			}
+			response := server.HandleMCPRequestContext(ctx, transport.MCPRequest{
+				JSONRPC: "2.0",
+				Method:  "tools/call",
+				Params:  map[string]any{"name": tt.tool, "arguments": map[string]any{"loan_id": "102", "customer_id": "3"}},
+				ID:      1,
+			})
+
+			forbidden := response.Error != nil && response.Error.Code == errorCodeForbidden
+			if forbidden == tt.allowed {
+				t.Errorf("Expected allowed=%v, got %+v", tt.allowed, response.Error)
+			}
+			if forbidden && !strings.Contains(response.Error.Message, "insufficient_scope") {
+				t.Errorf("Expected insufficient_scope error, got %s", response.Error.Message)
+			}
+		})
+	}
+}
+
+func TestLoadAuthConfig_OAuth(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := `{"keys": [{"kty": "OKP", "crv": "Ed25519", "kid": "k1", "x": "` + base64.RawURLEncoding.EncodeToString(public) + `"}]}`
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(jwksFile, []byte(jwks), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("MCP_AUTH_KEYS_FILE", "")
+	t.Setenv("MCP_AUTH_HMAC_SECRET", "")
+	t.Setenv("MCP_OAUTH_ISSUER", "https://auth.example.com")
+	t.Setenv("MCP_OAUTH_RESOURCE", "")
+	if _, err := loadAuthConfig(defaultToolScopes); err == nil {
+		t.Error("Expected error without MCP_OAUTH_RESOURCE")
+	}
+
+	t.Setenv("MCP_OAUTH_RESOURCE", "https://mcp.example.com/mcp")
+	t.Setenv("MCP_OAUTH_JWKS_FILE", jwksFile)
+	config, err := loadAuthConfig(defaultToolScopes)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	r := mux.NewRouter()
+	config.route(r)
+	r.Handle("/mcp", config.protect(transport.NewHTTPTransport(NewMCPServer(&loanpro.Client{})).HandleMCP))
+
+	// Clients discover the authorization server from the challenge
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/mcp", nil))
+	challenge := w.Header().Get("WWW-Authenticate")
+	if w.Code != http.StatusUnauthorized || !strings.Contains(challenge, `resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource/mcp"`) {
+		t.Fatalf("Expected challenge with resource_metadata, got %d %q", w.Code, challenge)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/.well-known/oauth-protected-resource/mcp", nil))
+	var metadata auth.ProtectedResourceMetadata
+	if err := json.Unmarshal(w.Body.Bytes(), &metadata); err != nil {
+		t.Fatal(err)
+	}
+	if metadata.AuthorizationServers[0] != "https://auth.example.com" || len(metadata.ScopesSupported) != 3 {
+		t.Errorf("Unexpected metadata %+v", metadata)
+	}
+
+	// An access token from the issuer reaches the MCP endpoint
+	b64 := base64.RawURLEncoding.EncodeToString
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   "https://auth.example.com",
+		"aud":   "https://mcp.example.com/mcp",
+		"sub":   "user-42",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "loans:read",
+	})
+	signed := b64([]byte(`{"alg":"EdDSA","kid":"k1"}`)) + "." + b64(claims)
+	token := signed + "." + b64(ed25519.Sign(private, []byte(signed)))
+
+	body, _ := json.Marshal(transport.MCPRequest{JSONRPC: "2.0", Method: "tools/list", ID: 1})
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for a valid access token, got %d: %s", w.Code, w.Body.String())
+	}
+}
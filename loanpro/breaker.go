@@ -0,0 +1,249 @@
+package loanpro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default circuit breaker settings
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// ErrUnavailable is returned without contacting LoanPro while the circuit breaker is open
+var ErrUnavailable = errors.New("LoanPro unavailable")
+
+// BreakerState is the state of the circuit breaker
+type BreakerState string
+
+// Circuit breaker states
+const (
+	BreakerClosed   BreakerState = "closed"    // Requests are sent normally
+	BreakerOpen     BreakerState = "open"      // Requests fail fast until the cooldown passes
+	BreakerHalfOpen BreakerState = "half_open" // Probe requests are sent to test whether LoanPro has recovered
+)
+
+// BreakerConfig configures the circuit breaker
+type BreakerConfig struct {
+	FailureThreshold int           // Consecutive failures that open the circuit
+	Cooldown         time.Duration // How long the circuit stays open before probing
+	HalfOpenProbes   int           // Probes allowed at once while half-open, and successes needed to close (defaults to 1)
+}
+
+// BreakerStats contains the current state of the circuit breaker
+type BreakerStats struct {
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	Trips               int64        `json:"trips"` // Times the circuit has opened
+	OpenUntil           time.Time    `json:"open_until,omitzero"`
+	LastError           string       `json:"last_error,omitempty"`
+}
+
+// UnavailableError is returned when the circuit breaker rejects a request. It
+// matches ErrUnavailable with errors.Is.
+type UnavailableError struct {
+	State      BreakerState
+	Failures   int           // Consecutive failures that opened the circuit
+	RetryAfter time.Duration // Time until probing starts, zero while half-open
+	LastError  string        // Failure that opened the circuit
+}
+
+func (e *UnavailableError) Error() string {
+	if e.State == BreakerHalfOpen {
+		return fmt.Sprintf("%s: circuit breaker is half-open and probing for recovery, retry shortly", ErrUnavailable)
+	}
+	msg := fmt.Sprintf("%s: circuit breaker is open after %d consecutive failures, retry in %s",
+		ErrUnavailable, e.Failures, e.RetryAfter.Round(time.Second))
+	if e.LastError != "" {
+		msg += " (last error: " + e.LastError + ")"
+	}
+	return msg
+}
+
+// Is reports whether target is ErrUnavailable
+func (e *UnavailableError) Is(target error) bool {
+	return target == ErrUnavailable
+}
+
+// breakerOutcome is how a finished request affects the breaker
+type breakerOutcome int
+
+const (
+	outcomeSuccess breakerOutcome = iota
+	outcomeFailure
+	outcomeIgnored // the request never reached LoanPro or was cancelled by the caller
+)
+
+// circuitBreaker fails requests fast after repeated LoanPro failures. After the
+// cooldown it lets a limited number of probe requests through; if they succeed
+// the circuit closes, otherwise it opens again.
+type circuitBreaker struct {
+	config BreakerConfig
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int // consecutive failures, kept while open for reporting
+	openedAt  time.Time
+	probes    int // probes in flight while half-open
+	successes int // successful probes since going half-open
+	trips     int64
+	lastErr   string
+}
+
+// newCircuitBreaker creates a closed breaker, filling in defaults for unset settings
+func newCircuitBreaker(config BreakerConfig) *circuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = DefaultBreakerThreshold
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = DefaultBreakerCooldown
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = 1
+	}
+	return &circuitBreaker{config: config, state: BreakerClosed}
+}
+
+// allow reports whether a request may be sent. The returned function must be
+// called with the request's result.
+func (b *circuitBreaker) allow() (func(ctx context.Context, err error), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen {
+		retryAfter := b.config.Cooldown - time.Since(b.openedAt)
+		if retryAfter > 0 {
+			return nil, &UnavailableError{
+				State:      BreakerOpen,
+				Failures:   b.failures,
+				RetryAfter: retryAfter,
+				LastError:  b.lastErr,
+			}
+		}
+		b.state = BreakerHalfOpen
+		b.probes = 0
+		b.successes = 0
+	}
+
+	probe := false
+	if b.state == BreakerHalfOpen {
+		if b.probes >= b.config.HalfOpenProbes {
+			return nil, &UnavailableError{State: BreakerHalfOpen, Failures: b.failures, LastError: b.lastErr}
+		}
+		b.probes++
+		probe = true
+	}
+
+	return func(ctx context.Context, err error) {
+		b.record(probe, classifyOutcome(ctx, err), err)
+	}, nil
+}
+
+// record updates the breaker with the outcome of a request it allowed
+func (b *circuitBreaker) record(probe bool, outcome breakerOutcome, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if outcome == outcomeFailure {
+		b.lastErr = err.Error()
+	}
+
+	if probe && b.state == BreakerHalfOpen {
+		b.probes--
+	}
+
+	switch b.state {
+	case BreakerClosed:
+		switch outcome {
+		case outcomeSuccess:
+			b.failures = 0
+		case outcomeFailure:
+			b.failures++
+			if b.failures >= b.config.FailureThreshold {
+				b.open()
+			}
+		}
+	case BreakerHalfOpen:
+		// Requests admitted before the circuit opened don't count as probes
+		if !probe {
+			return
+		}
+		switch outcome {
+		case outcomeSuccess:
+			b.successes++
+			if b.successes >= b.config.HalfOpenProbes {
+				b.state = BreakerClosed
+				b.failures = 0
+				b.lastErr = ""
+			}
+		case outcomeFailure:
+			b.failures++
+			b.open()
+		}
+	}
+}
+
+// open trips the circuit. The caller must hold b.mu.
+func (b *circuitBreaker) open() {
+	b.state = BreakerOpen
+	b.openedAt = time.Now()
+	b.trips++
+}
+
+// stats returns a snapshot of the breaker state
+func (b *circuitBreaker) stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := BreakerStats{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Trips:               b.trips,
+		LastError:           b.lastErr,
+	}
+	if b.state == BreakerOpen {
+		stats.OpenUntil = b.openedAt.Add(b.config.Cooldown).UTC()
+	}
+	return stats
+}
+
+// classifyOutcome decides whether a request result counts against LoanPro.
+// Network errors, timeouts, rate limiting and server errors do; client errors
+// such as 404 mean LoanPro is up, and cancellations by the caller say nothing
+// about LoanPro at all.
+func classifyOutcome(ctx context.Context, err error) breakerOutcome {
+	if err == nil {
+		return outcomeSuccess
+	}
+	if ctx.Err() != nil {
+		return outcomeIgnored
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusTooManyRequests {
+			return outcomeFailure
+		}
+		return outcomeSuccess
+	}
+	return outcomeFailure
+}
+
+// EnableCircuitBreaker makes the client fail fast while LoanPro is unavailable.
+// It should be called before the client is used.
+func (c *Client) EnableCircuitBreaker(config BreakerConfig) {
+	c.breaker = newCircuitBreaker(config)
+}
+
+// BreakerStats returns the circuit breaker state. It returns a closed state when
+// the breaker is disabled.
+func (c *Client) BreakerStats() BreakerStats {
+	if c.breaker == nil {
+		return BreakerStats{State: BreakerClosed}
+	}
+	return c.breaker.stats()
+}
@@ -0,0 +1,179 @@
+package loanpro
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFlakyServer returns a server that responds with the current status, serving a
+// loan when it is 200
+func newFlakyServer(t *testing.T, status *atomic.Int32) (string, *atomic.Int32) {
+	t.Helper()
+	server, requests := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		code := int(status.Load())
+		if code != http.StatusOK {
+			w.WriteHeader(code)
+			w.Write([]byte(`{"error": "unavailable"}`))
+			return
+		}
+		w.Write([]byte(`{"d": {"id": 1, "displayId": "LN1"}}`))
+	})
+	return server.URL, requests
+}
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	url, requests := newFlakyServer(t, &status)
+
+	client := NewClient(url, "key", "tenant")
+	client.EnableCircuitBreaker(BreakerConfig{FailureThreshold: 3, Cooldown: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		_, err := client.GetLoan("1")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("Expected 503 API error on attempt %d, got %v", i+1, err)
+		}
+	}
+
+	start := time.Now()
+	_, err := client.GetLoan("1")
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Expected ErrUnavailable once open, got %v", err)
+	}
+	if time.Since(start) > 100*time.Millisecond {
+		t.Errorf("Expected open breaker to fail fast, took %v", time.Since(start))
+	}
+	if requests.Load() != 3 {
+		t.Errorf("Expected open breaker not to contact LoanPro, got %d requests", requests.Load())
+	}
+	for _, want := range []string{"LoanPro unavailable", "circuit breaker is open", "3 consecutive failures", "status 503"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+		}
+	}
+
+	stats := client.BreakerStats()
+	if stats.State != BreakerOpen || stats.Trips != 1 || stats.OpenUntil.IsZero() {
+		t.Errorf("Expected open breaker with one trip, got %+v", stats)
+	}
+}
+
+func TestBreakerIgnoresClientErrors(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusNotFound)
+	url, requests := newFlakyServer(t, &status)
+
+	client := NewClient(url, "key", "tenant")
+	client.EnableCircuitBreaker(BreakerConfig{FailureThreshold: 2})
+
+	for i := 0; i < 5; i++ {
+		if _, err := client.GetLoan("1"); errors.Is(err, ErrUnavailable) {
+			t.Fatalf("Expected 404s not to open the breaker, got %v", err)
+		}
+	}
+	if requests.Load() != 5 {
+		t.Errorf("Expected 5 requests, got %d", requests.Load())
+	}
+	if stats := client.BreakerStats(); stats.State != BreakerClosed {
+		t.Errorf("Expected closed breaker, got %+v", stats)
+	}
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	var status atomic.Int32
+	url, _ := newFlakyServer(t, &status)
+
+	client := NewClient(url, "key", "tenant")
+	client.EnableCircuitBreaker(BreakerConfig{FailureThreshold: 2})
+
+	// Failures separated by a success are not consecutive
+	for _, code := range []int{500, 200, 500, 200, 500} {
+		status.Store(int32(code))
+		client.GetLoan("1")
+	}
+	if stats := client.BreakerStats(); stats.State != BreakerClosed || stats.ConsecutiveFailures != 1 {
+		t.Errorf("Expected closed breaker with 1 failure, got %+v", stats)
+	}
+}
+
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusBadGateway)
+	url, requests := newFlakyServer(t, &status)
+
+	client := NewClient(url, "key", "tenant")
+	client.EnableCircuitBreaker(BreakerConfig{FailureThreshold: 1, Cooldown: 20 * time.Millisecond})
+
+	client.GetLoan("1")
+	if stats := client.BreakerStats(); stats.State != BreakerOpen {
+		t.Fatalf("Expected open breaker, got %+v", stats)
+	}
+
+	// A failed probe opens the circuit again
+	time.Sleep(30 * time.Millisecond)
+	if _, err := client.GetLoan("1"); errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Expected probe request after cooldown, got %v", err)
+	}
+	if stats := client.BreakerStats(); stats.State != BreakerOpen || stats.Trips != 2 {
+		t.Fatalf("Expected failed probe to reopen the breaker, got %+v", stats)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests.Load())
+	}
+
+	// A successful probe closes it
+	status.Store(http.StatusOK)
+	time.Sleep(30 * time.Millisecond)
+	if _, err := client.GetLoan("1"); err != nil {
+		t.Fatalf("Expected successful probe, got %v", err)
+	}
+	if stats := client.BreakerStats(); stats.State != BreakerClosed || stats.ConsecutiveFailures != 0 {
+		t.Errorf("Expected closed breaker after successful probe, got %+v", stats)
+	}
+}
+
+func TestBreakerHalfOpenLimitsProbes(t *testing.T) {
+	b := newCircuitBreaker(BreakerConfig{FailureThreshold: 1, Cooldown: time.Millisecond})
+	done, _ := b.allow()
+	done(context.Background(), errors.New("connection refused"))
+
+	time.Sleep(5 * time.Millisecond)
+	probe, err := b.allow()
+	if err != nil {
+		t.Fatalf("Expected first probe to be allowed, got %v", err)
+	}
+
+	_, err = b.allow()
+	var unavailable *UnavailableError
+	if !errors.As(err, &unavailable) || unavailable.State != BreakerHalfOpen {
+		t.Fatalf("Expected second request to be rejected while probing, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "half-open") {
+		t.Errorf("Expected error to mention half-open state, got %q", err.Error())
+	}
+
+	probe(context.Background(), nil)
+	if stats := b.stats(); stats.State != BreakerClosed {
+		t.Errorf("Expected closed breaker, got %+v", stats)
+	}
+}
+
+func TestBreakerIgnoresCancellation(t *testing.T) {
+	b := newCircuitBreaker(BreakerConfig{FailureThreshold: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done, _ := b.allow()
+	done(ctx, ctx.Err())
+
+	if stats := b.stats(); stats.State != BreakerClosed || stats.ConsecutiveFailures != 0 {
+		t.Errorf("Expected cancelled request not to count, got %+v", stats)
+	}
+}
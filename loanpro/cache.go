@@ -0,0 +1,273 @@
+package loanpro
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Default cache settings
+const (
+	DefaultCacheTTL        = 30 * time.Second
+	DefaultCacheMaxEntries = 1000
+)
+
+// DefaultCacheTTLs are per-resource TTLs applied on top of CacheConfig.DefaultTTL.
+// Reference data changes rarely; search results are kept briefly since they span many loans.
+var DefaultCacheTTLs = map[string]time.Duration{
+	"CustomFields":     10 * time.Minute,
+	"Portfolios":       10 * time.Minute,
+	"Loans.Search":     10 * time.Second,
+	"Customers.Search": 10 * time.Second,
+}
+
+// CacheConfig configures the response cache
+type CacheConfig struct {
+	MaxEntries int                      // Maximum cached responses before least recently used are evicted
+	DefaultTTL time.Duration            // TTL for resources without an entry in TTLs
+	TTLs       map[string]time.Duration // Per-resource TTLs, e.g. "Transactions" or "Loans.Search". Zero disables caching for that resource.
+}
+
+// CacheStats contains response cache counters
+type CacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Shared    int64 `json:"shared"` // Requests that waited on an identical in-flight request
+	Evictions int64 `json:"evictions"`
+	Entries   int   `json:"entries"`
+}
+
+// responseCache is a size-bounded LRU cache of response bodies with per-entry
+// expiry. Concurrent misses for the same key are collapsed into a single request.
+type responseCache struct {
+	config CacheConfig
+
+	mu       sync.Mutex
+	order    *list.List // front is most recently used
+	entries  map[string]*list.Element
+	inflight map[string]*inflightCall
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	shared    atomic.Int64
+	evictions atomic.Int64
+}
+
+// cacheEntry is a cached response body
+type cacheEntry struct {
+	key     string
+	body    []byte
+	expires time.Time
+}
+
+// inflightCall is a request that other callers with the same key are waiting on
+type inflightCall struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// newResponseCache creates a cache, filling in defaults for unset settings
+func newResponseCache(config CacheConfig) *responseCache {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultCacheMaxEntries
+	}
+	if config.DefaultTTL <= 0 {
+		config.DefaultTTL = DefaultCacheTTL
+	}
+	ttls := make(map[string]time.Duration, len(DefaultCacheTTLs)+len(config.TTLs))
+	for resource, ttl := range DefaultCacheTTLs {
+		ttls[resource] = ttl
+	}
+	for resource, ttl := range config.TTLs {
+		ttls[resource] = ttl
+	}
+	config.TTLs = ttls
+
+	return &responseCache{
+		config:   config,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		inflight: make(map[string]*inflightCall),
+	}
+}
+
+// ttl returns how long responses for a resource are cached
+func (rc *responseCache) ttl(resource string) time.Duration {
+	if ttl, ok := rc.config.TTLs[resource]; ok {
+		return ttl
+	}
+	return rc.config.DefaultTTL
+}
+
+// get returns a cached, unexpired response body
+func (rc *responseCache) get(key string) ([]byte, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	elem, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		rc.order.Remove(elem)
+		delete(rc.entries, key)
+		return nil, false
+	}
+	rc.order.MoveToFront(elem)
+	return entry.body, true
+}
+
+// set stores a response body, evicting the least recently used entries when full
+func (rc *responseCache) set(key string, body []byte, ttl time.Duration) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if elem, ok := rc.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.body = body
+		entry.expires = expires
+		rc.order.MoveToFront(elem)
+		return
+	}
+
+	rc.entries[key] = rc.order.PushFront(&cacheEntry{key: key, body: body, expires: expires})
+	for rc.order.Len() > rc.config.MaxEntries {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.entries, oldest.Value.(*cacheEntry).key)
+		rc.evictions.Add(1)
+	}
+}
+
+// do returns the cached response for key, or calls fetch and caches its result.
+// Concurrent calls for the same key share a single fetch; when it fails because its
+// caller cancelled, waiters that haven't cancelled fetch again. When fresh is true
+// the cached value is ignored but the new result still replaces it.
+func (rc *responseCache) do(ctx context.Context, key, resource string, fresh bool, fetch func() ([]byte, error)) ([]byte, error) {
+	ttl := rc.ttl(resource)
+	if ttl <= 0 {
+		return fetch()
+	}
+
+	if !fresh {
+		if body, ok := rc.get(key); ok {
+			rc.hits.Add(1)
+			cacheLookups.Inc(resource, "hit")
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String("loanpro.cache", "hit"))
+			return body, nil
+		}
+	}
+	rc.misses.Add(1)
+	cacheLookups.Inc(resource, "miss")
+
+	rc.mu.Lock()
+	if call, ok := rc.inflight[key]; ok {
+		rc.mu.Unlock()
+		rc.shared.Add(1)
+		select {
+		case <-call.done:
+			if ctx.Err() == nil && (errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded)) {
+				// The caller that fetched gave up; fetch again rather than fail with its error
+				return rc.do(ctx, key, resource, fresh, fetch)
+			}
+			return call.body, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &inflightCall{done: make(chan struct{})}
+	rc.inflight[key] = call
+	rc.mu.Unlock()
+
+	call.body, call.err = fetch()
+	if call.err == nil {
+		rc.set(key, call.body, ttl)
+	}
+
+	rc.mu.Lock()
+	delete(rc.inflight, key)
+	rc.mu.Unlock()
+	close(call.done)
+
+	return call.body, call.err
+}
+
+// stats returns a snapshot of the cache counters
+func (rc *responseCache) stats() CacheStats {
+	rc.mu.Lock()
+	entries := rc.order.Len()
+	rc.mu.Unlock()
+
+	return CacheStats{
+		Hits:      rc.hits.Load(),
+		Misses:    rc.misses.Load(),
+		Shared:    rc.shared.Load(),
+		Evictions: rc.evictions.Load(),
+		Entries:   entries,
+	}
+}
+
+// cacheResource names the resource an endpoint reads, used to pick its TTL.
+// For example ".../odata.svc/Loans(1)/Transactions" is "Transactions" and
+// ".../Loans/Autopal.Search()" is "Loans.Search".
+func cacheResource(endpoint string) string {
+	endpoint = strings.TrimPrefix(endpoint, odataBasePath)
+	endpoint = strings.TrimPrefix(endpoint, "/public/api/1/")
+
+	segments := strings.Split(endpoint, "/")
+	last := segments[len(segments)-1]
+	if strings.HasPrefix(last, "Autopal.Search") && len(segments) > 1 {
+		return segments[len(segments)-2] + ".Search"
+	}
+	if idx := strings.Index(last, "("); idx >= 0 {
+		last = last[:idx]
+	}
+	return last
+}
+
+// isCacheableRead reports whether a request only reads data. Searches are POSTs
+// but don't modify anything.
+func isCacheableRead(method, endpoint string) bool {
+	switch method {
+	case "GET":
+		return true
+	case "POST":
+		return strings.HasSuffix(endpoint, "/Autopal.Search()")
+	}
+	return false
+}
+
+// EnableCache turns on response caching for read requests. It should be called
+// before the client is used.
+func (c *Client) EnableCache(config CacheConfig) {
+	c.cache = newResponseCache(config)
+}
+
+// CacheStats returns response cache counters. It returns zero stats when caching is disabled.
+func (c *Client) CacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+	return c.cache.stats()
+}
+
+// Fresh returns a client that skips cached responses and always reads from LoanPro.
+// Its results still refresh the shared cache.
+func (c *Client) Fresh() *Client {
+	if c.cache == nil || c.bypassCache {
+		return c
+	}
+	fresh := *c
+	fresh.bypassCache = true
+	return &fresh
+}
@@ -0,0 +1,274 @@
+package loanpro
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newCountingServer returns a loan for any request and counts requests per path
+func newCountingServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if handler != nil {
+			handler(w, r)
+			return
+		}
+		w.Write([]byte(`{"d": {"id": 1, "displayId": "LN1"}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestCacheHitsAndMisses(t *testing.T) {
+	server, requests := newCountingServer(t, nil)
+	client := NewClient(server.URL, "key", "tenant")
+	client.EnableCache(CacheConfig{})
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetLoan("1"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if _, err := client.GetLoan("2"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if requests.Load() != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests.Load())
+	}
+	stats := client.CacheStats()
+	if stats.Hits != 2 || stats.Misses != 2 || stats.Entries != 2 {
+		t.Errorf("Expected 2 hits, 2 misses and 2 entries, got %+v", stats)
+	}
+}
+
+func TestCacheFresh(t *testing.T) {
+	var status atomic.Value
+	status.Store("Active")
+	server, requests := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"d": {"id": 1, "loanStatusText": "` + status.Load().(string) + `"}}`))
+	})
+	client := NewClient(server.URL, "key", "tenant")
+	client.EnableCache(CacheConfig{})
+
+	client.GetLoan("1")
+	status.Store("Paid Off")
+
+	cached, _ := client.GetLoan("1")
+	if cached.GetLoanStatus() != "Active" {
+		t.Errorf("Expected cached status Active, got %s", cached.GetLoanStatus())
+	}
+
+	fresh, _ := client.Fresh().GetLoan("1")
+	if fresh.GetLoanStatus() != "Paid Off" {
+		t.Errorf("Expected fresh status Paid Off, got %s", fresh.GetLoanStatus())
+	}
+
+	// The fresh read replaces the cached response
+	refreshed, _ := client.GetLoan("1")
+	if refreshed.GetLoanStatus() != "Paid Off" {
+		t.Errorf("Expected refreshed cache status Paid Off, got %s", refreshed.GetLoanStatus())
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests.Load())
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	server, requests := newCountingServer(t, nil)
+	client := NewClient(server.URL, "key", "tenant")
+	client.EnableCache(CacheConfig{TTLs: map[string]time.Duration{"Loans": 20 * time.Millisecond}})
+
+	client.GetLoan("1")
+	client.GetLoan("1")
+	time.Sleep(30 * time.Millisecond)
+	client.GetLoan("1")
+
+	if requests.Load() != 2 {
+		t.Errorf("Expected a new request after expiry, got %d requests", requests.Load())
+	}
+}
+
+func TestCacheDisabledResource(t *testing.T) {
+	server, requests := newCountingServer(t, nil)
+	client := NewClient(server.URL, "key", "tenant")
+	client.EnableCache(CacheConfig{TTLs: map[string]time.Duration{"Loans": 0}})
+
+	client.GetLoan("1")
+	client.GetLoan("1")
+
+	if requests.Load() != 2 {
+		t.Errorf("Expected zero TTL to disable caching, got %d requests", requests.Load())
+	}
+}
+
+func TestCacheLRUEviction(t *testing.T) {
+	server, requests := newCountingServer(t, nil)
+	client := NewClient(server.URL, "key", "tenant")
+	client.EnableCache(CacheConfig{MaxEntries: 2})
+
+	client.GetLoan("1")
+	client.GetLoan("2")
+	client.GetLoan("1") // 1 is now more recently used than 2
+	client.GetLoan("3") // evicts 2
+
+	if stats := client.CacheStats(); stats.Evictions != 1 || stats.Entries != 2 {
+		t.Errorf("Expected 1 eviction and 2 entries, got %+v", stats)
+	}
+
+	before := requests.Load()
+	client.GetLoan("1")
+	if requests.Load() != before {
+		t.Error("Expected loan 1 to still be cached")
+	}
+	client.GetLoan("2")
+	if requests.Load() != before+1 {
+		t.Error("Expected loan 2 to have been evicted")
+	}
+}
+
+func TestCacheErrorsNotCached(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	server, requests := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"d": {"id": 1}}`))
+	})
+	client := NewClient(server.URL, "key", "tenant")
+	client.EnableCache(CacheConfig{})
+
+	if _, err := client.GetLoan("1"); err == nil {
+		t.Fatal("Expected error from failing server")
+	}
+	fail.Store(false)
+	if _, err := client.GetLoan("1"); err != nil {
+		t.Fatalf("Expected retry to succeed, got %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests.Load())
+	}
+}
+
+func TestCacheSingleflight(t *testing.T) {
+	release := make(chan struct{})
+	server, requests := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{"d": {"id": 1}}`))
+	})
+	client := NewClient(server.URL, "key", "tenant")
+	client.EnableCache(CacheConfig{})
+
+	const callers = 5
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetLoan("1"); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}()
+	}
+
+	// Wait until every other caller is waiting on the first request
+	deadline := time.Now().Add(5 * time.Second)
+	for client.CacheStats().Shared < callers-1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if requests.Load() != 1 {
+		t.Errorf("Expected concurrent requests to collapse into 1, got %d", requests.Load())
+	}
+}
+
+func TestCacheSingleflightWaiterCancel(t *testing.T) {
+	rc := newResponseCache(CacheConfig{})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go rc.do(context.Background(), "key", "Loans", false, func() ([]byte, error) {
+		close(started)
+		<-release
+		return []byte("body"), nil
+	})
+	<-started
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := rc.do(ctx, "key", "Loans", false, nil); err != context.Canceled {
+		t.Errorf("Expected context.Canceled for a cancelled waiter, got %v", err)
+	}
+}
+
+func TestCacheSingleflightFetcherCancel(t *testing.T) {
+	rc := newResponseCache(CacheConfig{})
+	started := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	go rc.do(ctx, "key", "Loans", false, func() ([]byte, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	<-started
+
+	// The second caller waits on the first, which then cancels
+	done := make(chan error, 1)
+	go func() {
+		body, err := rc.do(context.Background(), "key", "Loans", false, func() ([]byte, error) {
+			return []byte("body"), nil
+		})
+		if err == nil && string(body) != "body" {
+			err = fmt.Errorf("unexpected body %q", body)
+		}
+		done <- err
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for rc.stats().Shared < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Errorf("Expected the waiter to fetch again after the first caller cancelled, got %v", err)
+	}
+}
+
+func TestCacheResource(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		expected string
+	}{
+		{"/public/api/1/odata.svc/Loans(1)", "Loans"},
+		{"/public/api/1/odata.svc/Loans(1)/Transactions", "Transactions"},
+		{"/public/api/1/odata.svc/Customers('a')", "Customers"},
+		{"/public/api/1/odata.svc/Portfolios", "Portfolios"},
+		{"/public/api/1/Loans/Autopal.Search()", "Loans.Search"},
+		{"/public/api/1/Customers/Autopal.Search()", "Customers.Search"},
+	}
+
+	for _, tt := range tests {
+		if got := cacheResource(tt.endpoint); got != tt.expected {
+			t.Errorf("Expected resource %s for %s, got %s", tt.expected, tt.endpoint, got)
+		}
+	}
+
+	if isCacheableRead("POST", "/public/api/1/odata.svc/Payments") {
+		t.Error("Expected non-search POST not to be cached")
+	}
+	if !isCacheableRead("POST", "/public/api/1/Loans/Autopal.Search()") {
+		t.Error("Expected search POST to be cached")
+	}
+}
@@ -2,34 +2,91 @@ package loanpro
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
-	"os"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"loanpro-mcp-server/requestid"
 )
 
 // Client represents a LoanPro API client
 type Client struct {
-	baseURL  string
-	apiKey   string
-	tenantID string
-	client   *http.Client
+	baseURL     string
+	credentials *credentials
+	tenantID    string
+	client      *http.Client
+
+	// customFields caches the tenant's custom field definitions
+	customFields *customFieldCache
+
+	// cache holds read responses when caching is enabled. bypassCache is set on
+	// clients returned by Fresh.
+	cache       *responseCache
+	bypassCache bool
+
+	// limiter throttles outbound requests when rate limiting is enabled
+	limiter *rateLimiter
+
+	// breaker fails requests fast while LoanPro is unavailable
+	breaker *circuitBreaker
+
+	// propagateTrace sends the W3C trace context along with LoanPro requests
+	propagateTrace bool
+
+	// location is the tenant's timezone that timestamps render in, nil for UTC
+	location *time.Location
+
+	// ctx is the context requests are made with, set on clients returned by WithContext
+	ctx context.Context
+}
+
+// APIError is returned when LoanPro responds with a non-200 status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API returned status %d", e.StatusCode)
 }
 
 // NewClient creates a new LoanPro client
 func NewClient(baseURL, apiKey, tenantID string) *Client {
 	return &Client{
-		baseURL:  baseURL,
-		apiKey:   apiKey,
-		tenantID: tenantID,
+		baseURL:     baseURL,
+		credentials: &credentials{apiKey: apiKey},
+		tenantID:    tenantID,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		customFields: &customFieldCache{},
+	}
+}
+
+// WithContext returns a client whose requests are made with ctx, so they are
+// cancelled along with it and carry its trace
+func (c *Client) WithContext(ctx context.Context) *Client {
+	bound := *c
+	bound.ctx = ctx
+	return &bound
+}
+
+// requestContext returns the context set by WithContext, or the background context
+func (c *Client) requestContext() context.Context {
+	if c.ctx != nil {
+		return c.ctx
 	}
+	return context.Background()
 }
 
 // makeRequest makes a GET request to the LoanPro API
@@ -44,6 +101,14 @@ func (c *Client) makePostRequest(endpoint string, body any) ([]byte, error) {
 
 // makeRequestWithMethod makes an HTTP request with the specified method
 func (c *Client) makeRequestWithMethod(method, endpoint string, params map[string]string, body any) ([]byte, error) {
+	return c.makeRequestWithContext(c.requestContext(), method, endpoint, params, body)
+}
+
+// makeRequestWithContext makes an HTTP request that is cancelled along with ctx
+func (c *Client) makeRequestWithContext(ctx context.Context, method, endpoint string, params map[string]string, body any) (responseBody []byte, err error) {
+	ctx, span := startRequestSpan(ctx, method, endpoint)
+	defer func() { endSpan(span, err) }()
+
 	u, err := url.Parse(c.baseURL + endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("invalid URL: %w", err)
@@ -57,56 +122,102 @@ func (c *Client) makeRequestWithMethod(method, endpoint string, params map[strin
 		u.RawQuery = q.Encode()
 	}
 
-	var requestBody io.Reader
-	var bodyStr string
+	var bodyBytes []byte
 	if body != nil {
-		bodyBytes, err := json.Marshal(body)
+		bodyBytes, err = json.Marshal(body)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "[ERROR] Failed to marshal request body: %v\n", err)
+			slog.ErrorContext(ctx, "Failed to marshal request body", "error", err)
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		bodyStr = string(bodyBytes)
+	}
+
+	if c.cache != nil && isCacheableRead(method, endpoint) {
+		key := method + " " + u.String() + " " + string(bodyBytes)
+		return c.cache.do(ctx, key, cacheResource(endpoint), c.bypassCache, func() ([]byte, error) {
+			return c.doRequest(ctx, method, u, bodyBytes)
+		})
+	}
+
+	return c.doRequest(ctx, method, u, bodyBytes)
+}
+
+// doRequest sends a request through the circuit breaker and returns the response body
+func (c *Client) doRequest(ctx context.Context, method string, u *url.URL, bodyBytes []byte) ([]byte, error) {
+	if c.breaker == nil {
+		return c.sendWithRotation(ctx, method, u, bodyBytes)
+	}
+
+	done, err := c.breaker.allow()
+	if err != nil {
+		slog.WarnContext(ctx, "LoanPro request rejected by circuit breaker", "method", method, "url", u.String(), "error", err)
+		return nil, err
+	}
+	responseBody, err := c.sendWithRotation(ctx, method, u, bodyBytes)
+	done(ctx, err)
+	return responseBody, err
+}
+
+// sendRequest sends a request to the LoanPro API with the given key and returns the
+// response body
+func (c *Client) sendRequest(ctx context.Context, method string, u *url.URL, bodyBytes []byte, apiKey string) ([]byte, error) {
+	if c.limiter != nil {
+		release, err := c.limiter.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	var requestBody io.Reader
+	if bodyBytes != nil {
 		requestBody = bytes.NewReader(bodyBytes)
 	}
 
-	slog.Debug("Making LoanPro API request", "method", method, "url", u.String())
-	if bodyStr != "" {
-		slog.Debug("Request body", "data", bodyStr)
+	slog.DebugContext(ctx, "Making LoanPro API request", "method", method, "url", u.String())
+	if bodyBytes != nil {
+		slog.DebugContext(ctx, "Request body", "data", string(bodyBytes))
 	}
 
-	req, err := http.NewRequest(method, u.String(), requestBody)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), requestBody)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[ERROR] Failed to create HTTP request: %v\n", err)
+		slog.ErrorContext(ctx, "Failed to create HTTP request", "error", err)
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Autopal-Instance-Id", c.tenantID)
-	req.Header.Set("Authorization", c.apiKey)
+	req.Header.Set("Authorization", apiKey)
 	req.Header.Set("Content-Type", "application/json")
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
+	if c.propagateTrace {
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
 
+	start := time.Now()
 	resp, err := c.client.Do(req)
 	if err != nil {
-		slog.Error("LoanPro API request failed", "error", err)
-		fmt.Fprintf(os.Stderr, "[ERROR] LoanPro API request failed: %v\n", err)
+		observeRequest(endpointLabel(u.EscapedPath()), method, 0, time.Since(start))
+		slog.ErrorContext(ctx, "LoanPro API request failed", "error", err)
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	slog.Debug("Response received", "status", resp.StatusCode, "statusText", resp.Status)
+	slog.DebugContext(ctx, "Response received", "status", resp.StatusCode, "statusText", resp.Status)
 
 	responseBody, err := io.ReadAll(resp.Body)
+	observeRequest(endpointLabel(u.EscapedPath()), method, resp.StatusCode, time.Since(start))
+	trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
 	if err != nil {
-		slog.Error("Failed to read LoanPro response body", "error", err)
-		fmt.Fprintf(os.Stderr, "[ERROR] Failed to read LoanPro response body: %v\n", err)
+		slog.ErrorContext(ctx, "Failed to read LoanPro response body", "error", err)
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	slog.Debug("Response body", "data", string(responseBody))
+	slog.DebugContext(ctx, "Response body", "data", string(responseBody))
 
 	if resp.StatusCode != http.StatusOK {
-		slog.Error("LoanPro API error", "status", resp.StatusCode, "body", string(responseBody))
-		fmt.Fprintf(os.Stderr, "[ERROR] LoanPro API returned status %d: %s\n", resp.StatusCode, string(responseBody))
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+		slog.ErrorContext(ctx, "LoanPro API error", "status", resp.StatusCode, "body", string(responseBody))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(responseBody)}
 	}
 
 	return responseBody, nil
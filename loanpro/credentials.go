@@ -0,0 +1,93 @@
+package loanpro
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// credentials holds the API key so it can be rotated while requests are in flight.
+// Clients returned by Fresh share their parent's credentials.
+type credentials struct {
+	mu     sync.RWMutex
+	apiKey string
+	reload func() (string, error)
+}
+
+// SetAPIKey replaces the API key used for new requests
+func (c *Client) SetAPIKey(apiKey string) {
+	c.credentials.mu.Lock()
+	defer c.credentials.mu.Unlock()
+	c.credentials.apiKey = apiKey
+}
+
+// SetAPIKeyReloader sets a function that reads the current API key, e.g. from a
+// mounted secret. It is called when LoanPro rejects a request with 401 so a rotated
+// key is picked up before the next scheduled reload.
+func (c *Client) SetAPIKeyReloader(reload func() (string, error)) {
+	c.credentials.mu.Lock()
+	defer c.credentials.mu.Unlock()
+	c.credentials.reload = reload
+}
+
+// apiKey returns the API key for a new request
+func (c *Client) apiKey() string {
+	if c.credentials == nil {
+		return ""
+	}
+	c.credentials.mu.RLock()
+	defer c.credentials.mu.RUnlock()
+	return c.credentials.apiKey
+}
+
+// rotatedAPIKey returns the API key to retry with after used was rejected, reloading
+// it if the key hasn't changed yet. It reports false when there is no newer key.
+func (c *Client) rotatedAPIKey(used string) (string, bool) {
+	if c.credentials == nil {
+		return "", false
+	}
+	if current := c.apiKey(); current != used {
+		return current, true
+	}
+
+	c.credentials.mu.RLock()
+	reload := c.credentials.reload
+	c.credentials.mu.RUnlock()
+	if reload == nil {
+		return "", false
+	}
+
+	apiKey, err := reload()
+	if err != nil {
+		slog.Error("Failed to reload LoanPro API key", "error", err)
+		return "", false
+	}
+	if apiKey == "" || apiKey == used {
+		return "", false
+	}
+	c.SetAPIKey(apiKey)
+	return apiKey, true
+}
+
+// sendWithRotation sends a request and, if it is rejected with 401 while the API key
+// is being rotated, retries it once with the new key
+func (c *Client) sendWithRotation(ctx context.Context, method string, u *url.URL, bodyBytes []byte) ([]byte, error) {
+	apiKey := c.apiKey()
+	responseBody, err := c.sendRequest(ctx, method, u, bodyBytes, apiKey)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		return responseBody, err
+	}
+	rotated, ok := c.rotatedAPIKey(apiKey)
+	if !ok {
+		return responseBody, err
+	}
+
+	slog.InfoContext(ctx, "Retrying LoanPro request with rotated API key", "method", method, "url", u.String())
+	apiRetries.Inc(endpointLabel(u.EscapedPath()), "key_rotation")
+	return c.sendRequest(ctx, method, u, bodyBytes, rotated)
+}
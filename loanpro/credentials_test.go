@@ -0,0 +1,89 @@
+package loanpro
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// newKeyServer accepts only the "Bearer new" API key
+func newKeyServer(t *testing.T, onRequest func(r *http.Request)) (*Client, func() int32) {
+	t.Helper()
+	server, requests := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if onRequest != nil {
+			onRequest(r)
+		}
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"d": {"id": 1, "displayId": "LN1"}}`))
+	})
+	return NewClient(server.URL, "Bearer old", "tenant"), requests.Load
+}
+
+func TestCredentials_RetryAfterRotation(t *testing.T) {
+	var client *Client
+	client, requests := newKeyServer(t, func(r *http.Request) {
+		// The key is rotated while the first request is in flight
+		if r.Header.Get("Authorization") == "Bearer old" {
+			client.SetAPIKey("Bearer new")
+		}
+	})
+
+	if _, err := client.GetLoan("1"); err != nil {
+		t.Fatalf("Expected retry with the rotated key to succeed, got %v", err)
+	}
+	if requests() != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests())
+	}
+}
+
+func TestCredentials_ReloadOnUnauthorized(t *testing.T) {
+	client, requests := newKeyServer(t, nil)
+	reloads := 0
+	client.SetAPIKeyReloader(func() (string, error) {
+		reloads++
+		return "Bearer new", nil
+	})
+
+	// Fresh clients share the parent's credentials
+	if _, err := client.Fresh().GetLoan("1"); err != nil {
+		t.Fatalf("Expected retry with the reloaded key to succeed, got %v", err)
+	}
+	if _, err := client.GetLoan("2"); err != nil {
+		t.Fatalf("Expected reloaded key to be kept, got %v", err)
+	}
+	if requests() != 3 || reloads != 1 {
+		t.Errorf("Expected 3 requests and 1 reload, got %d and %d", requests(), reloads)
+	}
+}
+
+func TestCredentials_NoRetryWithoutNewKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		reload func() (string, error)
+	}{
+		{"no reloader", nil},
+		{"unchanged key", func() (string, error) { return "Bearer old", nil }},
+		{"reload error", func() (string, error) { return "", errors.New("permission denied") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, requests := newKeyServer(t, nil)
+			if tt.reload != nil {
+				client.SetAPIKeyReloader(tt.reload)
+			}
+
+			_, err := client.GetLoan("1")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+				t.Errorf("Expected 401 error, got %v", err)
+			}
+			if requests() != 1 {
+				t.Errorf("Expected no retry, got %d requests", requests())
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package loanpro
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CustomField represents a custom field definition configured in the tenant
+type CustomField struct {
+	ID         json.Number `json:"id"`
+	Name       string      `json:"customFieldName"`
+	Type       string      `json:"customFieldType"`
+	EntityType string      `json:"entityType"`
+}
+
+// CustomFieldValue represents a custom field value attached to a loan or customer
+type CustomFieldValue struct {
+	ID            json.Number `json:"id"`
+	EntityID      json.Number `json:"entityId"`
+	EntityType    string      `json:"entityType"`
+	CustomFieldID json.Number `json:"customFieldId"`
+	Value         string      `json:"customFieldValue"`
+}
+
+// CustomFieldValuesWrapper wraps custom field value results
+type CustomFieldValuesWrapper struct {
+	Results []CustomFieldValue `json:"results"`
+}
+
+// customFieldCache holds custom field definitions once they have been loaded
+type customFieldCache struct {
+	mu     sync.Mutex
+	fields map[string]CustomField
+}
+
+// GetCustomFields retrieves the tenant's custom field definitions keyed by ID.
+// Definitions rarely change, so they are fetched once and cached on the client.
+func (c *Client) GetCustomFields() (map[string]CustomField, error) {
+	c.customFields.mu.Lock()
+	defer c.customFields.mu.Unlock()
+
+	if c.customFields.fields != nil {
+		return c.customFields.fields, nil
+	}
+
+	body, err := c.getOData(NewODataQuery("CustomFields"))
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		D struct {
+			Results []CustomField `json:"results"`
+		} `json:"d"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		slog.ErrorContext(c.requestContext(), "Failed to parse GetCustomFields response", "error", err, "body", string(body))
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	fields := make(map[string]CustomField, len(response.D.Results))
+	for _, field := range response.D.Results {
+		fields[string(field.ID)] = field
+	}
+	c.customFields.fields = fields
+
+	return fields, nil
+}
+
+// resolveCustomFields maps custom field values to a name→typed-value map using
+// the cached definitions. Values without a known definition are skipped.
+func (c *Client) resolveCustomFields(wrapper *CustomFieldValuesWrapper) map[string]any {
+	if wrapper == nil || len(wrapper.Results) == 0 {
+		return nil
+	}
+
+	definitions, err := c.GetCustomFields()
+	if err != nil {
+		slog.WarnContext(c.requestContext(), "Failed to load custom field definitions", "error", err)
+		return nil
+	}
+
+	return resolveCustomFieldValues(definitions, wrapper.Results)
+}
+
+// resolveCustomFieldValues converts raw custom field values into typed values keyed by field name
+func resolveCustomFieldValues(definitions map[string]CustomField, values []CustomFieldValue) map[string]any {
+	resolved := make(map[string]any, len(values))
+	for _, value := range values {
+		definition, ok := definitions[string(value.CustomFieldID)]
+		if !ok {
+			continue
+		}
+		resolved[definition.Name] = parseCustomFieldValue(definition.Type, value.Value)
+	}
+	return resolved
+}
+
+// parseCustomFieldValue converts a raw custom field string into a Go value based on
+// the field type. Currency is exact Money. Unknown types and unparseable values are
+// returned as strings.
+func parseCustomFieldValue(fieldType, raw string) any {
+	if raw == "" {
+		return nil
+	}
+
+	// LoanPro reports types either bare ("number") or namespaced ("custom.type.number")
+	kind := strings.ToLower(fieldType)
+	if idx := strings.LastIndex(kind, "."); idx >= 0 {
+		kind = kind[idx+1:]
+	}
+
+	switch kind {
+	case "currency":
+		if m, err := ParseMoney(raw); err == nil {
+			return m
+		}
+	case "number", "decimal", "percent":
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return f
+		}
+	case "integer", "int":
+		if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return i
+		}
+	case "yes/no", "yesno", "checkbox", "boolean", "bool":
+		switch strings.ToLower(raw) {
+		case "1", "true", "yes":
+			return true
+		case "0", "false", "no":
+			return false
+		}
+	case "date":
+		if parsed, err := ParseDate(raw); err == nil {
+			if !parsed.IsSet() {
+				return nil
+			}
+			return parsed
+		}
+	}
+
+	return raw
+}
@@ -0,0 +1,106 @@
+package loanpro
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const mockCustomFieldsResponse = `{
+    "d": {
+        "results": [
+            {"id": 11, "customFieldName": "FICO at Origination", "customFieldType": "number", "entityType": "Entity.Loan"},
+            {"id": 12, "customFieldName": "Channel", "customFieldType": "text", "entityType": "Entity.Loan"},
+            {"id": 13, "customFieldName": "Verified Income", "customFieldType": "yes/no", "entityType": "Entity.Customer"}
+        ]
+    }
+}`
+
+const mockLoanWithCustomFieldsResponse = `{
+    "d": {
+        "id": 630,
+        "displayId": "LN00000630",
+        "CustomFieldValues": {
+            "results": [
+                {"id": 1, "entityId": 630, "entityType": "Entity.Loan", "customFieldId": 11, "customFieldValue": "712"},
+                {"id": 2, "entityId": 630, "entityType": "Entity.Loan", "customFieldId": 12, "customFieldValue": "Partner"},
+                {"id": 3, "entityId": 630, "entityType": "Entity.Loan", "customFieldId": 99, "customFieldValue": "orphan"}
+            ]
+        }
+    }
+}`
+
+func TestParseCustomFieldValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType string
+		raw       string
+		expected  any
+	}{
+		{"Number", "number", "712", float64(712)},
+		{"Namespaced currency", "custom.type.currency", "1500.25", MustParseMoney("1500.25")},
+		{"Currency with separators", "currency", "1,500.25", MustParseMoney("1500.25")},
+		{"Integer", "integer", "42", int64(42)},
+		{"Yes", "yes/no", "1", true},
+		{"No", "checkbox", "false", false},
+		{"Date", "date", "/Date(1427829732)/", NewDate(2015, time.March, 31)},
+		{"Empty date placeholder", "date", "0000-00-00", nil},
+		{"Text", "text", "Partner", "Partner"},
+		{"Unparseable number", "number", "n/a", "n/a"},
+		{"Empty value", "number", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseCustomFieldValue(tt.fieldType, tt.raw)
+			if result != tt.expected {
+				t.Errorf("Expected %v (%T), got %v (%T)", tt.expected, tt.expected, result, result)
+			}
+		})
+	}
+}
+
+func TestGetLoan_CustomFields(t *testing.T) {
+	definitionRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/CustomFields"):
+			definitionRequests++
+			w.Write([]byte(mockCustomFieldsResponse))
+		case strings.Contains(r.URL.Path, "/Loans("):
+			if !strings.Contains(r.URL.Query().Get("$expand"), "CustomFieldValues") {
+				t.Errorf("Expected CustomFieldValues to be expanded, got %s", r.URL.Query().Get("$expand"))
+			}
+			w.Write([]byte(mockLoanWithCustomFieldsResponse))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", "tenant")
+
+	for i := 0; i < 2; i++ {
+		loan, err := client.GetLoan("630")
+		if err != nil {
+			t.Fatalf("GetLoan failed: %v", err)
+		}
+
+		fields := loan.GetCustomFields()
+		if fields["FICO at Origination"] != float64(712) {
+			t.Errorf("Expected FICO 712, got %v", fields["FICO at Origination"])
+		}
+		if fields["Channel"] != "Partner" {
+			t.Errorf("Expected Channel Partner, got %v", fields["Channel"])
+		}
+		if len(fields) != 2 {
+			t.Errorf("Expected values without a definition to be skipped, got %v", fields)
+		}
+	}
+
+	if definitionRequests != 1 {
+		t.Errorf("Expected custom field definitions to be fetched once, got %d requests", definitionRequests)
+	}
+}
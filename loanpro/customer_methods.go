@@ -27,10 +27,12 @@ func (c *Customer) GetPhone() string {
 	return c.Phone
 }
 
-// GetCreatedDate returns the created date in human-readable format
+// GetCustomFields returns the customer's custom field values keyed by field name
+func (c *Customer) GetCustomFields() map[string]any {
+	return c.CustomFields
+}
+
+// GetCreatedDate returns the created timestamp in the tenant's timezone
 func (c *Customer) GetCreatedDate() string {
-	if parsed, err := parseLoanProDateTime(c.CreatedAt); err == nil {
-		return parsed
-	}
-	return c.CreatedAt
+	return c.CreatedAt.String()
 }
@@ -0,0 +1,188 @@
+package loanpro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSearch is returned when customer search criteria are malformed
+var ErrInvalidSearch = errors.New("invalid search criteria")
+
+// CustomerSearchOptions contains field-specific filters and pagination for customer searches.
+// Each filter targets a single field; identifiers are never wildcarded across PII fields.
+type CustomerSearchOptions struct {
+	Name      string // Matched against first name, last name and company name
+	Email     string // Exact email address (case-insensitive)
+	Phone     string // Phone number in any format; normalized to digits
+	SSNLast4  string // Last four digits of the SSN
+	BirthDate string // Date of birth (YYYY-MM-DD)
+	From      int    // Number of results to skip
+	Size      int    // Maximum number of results
+}
+
+// CustomerSearchResult contains customers and pagination metadata from a search
+type CustomerSearchResult struct {
+	Customers []Customer
+	TotalHits int // Total number of customers matching the search
+}
+
+// NormalizePhone strips formatting from a US phone number, returning its 10 digits
+func NormalizePhone(phone string) (string, error) {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+
+	normalized := digits.String()
+	if len(normalized) == 11 && normalized[0] == '1' {
+		normalized = normalized[1:]
+	}
+	if len(normalized) != 10 {
+		return "", fmt.Errorf("%w: phone must have 10 digits", ErrInvalidSearch)
+	}
+	return normalized, nil
+}
+
+// Body builds the Autopal customer search request body
+func (o *CustomerSearchOptions) Body() (map[string]any, error) {
+	var must []map[string]any
+
+	if o.Name != "" {
+		must = append(must, map[string]any{
+			"multi_match": map[string]any{
+				"query":    o.Name,
+				"fields":   []string{"firstName", "lastName", "companyName"},
+				"operator": "and",
+			},
+		})
+	}
+
+	if o.Email != "" {
+		if !strings.Contains(o.Email, "@") {
+			return nil, fmt.Errorf("%w: email must be a full address", ErrInvalidSearch)
+		}
+		must = append(must, map[string]any{
+			"term": map[string]any{"email": strings.ToLower(strings.TrimSpace(o.Email))},
+		})
+	}
+
+	if o.Phone != "" {
+		phone, err := NormalizePhone(o.Phone)
+		if err != nil {
+			return nil, err
+		}
+		must = append(must, map[string]any{
+			"term": map[string]any{"primaryPhone": phone},
+		})
+	}
+
+	if o.SSNLast4 != "" {
+		if !isDigits(o.SSNLast4, 4) {
+			return nil, fmt.Errorf("%w: SSN last four must be exactly 4 digits", ErrInvalidSearch)
+		}
+		// Anchored suffix match on the SSN; the only wildcard permitted on PII
+		must = append(must, map[string]any{
+			"wildcard": map[string]any{"ssn": "*" + o.SSNLast4},
+		})
+	}
+
+	if o.BirthDate != "" {
+		if _, err := time.Parse("2006-01-02", o.BirthDate); err != nil {
+			return nil, fmt.Errorf("%w: birth date must be YYYY-MM-DD", ErrInvalidSearch)
+		}
+		must = append(must, map[string]any{
+			"term": map[string]any{"birthDate": o.BirthDate},
+		})
+	}
+
+	// Listing every customer isn't a search, so at least one criterion is required
+	if len(must) == 0 {
+		return nil, fmt.Errorf("%w: at least one of name, email, phone, SSN last four or birth date is required", ErrInvalidSearch)
+	}
+
+	body := map[string]any{
+		"size": o.Size,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"must": must,
+			},
+		},
+	}
+	if o.From > 0 {
+		body["from"] = o.From
+	}
+
+	return body, nil
+}
+
+// fields returns the names of the filters in use, for audit logging without values
+func (o *CustomerSearchOptions) fields() []string {
+	var fields []string
+	for _, field := range []struct{ name, value string }{
+		{"name", o.Name},
+		{"email", o.Email},
+		{"phone", o.Phone},
+		{"ssnLast4", o.SSNLast4},
+		{"birthDate", o.BirthDate},
+	} {
+		if field.value != "" {
+			fields = append(fields, field.name)
+		}
+	}
+	return fields
+}
+
+// isDigits checks that s consists of exactly n ASCII digits
+func isDigits(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchCustomersWithMetadata searches for customers by specific fields and returns pagination metadata
+func (c *Client) SearchCustomersWithMetadata(opts *CustomerSearchOptions) (*CustomerSearchResult, error) {
+	if opts == nil {
+		opts = &CustomerSearchOptions{}
+	}
+	return c.searchCustomersContext(c.requestContext(), opts)
+}
+
+// searchCustomersContext runs a customer search that is cancelled along with ctx
+func (c *Client) searchCustomersContext(ctx context.Context, opts *CustomerSearchOptions) (*CustomerSearchResult, error) {
+	searchBody, err := opts.Body()
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Customer search", "fields", opts.fields(), "from", opts.From, "size", opts.Size)
+
+	body, err := c.makeRequestWithContext(ctx, "POST", "/public/api/1/Customers/Autopal.Search()", nil, searchBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var response CustomerSearchResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		slog.ErrorContext(ctx, "Failed to parse SearchCustomers response", "error", err, "body", string(body))
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	c.localizeCustomers(response.D.Results)
+
+	return &CustomerSearchResult{
+		Customers: response.D.Results,
+		TotalHits: response.D.Summary.TotalHits,
+	}, nil
+}
@@ -0,0 +1,154 @@
+package loanpro
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		hasError bool
+	}{
+		{"(555) 123-4567", "5551234567", false},
+		{"555.123.4567", "5551234567", false},
+		{"+1 555 123 4567", "5551234567", false},
+		{"5551234567", "5551234567", false},
+		{"123-4567", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := NormalizePhone(tt.input)
+			if tt.hasError {
+				if !errors.Is(err, ErrInvalidSearch) {
+					t.Errorf("Expected ErrInvalidSearch, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCustomerSearchOptions_Body(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     CustomerSearchOptions
+		expected string
+	}{
+		{
+			name:     "Pagination is kept",
+			opts:     CustomerSearchOptions{Email: "a@example.com", From: 20, Size: 10},
+			expected: `{"from":20,"query":{"bool":{"must":[{"term":{"email":"a@example.com"}}]}},"size":10}`,
+		},
+		{
+			name:     "Name only searches name fields",
+			opts:     CustomerSearchOptions{Name: "Doe", Size: 10},
+			expected: `{"query":{"bool":{"must":[{"multi_match":{"fields":["firstName","lastName","companyName"],"operator":"and","query":"Doe"}}]}},"size":10}`,
+		},
+		{
+			name:     "Exact email is lowercased",
+			opts:     CustomerSearchOptions{Email: " John.Doe@Example.com ", Size: 10},
+			expected: `{"query":{"bool":{"must":[{"term":{"email":"john.doe@example.com"}}]}},"size":10}`,
+		},
+		{
+			name: "Identifiers combine",
+			opts: CustomerSearchOptions{Phone: "(555) 123-4567", SSNLast4: "6789", BirthDate: "1980-01-31", Size: 5},
+			expected: `{"query":{"bool":{"must":[{"term":{"primaryPhone":"5551234567"}},` +
+				`{"wildcard":{"ssn":"*6789"}},{"term":{"birthDate":"1980-01-31"}}]}},"size":5}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := tt.opts.Body()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result := marshalBody(t, body); result != tt.expected {
+				t.Errorf("Unexpected search body:\n got: %s\nwant: %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCustomerSearchOptions_RequiresCriteria(t *testing.T) {
+	for _, opts := range []CustomerSearchOptions{
+		{Size: 10},
+		{From: 20, Size: 10},
+	} {
+		if _, err := opts.Body(); !errors.Is(err, ErrInvalidSearch) {
+			t.Errorf("Expected ErrInvalidSearch for %+v, got %v", opts, err)
+		}
+	}
+}
+
+func TestCustomerSearchOptions_NameNeverSearchesPII(t *testing.T) {
+	body, err := (&CustomerSearchOptions{Name: "12", Size: 10}).Body()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data := marshalBody(t, body)
+	for _, field := range []string{"ssn", "email", "primaryPhone", "birthDate", "*12*"} {
+		if strings.Contains(data, `"`+field+`"`) {
+			t.Errorf("Expected name search not to touch %s, got %s", field, data)
+		}
+	}
+}
+
+func TestCustomerSearchOptions_Invalid(t *testing.T) {
+	tests := []CustomerSearchOptions{
+		{Email: "john.doe"},
+		{Phone: "555-1234"},
+		{SSNLast4: "123"},
+		{SSNLast4: "123-45-6789"},
+		{BirthDate: "01/31/1980"},
+	}
+
+	for _, opts := range tests {
+		if _, err := opts.Body(); !errors.Is(err, ErrInvalidSearch) {
+			t.Errorf("Expected ErrInvalidSearch for %+v, got %v", opts, err)
+		}
+	}
+}
+
+func TestSearchCustomersWithMetadata(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &requestBody)
+		w.Write([]byte(`{"d":{"results":[{"id":7,"firstName":"John","lastName":"Doe"}],"summary":{"totalHits":42,"totalTime":1}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", "tenant")
+
+	result, err := client.SearchCustomersWithMetadata(&CustomerSearchOptions{SSNLast4: "6789", From: 10, Size: 1})
+	if err != nil {
+		t.Fatalf("SearchCustomersWithMetadata failed: %v", err)
+	}
+
+	if len(result.Customers) != 1 || result.Customers[0].GetID() != 7 {
+		t.Errorf("Unexpected customers: %+v", result.Customers)
+	}
+	if result.TotalHits != 42 {
+		t.Errorf("Expected 42 total hits, got %d", result.TotalHits)
+	}
+	if requestBody["from"] != float64(10) {
+		t.Errorf("Expected from 10 in request, got %v", requestBody["from"])
+	}
+}
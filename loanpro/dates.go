@@ -0,0 +1,312 @@
+package loanpro
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidDate is returned when a date or timestamp isn't in a format LoanPro uses
+var ErrInvalidDate = errors.New("invalid date")
+
+// loanProTimestamp matches LoanPro's /Date(n)/ form, with an optional zone offset suffix
+var loanProTimestamp = regexp.MustCompile(`^/Date\((-?\d+)([+-]\d{4})?\)/$`)
+
+// millisecondThreshold separates /Date(n)/ values in seconds from values in
+// milliseconds. In seconds it falls in the year 5138, so real dates never reach it.
+const millisecondThreshold = 100_000_000_000
+
+// dateLayouts are the ISO forms accepted for dates and timestamps. Forms without a
+// zone are taken as UTC, which is how LoanPro stores them.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02",
+}
+
+// SetLocation sets the tenant's timezone, e.g. America/New_York, that timestamps
+// of the loans and customers the client returns render in. A nil location is UTC.
+// It should be called before the client is used.
+func (c *Client) SetLocation(loc *time.Location) {
+	c.location = loc
+}
+
+// Location returns the tenant's timezone that timestamps render in
+func (c *Client) Location() *time.Location {
+	if c.location == nil {
+		return time.UTC
+	}
+	return c.location
+}
+
+// localizeLoans sets the location timestamps of loans render in
+func (c *Client) localizeLoans(loans []Loan) {
+	for i := range loans {
+		loans[i].Created = loans[i].Created.In(c.location)
+	}
+}
+
+// localizeCustomers sets the location timestamps of customers render in
+func (c *Client) localizeCustomers(customers []Customer) {
+	for i := range customers {
+		customers[i].CreatedAt = customers[i].CreatedAt.In(c.location)
+	}
+}
+
+// parseLoanProTime parses a /Date(n)/ or ISO value into a UTC time. Empty values and
+// LoanPro's year-zero placeholders for missing dates return the zero time.
+func parseLoanProTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.HasPrefix(s, "0000-00-00") {
+		return time.Time{}, nil
+	}
+
+	var t time.Time
+	if matches := loanProTimestamp.FindStringSubmatch(s); matches != nil {
+		n, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+		}
+		if n >= millisecondThreshold || n <= -millisecondThreshold {
+			t = time.UnixMilli(n)
+		} else {
+			t = time.Unix(n, 0)
+		}
+	} else {
+		parsed := false
+		for _, layout := range dateLayouts {
+			if p, err := time.Parse(layout, s); err == nil {
+				t = p
+				parsed = true
+				break
+			}
+		}
+		if !parsed {
+			return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+		}
+	}
+
+	if t.Year() < 1 {
+		return time.Time{}, nil
+	}
+	if t.UTC().Year() > 9999 {
+		return time.Time{}, fmt.Errorf("%w: %q is out of range", ErrInvalidDate, s)
+	}
+	return t.UTC(), nil
+}
+
+// unmarshalLoanProTime decodes a JSON string or null into a UTC time
+func unmarshalLoanProTime(data []byte) (time.Time, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return time.Time{}, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidDate, data)
+	}
+	return parseLoanProTime(s)
+}
+
+// rawDate returns the text of a JSON date value that couldn't be parsed
+func rawDate(data []byte) string {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return s
+	}
+	return string(bytes.TrimSpace(data))
+}
+
+// Date is a calendar date such as a contract or payment date. LoanPro sends these as
+// midnight UTC, so the date is taken in UTC and is not shifted into the tenant
+// location. The zero Date is unset and formats as an empty string.
+type Date struct {
+	t   time.Time
+	raw string // Value received when it couldn't be parsed
+}
+
+// NewDate creates a date from a year, month and day
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{t: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate parses a /Date(n)/ or ISO date. Any time of day is dropped.
+func ParseDate(s string) (Date, error) {
+	t, err := parseLoanProTime(s)
+	if err != nil || t.IsZero() {
+		return Date{}, err
+	}
+	return NewDate(t.Date()), nil
+}
+
+// MustParseDate is like ParseDate but panics on invalid input. It is intended for
+// constants and tests.
+func MustParseDate(s string) Date {
+	d, err := ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// IsSet reports whether the date was present
+func (d Date) IsSet() bool {
+	return !d.t.IsZero()
+}
+
+// Time returns the date as midnight UTC
+func (d Date) Time() time.Time {
+	return d.t
+}
+
+// Before reports whether d is before other
+func (d Date) Before(other Date) bool {
+	return d.t.Before(other.t)
+}
+
+// After reports whether d is after other
+func (d Date) After(other Date) bool {
+	return d.t.After(other.t)
+}
+
+// String formats the date as YYYY-MM-DD, or returns the value received when it
+// couldn't be parsed
+func (d Date) String() string {
+	if !d.IsSet() {
+		return d.raw
+	}
+	return d.t.Format("2006-01-02")
+}
+
+// UnmarshalJSON accepts /Date(n)/ and ISO date strings. null and "" leave the date
+// unset. A value that can't be parsed also leaves it unset but is kept as its text,
+// so one malformed field doesn't fail the whole record.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	t, err := unmarshalLoanProTime(data)
+	if err != nil {
+		*d = Date{raw: rawDate(data)}
+		return nil
+	}
+	*d = Date{}
+	if !t.IsZero() {
+		*d = NewDate(t.Date())
+	}
+	return nil
+}
+
+// MarshalJSON encodes the date as YYYY-MM-DD, or null when unset
+func (d Date) MarshalJSON() ([]byte, error) {
+	if !d.IsSet() {
+		if d.raw != "" {
+			return json.Marshal(d.raw)
+		}
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// DateTime is a point in time such as a creation timestamp. It renders in the
+// tenant's location, set by the client that fetched it, so late-evening events show
+// on the tenant's calendar day. The zero DateTime is unset and formats as an empty
+// string.
+type DateTime struct {
+	t   time.Time
+	loc *time.Location // Location it renders in, nil for UTC
+	raw string         // Value received when it couldn't be parsed
+}
+
+// NewDateTime creates a DateTime from a time
+func NewDateTime(t time.Time) DateTime {
+	return DateTime{t: t.UTC()}
+}
+
+// ParseDateTime parses a /Date(n)/ or ISO timestamp
+func ParseDateTime(s string) (DateTime, error) {
+	t, err := parseLoanProTime(s)
+	if err != nil {
+		return DateTime{}, err
+	}
+	return DateTime{t: t}, nil
+}
+
+// MustParseDateTime is like ParseDateTime but panics on invalid input. It is
+// intended for constants and tests.
+func MustParseDateTime(s string) DateTime {
+	dt, err := ParseDateTime(s)
+	if err != nil {
+		panic(err)
+	}
+	return dt
+}
+
+// IsSet reports whether the timestamp was present
+func (dt DateTime) IsSet() bool {
+	return !dt.t.IsZero()
+}
+
+// Time returns the timestamp in UTC
+func (dt DateTime) Time() time.Time {
+	return dt.t
+}
+
+// In returns the timestamp rendering in loc. A nil location renders in UTC.
+func (dt DateTime) In(loc *time.Location) DateTime {
+	dt.loc = loc
+	return dt
+}
+
+// Local returns the timestamp in the tenant location
+func (dt DateTime) Local() time.Time {
+	if dt.loc == nil {
+		return dt.t
+	}
+	return dt.t.In(dt.loc)
+}
+
+// Date returns the tenant-local calendar date of the timestamp
+func (dt DateTime) Date() Date {
+	if !dt.IsSet() {
+		return Date{}
+	}
+	return NewDate(dt.Local().Date())
+}
+
+// String formats the timestamp in the tenant location, e.g. "2025-01-15 10:30:00 EST",
+// or returns the value received when it couldn't be parsed
+func (dt DateTime) String() string {
+	if !dt.IsSet() {
+		return dt.raw
+	}
+	return dt.Local().Format("2006-01-02 15:04:05 MST")
+}
+
+// UnmarshalJSON accepts /Date(n)/ and ISO timestamp strings. null and "" leave the
+// timestamp unset, as does a value that can't be parsed, which is kept as its text.
+func (dt *DateTime) UnmarshalJSON(data []byte) error {
+	t, err := unmarshalLoanProTime(data)
+	if err != nil {
+		*dt = DateTime{raw: rawDate(data)}
+		return nil
+	}
+	*dt = DateTime{t: t}
+	return nil
+}
+
+// MarshalJSON encodes the timestamp as RFC 3339 in UTC, or null when unset
+func (dt DateTime) MarshalJSON() ([]byte, error) {
+	if !dt.IsSet() {
+		if dt.raw != "" {
+			return json.Marshal(dt.raw)
+		}
+		return []byte("null"), nil
+	}
+	return json.Marshal(dt.t.Format(time.RFC3339))
+}
@@ -0,0 +1,289 @@
+package loanpro
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"loanpro-mcp-server/loanpro/fake"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		hasError bool
+	}{
+		{name: "Unix seconds", input: "/Date(1427829732)/", expected: "2015-03-31"},
+		{name: "Unix milliseconds", input: "/Date(1427829732000)/", expected: "2015-03-31"},
+		{name: "Zone offset suffix", input: "/Date(1427829732+0000)/", expected: "2015-03-31"},
+		{name: "Zero timestamp", input: "/Date(0)/", expected: "1970-01-01"},
+		{name: "Year zero placeholder", input: "/Date(-62169984000)/", expected: ""},
+		{name: "Empty string", input: "", expected: ""},
+		{name: "Zero ISO placeholder", input: "0000-00-00", expected: ""},
+		{name: "ISO date", input: "2025-01-15", expected: "2025-01-15"},
+		{name: "ISO datetime keeps its date", input: "2025-01-15T23:30:00", expected: "2025-01-15"},
+		{name: "RFC 3339 is taken in UTC", input: "2025-01-15T23:30:00-05:00", expected: "2025-01-16"},
+		{name: "Invalid timestamp", input: "/Date(invalid)/", hasError: true},
+		{name: "Garbage", input: "next tuesday", hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseDate(tt.input)
+
+			if tt.hasError {
+				if !errors.Is(err, ErrInvalidDate) {
+					t.Errorf("Expected ErrInvalidDate, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if result.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestParseDateTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		hasError bool
+	}{
+		{name: "Unix seconds", input: "/Date(1427829732)/", expected: "2015-03-31 19:22:12 UTC"},
+		{name: "Unix milliseconds", input: "/Date(1427829732500)/", expected: "2015-03-31 19:22:12 UTC"},
+		{name: "Empty string", input: "", expected: ""},
+		{name: "Already formatted datetime", input: "2025-01-15 10:30:00 UTC", expected: "2025-01-15 10:30:00 UTC"},
+		{name: "ISO without zone", input: "2025-01-15T10:30:00", expected: "2025-01-15 10:30:00 UTC"},
+		{name: "RFC 3339 with offset", input: "2025-01-15T10:30:00-05:00", expected: "2025-01-15 15:30:00 UTC"},
+		{name: "Invalid timestamp", input: "/Date(invalid)/", hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseDateTime(tt.input)
+
+			if tt.hasError {
+				if !errors.Is(err, ErrInvalidDate) {
+					t.Errorf("Expected ErrInvalidDate, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if result.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestDateTime_In(t *testing.T) {
+	eastern, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Time zone data unavailable: %v", err)
+	}
+
+	// 2025-01-16 02:30 UTC is the evening of the 15th in New York
+	created := MustParseDateTime("2025-01-16T02:30:00Z").In(eastern)
+	if created.String() != "2025-01-15 21:30:00 EST" {
+		t.Errorf("Expected 2025-01-15 21:30:00 EST, got %s", created.String())
+	}
+	if created.Date().String() != "2025-01-15" {
+		t.Errorf("Expected tenant-local date 2025-01-15, got %s", created.Date().String())
+	}
+
+	// Calendar dates are sent as midnight UTC and must not shift to the previous day
+	contract := MustParseDate("/Date(1764547200)/")
+	if contract.String() != "2025-12-01" {
+		t.Errorf("Expected 2025-12-01, got %s", contract.String())
+	}
+
+	if utc := created.In(nil); utc.String() != "2025-01-16 02:30:00 UTC" {
+		t.Errorf("Expected nil location to render in UTC, got %s", utc.String())
+	}
+}
+
+func TestClient_Location(t *testing.T) {
+	eastern, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Time zone data unavailable: %v", err)
+	}
+	server := httptest.NewServer(fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID))
+	defer server.Close()
+
+	// Each client renders timestamps in its own tenant's timezone
+	newYork := NewClient(server.URL, fake.DefaultAPIKey, fake.DefaultTenantID)
+	newYork.SetLocation(eastern)
+	utc := NewClient(server.URL, fake.DefaultAPIKey, fake.DefaultTenantID)
+
+	local, err := newYork.GetLoan("101")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loan, err := utc.GetLoan("101")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(loan.GetCreatedDate(), " UTC") {
+		t.Errorf("Expected UTC without a location, got %s", loan.GetCreatedDate())
+	}
+	if expected := loan.Created.Time().In(eastern).Format("2006-01-02 15:04:05 MST"); local.GetCreatedDate() != expected {
+		t.Errorf("Expected %s in New York, got %s", expected, local.GetCreatedDate())
+	}
+
+	results, err := newYork.SearchLoans("", "", 1)
+	if err != nil || len(results) == 0 {
+		t.Fatalf("Expected search results, got %v", err)
+	}
+	if created := results[0].GetCreatedDate(); created != "" && strings.HasSuffix(created, " UTC") {
+		t.Errorf("Expected search results in New York time, got %s", created)
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	var decoded struct {
+		Date     Date     `json:"date"`
+		Created  DateTime `json:"created"`
+		Null     Date     `json:"null"`
+		Empty    DateTime `json:"empty"`
+		Missing  Date     `json:"missing"`
+		ISODate  Date     `json:"isoDate"`
+		Readable DateTime `json:"readable"`
+	}
+	data := `{"date": "/Date(1764892800)/", "created": "/Date(1427829732)/", "null": null, "empty": "", "isoDate": "2025-04-15", "readable": "2025-01-15 10:30:00"}`
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if decoded.Date.String() != "2025-12-05" {
+		t.Errorf("Expected 2025-12-05, got %s", decoded.Date)
+	}
+	if !decoded.Created.Time().Equal(time.Unix(1427829732, 0)) {
+		t.Errorf("Expected created at 1427829732, got %v", decoded.Created.Time())
+	}
+	if decoded.Null.IsSet() || decoded.Empty.IsSet() || decoded.Missing.IsSet() {
+		t.Error("Expected null, empty and missing dates to be unset")
+	}
+	if decoded.ISODate.String() != "2025-04-15" {
+		t.Errorf("Expected 2025-04-15, got %s", decoded.ISODate)
+	}
+	if decoded.Readable.String() != "2025-01-15 10:30:00 UTC" {
+		t.Errorf("Expected 2025-01-15 10:30:00 UTC, got %s", decoded.Readable)
+	}
+
+	encoded, err := json.Marshal(map[string]any{"date": NewDate(2025, 4, 15), "created": MustParseDateTime("/Date(1427829732)/"), "unset": Date{}})
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if string(encoded) != `{"created":"2015-03-31T19:22:12Z","date":"2025-04-15","unset":null}` {
+		t.Errorf("Unexpected JSON: %s", encoded)
+	}
+
+	// Malformed values are kept as text rather than failing the whole record
+	var record struct {
+		ID      int      `json:"id"`
+		Date    Date     `json:"date"`
+		Created DateTime `json:"created"`
+	}
+	if err := json.Unmarshal([]byte(`{"id": 7, "date": "next tuesday", "created": 12345}`), &record); err != nil {
+		t.Fatalf("Expected malformed dates not to fail unmarshalling, got %v", err)
+	}
+	if record.ID != 7 || record.Date.IsSet() || record.Date.String() != "next tuesday" || record.Created.String() != "12345" {
+		t.Errorf("Expected malformed dates to be unset and kept as text, got %+v", record)
+	}
+	if encoded, _ := json.Marshal(record.Date); string(encoded) != `"next tuesday"` {
+		t.Errorf("Expected the malformed date to encode as its text, got %s", encoded)
+	}
+}
+
+func FuzzParseDate(f *testing.F) {
+	for _, seed := range []string{
+		"/Date(1427829732)/",
+		"/Date(1427829732000)/",
+		"/Date(-62169984000)/",
+		"/Date(1427829732+0500)/",
+		"/Date(9223372036854775807)/",
+		"2025-01-15",
+		"2025-01-15T10:30:00Z",
+		"2025-01-15 10:30:00 UTC",
+		"0000-00-00",
+		"",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		d, err := ParseDate(input)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidDate) {
+				t.Fatalf("Unexpected error type for %q: %v", input, err)
+			}
+			return
+		}
+		if !d.IsSet() {
+			return
+		}
+
+		// A parsed date must be midnight UTC and round-trip through its string form
+		if h, m, s := d.Time().Clock(); h != 0 || m != 0 || s != 0 || d.Time().Location() != time.UTC {
+			t.Fatalf("Date %q parsed to non-midnight time %v", input, d.Time())
+		}
+		again, err := ParseDate(d.String())
+		if err != nil || again != d {
+			t.Fatalf("Date %q did not round-trip: %q -> %v (%v)", input, d.String(), again, err)
+		}
+	})
+}
+
+func FuzzParseDateTime(f *testing.F) {
+	for _, seed := range []string{
+		"/Date(1427829732)/",
+		"/Date(-1)/",
+		"/Date(99999999999)/",
+		"/Date(100000000000)/",
+		"2025-01-15T10:30:00.123456789-05:00",
+		"2025-01-15 10:30:00",
+		"/Date()/",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		dt, err := ParseDateTime(input)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidDate) {
+				t.Fatalf("Unexpected error type for %q: %v", input, err)
+			}
+			return
+		}
+		if !dt.IsSet() {
+			return
+		}
+
+		if dt.Time().Location() != time.UTC {
+			t.Fatalf("DateTime %q not normalized to UTC: %v", input, dt.Time())
+		}
+		data, err := json.Marshal(dt)
+		if err != nil {
+			t.Fatalf("Failed to marshal %q: %v", input, err)
+		}
+		var decoded DateTime
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Failed to unmarshal %s: %v", data, err)
+		}
+		if !decoded.Time().Equal(dt.Time().Truncate(time.Second)) {
+			t.Fatalf("DateTime %q did not round-trip through JSON: %v != %v", input, decoded.Time(), dt.Time())
+		}
+	})
+}
@@ -0,0 +1,470 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// defaultSearchSize is the page size Elasticsearch uses when a request omits size
+const defaultSearchSize = 10
+
+// searchRequest is an Autopal search request body
+type searchRequest struct {
+	Query map[string]any   `json:"query"`
+	Sort  []map[string]any `json:"sort"`
+	Size  *int             `json:"size"`
+	From  int              `json:"from"`
+}
+
+// serveSearch evaluates an Autopal search against a set of documents
+func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request, body []byte, docs []Record) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowedException", "search requires POST")
+		return
+	}
+
+	var req searchRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequestException", "invalid search body: "+err.Error())
+		return
+	}
+	if req.Query == nil {
+		req.Query = map[string]any{"match_all": map[string]any{}}
+	}
+
+	var hits []Record
+	for _, doc := range docs {
+		ok, err := matches(doc, req.Query)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BadRequestException", err.Error())
+			return
+		}
+		if ok {
+			hits = append(hits, doc)
+		}
+	}
+
+	if err := sortHits(hits, req.Sort); err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequestException", err.Error())
+		return
+	}
+
+	size := defaultSearchSize
+	if req.Size != nil {
+		size = *req.Size
+	}
+	page := hits[min(max(req.From, 0), len(hits)):]
+	page = page[:min(max(size, 0), len(page))]
+
+	results := make([]Record, 0, len(page))
+	for _, doc := range page {
+		result := maps.Clone(doc)
+		for _, field := range hiddenSearchFields {
+			delete(result, field)
+		}
+		results = append(results, result)
+	}
+
+	writeJSON(w, map[string]any{
+		"d": map[string]any{
+			"results": results,
+			"summary": map[string]any{
+				"totalHits": len(hits),
+				"totalTime": 1,
+			},
+		},
+	})
+}
+
+// matches evaluates a single query clause against a document
+func matches(doc Record, clause map[string]any) (bool, error) {
+	if len(clause) != 1 {
+		return false, fmt.Errorf("query clause must have exactly one key, got %d", len(clause))
+	}
+
+	for kind, body := range clause {
+		switch kind {
+		case "match_all":
+			return true, nil
+		case "bool":
+			return matchBool(doc, body)
+		case "match":
+			return matchField(body, func(field string, query any) bool {
+				return anyToken(doc, []string{field}, tokens(formatValue(query)))
+			})
+		case "term":
+			return matchField(body, func(field string, value any) bool {
+				return slices.ContainsFunc(fieldValues(doc, field), func(v any) bool {
+					return formatValue(v) == formatValue(value)
+				})
+			})
+		case "range":
+			return matchField(body, func(field string, bounds any) bool {
+				return matchRange(doc, field, bounds)
+			})
+		case "exists":
+			spec, _ := body.(map[string]any)
+			field, _ := spec["field"].(string)
+			return slices.ContainsFunc(fieldValues(doc, field), func(v any) bool {
+				return formatValue(v) != ""
+			}), nil
+		case "multi_match":
+			return matchMultiMatch(doc, body)
+		case "wildcard":
+			return matchField(body, func(field string, pattern any) bool {
+				return anyGlob(doc, []string{field}, formatValue(pattern))
+			})
+		case "query_string":
+			return matchQueryString(doc, body)
+		default:
+			return false, fmt.Errorf("query clause %q is not supported by the fake", kind)
+		}
+	}
+	return false, nil
+}
+
+// matchBool evaluates a bool query. Every must and filter clause has to match, no
+// must_not clause may match, and at least minimum_should_match should clauses have
+// to match (one when there are no must clauses).
+func matchBool(doc Record, body any) (bool, error) {
+	spec, ok := body.(map[string]any)
+	if !ok {
+		return false, fmt.Errorf("bool query must be an object")
+	}
+
+	for key := range spec {
+		switch key {
+		case "must", "filter", "should", "must_not", "minimum_should_match":
+		default:
+			return false, fmt.Errorf("bool option %q is not supported by the fake", key)
+		}
+	}
+
+	for _, key := range []string{"must", "filter"} {
+		for _, clause := range clauseList(spec[key]) {
+			ok, err := matches(doc, clause)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+	}
+
+	for _, clause := range clauseList(spec["must_not"]) {
+		ok, err := matches(doc, clause)
+		if err != nil || ok {
+			return false, err
+		}
+	}
+
+	should := clauseList(spec["should"])
+	if len(should) == 0 {
+		return true, nil
+	}
+	required := 0
+	if len(clauseList(spec["must"])) == 0 {
+		required = 1
+	}
+	if n, ok := spec["minimum_should_match"].(float64); ok {
+		required = int(n)
+	}
+
+	matched := 0
+	for _, clause := range should {
+		ok, err := matches(doc, clause)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			matched++
+		}
+	}
+	return matched >= required, nil
+}
+
+// clauseList normalizes a clause or array of clauses
+func clauseList(value any) []map[string]any {
+	switch v := value.(type) {
+	case map[string]any:
+		return []map[string]any{v}
+	case []any:
+		var clauses []map[string]any
+		for _, item := range v {
+			if clause, ok := item.(map[string]any); ok {
+				clauses = append(clauses, clause)
+			}
+		}
+		return clauses
+	}
+	return nil
+}
+
+// matchField evaluates a {field: value} clause. Values given in long form, e.g.
+// {"field": {"query": "x"}} or {"field": {"value": "x"}}, are unwrapped.
+func matchField(body any, match func(field string, value any) bool) (bool, error) {
+	spec, ok := body.(map[string]any)
+	if !ok || len(spec) != 1 {
+		return false, fmt.Errorf("field clause must name exactly one field")
+	}
+	for field, value := range spec {
+		if long, ok := value.(map[string]any); ok {
+			if v, ok := long["query"]; ok {
+				value = v
+			} else if v, ok := long["value"]; ok {
+				value = v
+			}
+		}
+		return match(field, value), nil
+	}
+	return false, nil
+}
+
+// matchRange reports whether any value of a field is within the bounds
+func matchRange(doc Record, field string, bounds any) bool {
+	spec, _ := bounds.(map[string]any)
+	return slices.ContainsFunc(fieldValues(doc, field), func(v any) bool {
+		for op, bound := range spec {
+			c := compareValues(v, bound)
+			switch op {
+			case "gt":
+				if c <= 0 {
+					return false
+				}
+			case "gte":
+				if c < 0 {
+					return false
+				}
+			case "lt":
+				if c >= 0 {
+					return false
+				}
+			case "lte":
+				if c > 0 {
+					return false
+				}
+			}
+		}
+		return true
+	})
+}
+
+// matchMultiMatch evaluates a multi_match query across several fields
+func matchMultiMatch(doc Record, body any) (bool, error) {
+	spec, ok := body.(map[string]any)
+	if !ok {
+		return false, fmt.Errorf("multi_match query must be an object")
+	}
+	fields := stringList(spec["fields"])
+	queryTokens := tokens(formatValue(spec["query"]))
+
+	if operator, _ := spec["operator"].(string); strings.EqualFold(operator, "and") {
+		for _, token := range queryTokens {
+			if !anyToken(doc, fields, []string{token}) {
+				return false, nil
+			}
+		}
+		return len(queryTokens) > 0, nil
+	}
+	return anyToken(doc, fields, queryTokens), nil
+}
+
+// matchQueryString evaluates a query_string query of whitespace-separated terms,
+// which may contain * and ? wildcards
+func matchQueryString(doc Record, body any) (bool, error) {
+	spec, ok := body.(map[string]any)
+	if !ok {
+		return false, fmt.Errorf("query_string query must be an object")
+	}
+	fields := stringList(spec["fields"])
+	terms := strings.Fields(formatValue(spec["query"]))
+	and := strings.EqualFold(formatValue(spec["default_operator"]), "and")
+
+	matched := 0
+	for _, term := range terms {
+		var ok bool
+		if strings.ContainsAny(term, "*?") {
+			ok = anyGlob(doc, fields, term)
+		} else {
+			ok = anyToken(doc, fields, tokens(term))
+		}
+		if ok {
+			matched++
+		} else if and {
+			return false, nil
+		}
+	}
+	return matched > 0, nil
+}
+
+// anyToken reports whether any of the fields contains any of the tokens
+func anyToken(doc Record, fields, queryTokens []string) bool {
+	for _, field := range fields {
+		for _, value := range fieldValues(doc, field) {
+			for _, token := range tokens(formatValue(value)) {
+				if slices.Contains(queryTokens, token) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// anyGlob reports whether any value of the fields matches a case-insensitive
+// wildcard pattern
+func anyGlob(doc Record, fields []string, pattern string) bool {
+	expr := regexp.QuoteMeta(strings.ToLower(pattern))
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	re := regexp.MustCompile("^" + expr + "$")
+
+	for _, field := range fields {
+		for _, value := range fieldValues(doc, field) {
+			if re.MatchString(strings.ToLower(formatValue(value))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sortHits sorts search hits by sort clauses such as {"created": {"order": "desc"}}.
+// Documents missing the field sort last.
+func sortHits(hits []Record, clauses []map[string]any) error {
+	type sortKey struct {
+		field      string
+		descending bool
+	}
+	var keys []sortKey
+	for _, clause := range clauses {
+		for field, spec := range clause {
+			order := "asc"
+			switch v := spec.(type) {
+			case string:
+				order = v
+			case map[string]any:
+				if o, ok := v["order"].(string); ok {
+					order = o
+				}
+			}
+			if order != "asc" && order != "desc" {
+				return fmt.Errorf("invalid sort order %q for %s", order, field)
+			}
+			keys = append(keys, sortKey{field: field, descending: order == "desc"})
+		}
+	}
+
+	slices.SortStableFunc(hits, func(a, b Record) int {
+		for _, key := range keys {
+			av, aok := a[key.field]
+			bv, bok := b[key.field]
+			if !aok || !bok {
+				if aok != bok {
+					if aok {
+						return -1
+					}
+					return 1
+				}
+				continue
+			}
+			c := compareValues(av, bv)
+			if key.descending {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return nil
+}
+
+// fieldValues returns the values of a possibly dotted field path, flattening arrays,
+// e.g. "customers.lastName"
+func fieldValues(value any, path string) []any {
+	if path == "" {
+		switch v := value.(type) {
+		case nil:
+			return nil
+		case []any:
+			return v
+		}
+		return []any{value}
+	}
+
+	field, rest, _ := strings.Cut(path, ".")
+	switch v := value.(type) {
+	case Record:
+		return fieldValues(v[field], rest)
+	case []Record:
+		var values []any
+		for _, item := range v {
+			values = append(values, fieldValues(item, path)...)
+		}
+		return values
+	case []any:
+		var values []any
+		for _, item := range v {
+			values = append(values, fieldValues(item, path)...)
+		}
+		return values
+	}
+	return nil
+}
+
+// tokens lower-cases text and splits it into alphanumeric words, like the standard analyzer
+func tokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// stringList converts a JSON array of strings
+func stringList(value any) []string {
+	items, _ := value.([]any)
+	var list []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// compareValues orders two values numerically when both are numbers and as strings otherwise
+func compareValues(a, b any) int {
+	as, bs := formatValue(a), formatValue(b)
+	af, aerr := strconv.ParseFloat(as, 64)
+	bf, berr := strconv.ParseFloat(bs, 64)
+	if aerr == nil && berr == nil {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(as, bs)
+}
+
+// formatValue renders a scalar for comparison, formatting whole floats without a
+// decimal point so 101 and 101.0 compare equal
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
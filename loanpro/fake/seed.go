@@ -0,0 +1,370 @@
+package fake
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// SeedAsOf is the date the seeded data is current as of. Payment schedules run up to
+// this date, and days past due and next payment dates are computed from it.
+var SeedAsOf = time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+// seedCustomer describes a seeded borrower
+type seedCustomer struct {
+	id        int
+	firstName string
+	lastName  string
+	company   string
+	email     string
+	phone     string
+	ssn       string
+	birthDate string
+	created   time.Time
+	contact   string // Preferred Contact custom field
+}
+
+// seedLoan describes a seeded installment loan. Payments are made on schedule
+// except for the last missed periods before SeedAsOf.
+type seedLoan struct {
+	id           int
+	displayID    string
+	customerID   int
+	amountCents  int64
+	rate         float64 // annual percentage rate
+	term         int     // months
+	contract     time.Time
+	missed       int
+	subStatus    string // sub-status for open loans
+	portfolio    int
+	subPortfolio int
+	fico         int
+	channel      string
+}
+
+var seedCustomers = []seedCustomer{
+	{1, "Jane", "Smith", "", "jane.smith@example.com", "5551234567", "123456789", "1985-04-12", time.Date(2022, 11, 2, 15, 4, 5, 0, time.UTC), "Email"},
+	{2, "John", "Doe", "", "john.doe@example.com", "5559876543", "987654321", "1979-11-03", time.Date(2023, 1, 20, 9, 30, 0, 0, time.UTC), "Phone"},
+	{3, "Maria", "Garcia", "", "maria.garcia@example.com", "5555550123", "555443333", "1992-07-21", time.Date(2023, 6, 8, 18, 45, 12, 0, time.UTC), "SMS"},
+	{4, "Robert", "Johnson", "Johnson Hauling LLC", "rjohnson@example.com", "5550001111", "111223333", "1968-02-29", time.Date(2020, 3, 14, 12, 0, 0, 0, time.UTC), "Phone"},
+}
+
+var seedLoans = []seedLoan{
+	{101, "LN00000101", 1, 1_500_000, 6.99, 60, time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC), 0, "Current", 1, 11, 742, "Dealer"},
+	{102, "LN00000102", 2, 2_250_000, 9.49, 72, time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), 2, "Past Due", 1, 12, 655, "Online"},
+	{103, "LN00000103", 3, 800_000, 12.5, 36, time.Date(2023, 7, 10, 0, 0, 0, 0, time.UTC), 4, "Collections", 2, 21, 601, "Online"},
+	{104, "LN00000104", 4, 3_500_000, 5.25, 36, time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC), 0, "", 2, 0, 780, "Branch"},
+	{105, "LN00000105", 1, 500_000, 7.75, 24, time.Date(2025, 2, 20, 0, 0, 0, 0, time.UTC), 0, "Current", 1, 12, 748, "Online"},
+}
+
+// portfolioTitles are the titles of the seeded portfolios, by ID
+var portfolioTitles = map[int]string{1: "Prime Auto", 2: "Near Prime"}
+
+// subStatusIDs are the tenant's loan sub-status IDs
+var subStatusIDs = map[string]int{
+	"Current":      21,
+	"Past Due":     22,
+	"Collections":  23,
+	"Paid In Full": 31,
+}
+
+// Custom field definition IDs
+const (
+	ficoFieldID    = 201
+	channelFieldID = 202
+	contactFieldID = 203
+)
+
+// SeedData returns a fresh copy of the demo tenant: four customers, five loans in
+// various states with payment and transaction history, two portfolios and a few
+// custom fields.
+func SeedData() *Data {
+	data := &Data{
+		CustomFields: []Record{
+			{"id": ficoFieldID, "customFieldName": "FICO at Origination", "customFieldType": "number", "entityType": "Entity.Loan"},
+			{"id": channelFieldID, "customFieldName": "Channel", "customFieldType": "select", "entityType": "Entity.Loan"},
+			{"id": contactFieldID, "customFieldName": "Preferred Contact", "customFieldType": "select", "entityType": "Entity.Customer"},
+		},
+		Portfolios: []Record{
+			{"id": 1, "title": "Prime Auto", "numPrefix": "PA", "numSuffix": "", "active": 1, "SubPortfolios": []Record{
+				{"id": 11, "parent": 1, "title": "Dealer Direct", "active": 1},
+				{"id": 12, "parent": 1, "title": "Online", "active": 1},
+			}},
+			{"id": 2, "title": "Near Prime", "numPrefix": "NP", "numSuffix": "", "active": 1, "SubPortfolios": []Record{
+				{"id": 21, "parent": 2, "title": "Refinance", "active": 1},
+			}},
+		},
+	}
+
+	customers := make(map[int]seedCustomer, len(seedCustomers))
+	for _, c := range seedCustomers {
+		customers[c.id] = c
+		data.Customers = append(data.Customers, Record{
+			"id":        c.id,
+			"firstName": c.firstName,
+			"lastName":  c.lastName,
+			"email":     c.email,
+			"phone":     c.phone,
+			"createdAt": odataDate(c.created),
+			"CustomFieldValues": []Record{
+				customFieldValue(c.id*10+3, c.id, "Entity.Customer", contactFieldID, c.contact),
+			},
+		})
+		data.CustomerIndex = append(data.CustomerIndex, Record{
+			"id":           c.id,
+			"firstName":    c.firstName,
+			"lastName":     c.lastName,
+			"companyName":  c.company,
+			"email":        c.email,
+			"primaryPhone": c.phone,
+			"phone":        c.phone,
+			"ssn":          c.ssn,
+			"birthDate":    c.birthDate,
+			"createdAt":    c.created.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	for _, l := range seedLoans {
+		loan, doc := buildLoan(l, customers[l.customerID])
+		data.Loans = append(data.Loans, loan)
+		data.LoanIndex = append(data.LoanIndex, doc)
+	}
+
+	return data
+}
+
+// buildLoan generates a loan's OData entity and search document by running its
+// amortization schedule up to SeedAsOf
+func buildLoan(l seedLoan, customer seedCustomer) (Record, Record) {
+	monthlyRate := l.rate / 1200
+	payment := int64(math.Round(float64(l.amountCents) * monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(l.term)))))
+	firstPayment := l.contract.AddDate(0, 1, 0)
+
+	// Periods due on or before SeedAsOf
+	due := 0
+	for due < l.term && !firstPayment.AddDate(0, due, 0).After(SeedAsOf) {
+		due++
+	}
+	paidPeriods := due - l.missed
+
+	var (
+		payments     []Record
+		transactions []Record
+		balance      = l.amountCents
+		txID         = l.id * 1000
+	)
+	for period := 1; period <= due; period++ {
+		date := firstPayment.AddDate(0, period-1, 0)
+		interest := int64(math.Round(float64(balance) * monthlyRate))
+		principal := payment - interest
+		if period == l.term || principal > balance {
+			principal = balance
+		}
+		amount := principal + interest
+
+		txID++
+		transactions = append(transactions, Record{
+			"id":               txID,
+			"txId":             fmt.Sprintf("%d-0-spm%d", l.id, period),
+			"entityType":       "Entity.Loan",
+			"entityId":         l.id,
+			"modId":            0,
+			"date":             odataDate(date),
+			"period":           period,
+			"periodStart":      odataDate(date.AddDate(0, -1, 0)),
+			"periodEnd":        odataDate(date.AddDate(0, 0, -1)),
+			"title":            fmt.Sprintf("Scheduled Payment: %d", period),
+			"type":             "scheduledPayment",
+			"infoOnly":         0,
+			"chargeAmount":     cents(amount),
+			"chargeInterest":   cents(interest),
+			"chargePrincipal":  cents(principal),
+			"chargeFees":       cents(0),
+			"principalBalance": cents(balance),
+			"displayOrder":     "0",
+		})
+
+		if period > paidPeriods {
+			if period == paidPeriods+1 {
+				txID++
+				transactions = append(transactions, Record{
+					"id":               txID,
+					"txId":             fmt.Sprintf("%d-0-fee%d", l.id, period),
+					"entityType":       "Entity.Loan",
+					"entityId":         l.id,
+					"date":             odataDate(date.AddDate(0, 0, 10)),
+					"period":           period,
+					"title":            "Late Fee",
+					"type":             "charge.latefee",
+					"infoOnly":         0,
+					"chargeAmount":     cents(2500),
+					"chargeFees":       cents(2500),
+					"principalBalance": cents(balance),
+					"displayOrder":     "1",
+				})
+			}
+			continue
+		}
+
+		paymentID := l.id*100 + period
+		payments = append(payments, Record{
+			"id":              paymentID,
+			"loanId":          l.id,
+			"date":            odataDate(date),
+			"amount":          cents(amount),
+			"paymentTypeId":   1,
+			"paymentMethodId": 2,
+			"info":            "ACH autopay",
+			"active":          1,
+		})
+
+		balance -= principal
+		txID++
+		transactions = append(transactions, Record{
+			"id":               txID,
+			"txId":             fmt.Sprintf("%d-0-pay%d", l.id, paymentID),
+			"entityType":       "Entity.Loan",
+			"entityId":         l.id,
+			"modId":            0,
+			"date":             odataDate(date),
+			"period":           period,
+			"title":            "Payment: ACH autopay",
+			"type":             "payment",
+			"infoOnly":         0,
+			"paymentId":        paymentID,
+			"paymentDisplayId": paymentID,
+			"paymentAmount":    cents(amount),
+			"paymentInterest":  cents(interest),
+			"paymentPrincipal": cents(principal),
+			"paymentFees":      cents(0),
+			"principalBalance": cents(balance),
+			"displayOrder":     "2",
+		})
+	}
+
+	status, subStatus, statusID := "Open", l.subStatus, 2
+	if balance == 0 {
+		status, subStatus, statusID = "Paid Off", "Paid In Full", 3
+	}
+
+	// Payoff is the balance plus interest accrued since the last due date and any late fee
+	payoff := balance + int64(math.Round(float64(balance)*monthlyRate/2))
+	daysPastDue := 0
+	amountDue := int64(0)
+	if l.missed > 0 {
+		firstMissed := firstPayment.AddDate(0, paidPeriods, 0)
+		daysPastDue = int(SeedAsOf.Sub(firstMissed).Hours() / 24)
+		amountDue = payment*int64(l.missed) + 2500
+		payoff += 2500
+	}
+
+	statusEntry := Record{
+		"id":               l.id*10 + 1,
+		"loanId":           l.id,
+		"date":             odataDate(SeedAsOf),
+		"principalBalance": cents(balance),
+		"payoff":           cents(payoff),
+		"amountDue":        cents(amountDue),
+		"daysPastDue":      daysPastDue,
+		"loanStatusText":   status,
+	}
+	var nextPaymentDate time.Time
+	if balance > 0 {
+		nextPaymentDate = firstPayment.AddDate(0, due, 0)
+		statusEntry["nextPaymentDate"] = odataDate(nextPaymentDate)
+		statusEntry["nextPaymentAmount"] = cents(payment)
+	}
+
+	created := l.contract.Add(-36 * time.Hour)
+	name := customer.firstName + " " + customer.lastName
+	loan := Record{
+		"id":        l.id,
+		"displayId": l.displayID,
+		"title":     l.displayID,
+		"active":    1,
+		"archived":  0,
+		"created":   odataDate(created),
+		"LoanSettings": Record{
+			"id":              l.id,
+			"loanId":          l.id,
+			"loanStatusId":    statusID,
+			"loanSubStatusId": subStatusIDs[subStatus],
+			"autopayEnabled":  1,
+		},
+		"LoanSetup": Record{
+			"id":               l.id,
+			"loanId":           l.id,
+			"contractDate":     odataDate(l.contract),
+			"loanType":         "loan.type.installment",
+			"loanClass":        "loan.class.consumer",
+			"loanAmount":       cents(l.amountCents),
+			"payment":          cents(payment),
+			"firstPaymentDate": odataDate(firstPayment),
+			"loanRate":         fmt.Sprintf("%.4f", l.rate),
+			"loanTerm":         fmt.Sprintf("%d", l.term),
+		},
+		"Customers": []Record{
+			{"id": customer.id, "firstName": customer.firstName, "lastName": customer.lastName, "email": customer.email},
+		},
+		"StatusArchive": []Record{statusEntry},
+		"Portfolios":    []Record{{"id": l.portfolio, "title": portfolioTitles[l.portfolio], "active": 1}},
+		"CustomFieldValues": []Record{
+			customFieldValue(l.id*10+1, l.id, "Entity.Loan", ficoFieldID, fmt.Sprintf("%d", l.fico)),
+			customFieldValue(l.id*10+2, l.id, "Entity.Loan", channelFieldID, l.channel),
+		},
+		"Payments":     payments,
+		"Transactions": transactions,
+	}
+
+	doc := Record{
+		"id":                  l.id,
+		"displayId":           l.displayID,
+		"title":               l.displayID,
+		"active":              1,
+		"created":             created.Format("2006-01-02 15:04:05"),
+		"primaryCustomerName": name,
+		"loanStatusText":      status,
+		"loanSubStatusText":   subStatus,
+		"principalBalance":    float64(balance) / 100,
+		"daysPastDue":         daysPastDue,
+		"portfolios":          []any{l.portfolio},
+		"customers": []Record{
+			{"id": customer.id, "firstName": customer.firstName, "lastName": customer.lastName, "email": customer.email},
+		},
+	}
+	if l.subPortfolio != 0 {
+		doc["subPortfolios"] = []any{l.subPortfolio}
+	}
+	if balance > 0 {
+		doc["nextPaymentAmount"] = float64(payment) / 100
+		doc["nextPaymentDate"] = nextPaymentDate.Format("2006-01-02")
+	}
+
+	return loan, doc
+}
+
+// customFieldValue builds a custom field value entity
+func customFieldValue(id, entityID int, entityType string, fieldID int, value string) Record {
+	return Record{
+		"id":               id,
+		"entityId":         entityID,
+		"entityType":       entityType,
+		"customFieldId":    fieldID,
+		"customFieldValue": value,
+	}
+}
+
+// odataDate formats a time in LoanPro's /Date(seconds)/ form
+func odataDate(t time.Time) string {
+	return fmt.Sprintf("/Date(%d)/", t.Unix())
+}
+
+// cents formats an amount in cents as a decimal string, the way LoanPro sends currency
+func cents(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
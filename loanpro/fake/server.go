@@ -0,0 +1,464 @@
+// Package fake is an in-memory stand-in for the LoanPro API, for offline demos and
+// integration tests. It serves seeded loans, customers, payments and transactions
+// over the same URL paths, headers and OData envelopes as LoanPro, understands
+// $expand, $select, $orderby, $top, $skip and $inlinecount, and evaluates the
+// subset of the Autopal search DSL that the client sends.
+//
+// Requests without the configured Authorization and Autopal-Instance-Id headers are
+// rejected, and unsupported query options or search clauses return 400, so drift
+// between the client and the API shape shows up as a failing test.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Default credentials accepted by the fake
+const (
+	DefaultAPIKey   = "Bearer fake-loanpro-token"
+	DefaultTenantID = "fake-tenant"
+)
+
+const (
+	odataPrefix  = "/public/api/1/odata.svc/"
+	searchPrefix = "/public/api/1/"
+)
+
+// Record is a LoanPro entity or search document as it appears on the wire.
+// Keys starting with an upper-case letter are navigation properties holding a
+// Record or []Record; they are only included when requested with $expand.
+type Record = map[string]any
+
+// Data is the content served by the fake
+type Data struct {
+	Loans         []Record
+	Customers     []Record
+	CustomFields  []Record
+	Portfolios    []Record
+	LoanIndex     []Record // Documents searched by Loans/Autopal.Search()
+	CustomerIndex []Record // Documents searched by Customers/Autopal.Search()
+}
+
+// hiddenSearchFields can be searched on but are never returned in search results
+var hiddenSearchFields = []string{"ssn"}
+
+// Request is a request received by the fake
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   []byte
+}
+
+// Server is a fake LoanPro API. It implements http.Handler.
+type Server struct {
+	apiKey   string
+	tenantID string
+
+	data        *Data // read-only once the server is created
+	maxPageSize int
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// NewServer creates a fake serving the seeded demo tenant
+func NewServer(apiKey, tenantID string) *Server {
+	return NewServerWithData(apiKey, tenantID, SeedData())
+}
+
+// NewServerWithData creates a fake serving the given data
+func NewServerWithData(apiKey, tenantID string, data *Data) *Server {
+	return &Server{
+		apiKey:   apiKey,
+		tenantID: tenantID,
+		data:     data,
+	}
+}
+
+// SetMaxPageSize caps collection pages at n results whatever $top asks for, like
+// LoanPro's server-side page limit. 0, the default, means no cap. It mustn't be
+// called while the server is handling requests.
+func (s *Server) SetMaxPageSize(n int) {
+	s.maxPageSize = n
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// ServeHTTP handles a LoanPro API request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequestException", "failed to read request body")
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Body: body})
+	s.mu.Unlock()
+
+	slog.Debug("Fake LoanPro request", "method", r.Method, "path", r.URL.Path, "query", r.URL.RawQuery)
+
+	if r.Header.Get("Authorization") != s.apiKey || r.Header.Get("Autopal-Instance-Id") != s.tenantID {
+		writeError(w, http.StatusUnauthorized, "UnauthorizedException", "invalid API token or tenant ID")
+		return
+	}
+
+	path := r.URL.Path
+	switch {
+	case strings.HasPrefix(path, odataPrefix):
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowedException", r.Method+" is not supported by the fake")
+			return
+		}
+		s.serveOData(w, r, strings.TrimPrefix(path, odataPrefix))
+
+	case path == searchPrefix+"Loans/Autopal.Search()":
+		s.serveSearch(w, r, body, s.data.LoanIndex)
+
+	case path == searchPrefix+"Customers/Autopal.Search()":
+		s.serveSearch(w, r, body, s.data.CustomerIndex)
+
+	default:
+		writeError(w, http.StatusNotFound, "NotFoundException", "no route for "+path)
+	}
+}
+
+// entitySet returns the records in an OData entity set
+func (s *Server) entitySet(name string) ([]Record, bool) {
+	switch name {
+	case "Loans":
+		return s.data.Loans, true
+	case "Customers":
+		return s.data.Customers, true
+	case "CustomFields":
+		return s.data.CustomFields, true
+	case "Portfolios":
+		return s.data.Portfolios, true
+	}
+	return nil, false
+}
+
+// odataOptions are the parsed system query options of an OData request
+type odataOptions struct {
+	expand      expandTree
+	selects     []string
+	orderBy     []string
+	top         int
+	skip        int
+	inlineCount bool
+}
+
+// expandTree holds $expand paths, e.g. "Customers/PrimaryAddress" as
+// {"Customers": {"PrimaryAddress": {}}}
+type expandTree map[string]expandTree
+
+// parseODataOptions parses the system query options the fake supports
+func parseODataOptions(query url.Values) (*odataOptions, error) {
+	opts := &odataOptions{expand: expandTree{}, top: -1}
+	for key, values := range query {
+		value := values[0]
+		switch key {
+		case "$expand":
+			for _, path := range strings.Split(value, ",") {
+				node := opts.expand
+				for _, property := range strings.Split(strings.TrimSpace(path), "/") {
+					if node[property] == nil {
+						node[property] = expandTree{}
+					}
+					node = node[property]
+				}
+			}
+		case "$select":
+			for _, field := range strings.Split(value, ",") {
+				opts.selects = append(opts.selects, strings.TrimSpace(field))
+			}
+		case "$orderby":
+			opts.orderBy = strings.Split(value, ",")
+		case "$top", "$skip":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid %s value %q", key, value)
+			}
+			if key == "$top" {
+				opts.top = n
+			} else {
+				opts.skip = n
+			}
+		case "$inlinecount":
+			if value != "allpages" && value != "none" {
+				return nil, fmt.Errorf("invalid $inlinecount value %q", value)
+			}
+			opts.inlineCount = value == "allpages"
+		default:
+			if strings.HasPrefix(key, "$") {
+				return nil, fmt.Errorf("query option %s is not supported by the fake", key)
+			}
+		}
+	}
+	return opts, nil
+}
+
+// serveOData serves an entity set, a single entity, or an entity's navigation property
+func (s *Server) serveOData(w http.ResponseWriter, r *http.Request, resource string) {
+	opts, err := parseODataOptions(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequestException", err.Error())
+		return
+	}
+
+	segments := strings.Split(resource, "/")
+	if len(segments) > 2 {
+		writeError(w, http.StatusBadRequest, "BadRequestException", "only one level of navigation is supported by the fake")
+		return
+	}
+
+	set, key, err := parseSegment(segments[0])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequestException", err.Error())
+		return
+	}
+
+	records, ok := s.entitySet(set)
+	if !ok {
+		writeError(w, http.StatusNotFound, "NotFoundException", "unknown entity set "+set)
+		return
+	}
+
+	uri := "http://" + r.Host + odataPrefix + set
+	if key == "" {
+		if len(segments) > 1 {
+			writeError(w, http.StatusBadRequest, "BadRequestException", "navigation requires an entity key")
+			return
+		}
+		s.writeCollection(w, uri, records, opts)
+		return
+	}
+
+	entity := findByID(records, key)
+	if entity == nil {
+		writeError(w, http.StatusNotFound, "NotFoundException", fmt.Sprintf("%s(%s) not found", set, key))
+		return
+	}
+	uri += "(" + key + ")"
+
+	if len(segments) == 1 {
+		s.writeEntity(w, uri, entity, opts)
+		return
+	}
+
+	property := segments[1]
+	switch nav := entity[property].(type) {
+	case []Record:
+		s.writeCollection(w, uri+"/"+property, nav, opts)
+	case Record:
+		s.writeEntity(w, uri+"/"+property, nav, opts)
+	default:
+		writeError(w, http.StatusNotFound, "NotFoundException", fmt.Sprintf("%s has no navigation property %s", set, property))
+	}
+}
+
+// parseSegment splits "Loans(101)" into the entity set and key. String keys are
+// quoted with embedded quotes doubled, e.g. "Loans('a”b')".
+func parseSegment(segment string) (string, string, error) {
+	open := strings.Index(segment, "(")
+	if open < 0 {
+		return segment, "", nil
+	}
+	if !strings.HasSuffix(segment, ")") {
+		return "", "", fmt.Errorf("malformed key in %q", segment)
+	}
+
+	set, key := segment[:open], segment[open+1:len(segment)-1]
+	if len(key) >= 2 && key[0] == '\'' && key[len(key)-1] == '\'' {
+		key = strings.ReplaceAll(key[1:len(key)-1], "''", "'")
+	} else if _, err := strconv.Atoi(key); err != nil {
+		return "", "", fmt.Errorf("malformed key in %q", segment)
+	}
+	return set, key, nil
+}
+
+// findByID returns the record whose id matches key
+func findByID(records []Record, key string) Record {
+	for _, record := range records {
+		if formatValue(record["id"]) == key {
+			return record
+		}
+	}
+	return nil
+}
+
+// writeEntity writes a single entity response
+func (s *Server) writeEntity(w http.ResponseWriter, uri string, entity Record, opts *odataOptions) {
+	rendered, err := render(entity, uri, opts.expand, opts.selects)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequestException", err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"d": rendered})
+}
+
+// writeCollection writes a page of a collection with LoanPro's summary block and,
+// when requested, the OData inline count
+func (s *Server) writeCollection(w http.ResponseWriter, uri string, records []Record, opts *odataOptions) {
+	sorted, err := orderRecords(records, opts.orderBy)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequestException", err.Error())
+		return
+	}
+
+	total := len(sorted)
+	page := sorted[min(opts.skip, total):]
+	if opts.top >= 0 && opts.top < len(page) {
+		page = page[:opts.top]
+	}
+	if s.maxPageSize > 0 && s.maxPageSize < len(page) {
+		page = page[:s.maxPageSize]
+	}
+
+	results := make([]Record, 0, len(page))
+	for _, record := range page {
+		rendered, err := render(record, fmt.Sprintf("%s(%s)", uri, formatValue(record["id"])), opts.expand, opts.selects)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BadRequestException", err.Error())
+			return
+		}
+		results = append(results, rendered)
+	}
+
+	d := map[string]any{
+		"results": results,
+		"summary": map[string]any{
+			"start":    opts.skip,
+			"pageSize": len(results),
+			"total":    total,
+		},
+	}
+	if opts.inlineCount {
+		d["__count"] = strconv.Itoa(total)
+	}
+	writeJSON(w, map[string]any{"d": d})
+}
+
+// orderRecords sorts a copy of records by "$orderby" clauses such as "date desc"
+func orderRecords(records []Record, clauses []string) ([]Record, error) {
+	sorted := slices.Clone(records)
+	if len(clauses) == 0 {
+		return sorted, nil
+	}
+
+	type sortKey struct {
+		field      string
+		descending bool
+	}
+	var keys []sortKey
+	for _, clause := range clauses {
+		fields := strings.Fields(clause)
+		if len(fields) == 0 || len(fields) > 2 || (len(fields) == 2 && fields[1] != "asc" && fields[1] != "desc") {
+			return nil, fmt.Errorf("invalid $orderby clause %q", clause)
+		}
+		keys = append(keys, sortKey{field: fields[0], descending: len(fields) == 2 && fields[1] == "desc"})
+	}
+
+	slices.SortStableFunc(sorted, func(a, b Record) int {
+		for _, key := range keys {
+			c := compareValues(a[key.field], b[key.field])
+			if key.descending {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return sorted, nil
+}
+
+// render copies an entity for output. Navigation properties are expanded when listed
+// in expand and otherwise replaced with a __deferred link, as LoanPro does.
+func render(entity Record, uri string, expand expandTree, selects []string) (Record, error) {
+	for property := range expand {
+		if _, ok := entity[property]; !ok || !isNavigation(property) {
+			return nil, fmt.Errorf("%s is not a navigation property", property)
+		}
+	}
+
+	out := Record{"__metadata": map[string]any{"uri": uri}}
+	for key, value := range entity {
+		if !isNavigation(key) {
+			if len(selects) == 0 || key == "id" || slices.Contains(selects, key) {
+				out[key] = value
+			}
+			continue
+		}
+
+		sub, expanded := expand[key]
+		if !expanded {
+			out[key] = map[string]any{"__deferred": map[string]any{"uri": uri + "/" + key}}
+			continue
+		}
+
+		switch nav := value.(type) {
+		case Record:
+			rendered, err := render(nav, uri+"/"+key, sub, nil)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = rendered
+		case []Record:
+			results := make([]Record, 0, len(nav))
+			for _, item := range nav {
+				rendered, err := render(item, fmt.Sprintf("%s/%s(%s)", uri, key, formatValue(item["id"])), sub, nil)
+				if err != nil {
+					return nil, err
+				}
+				results = append(results, rendered)
+			}
+			out[key] = map[string]any{"results": results}
+		}
+	}
+	return out, nil
+}
+
+// isNavigation reports whether a property is a navigation property
+func isNavigation(property string) bool {
+	for _, r := range property {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+// writeJSON writes a 200 response
+func writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes an error in LoanPro's error envelope
+func writeError(w http.ResponseWriter, status int, errorType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{
+			"message": message,
+			"type":    errorType,
+			"code":    status,
+		},
+	})
+}
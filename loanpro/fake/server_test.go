@@ -0,0 +1,416 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"loanpro-mcp-server/loanpro"
+)
+
+// newTestClient starts the seeded fake and returns a client pointed at it
+func newTestClient(t *testing.T) (*loanpro.Client, *Server) {
+	t.Helper()
+	fake := NewServer(DefaultAPIKey, DefaultTenantID)
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return loanpro.NewClient(server.URL, DefaultAPIKey, DefaultTenantID), fake
+}
+
+func TestFake_RejectsMissingCredentials(t *testing.T) {
+	server := httptest.NewServer(NewServer(DefaultAPIKey, DefaultTenantID))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		apiKey   string
+		tenantID string
+	}{
+		{"wrong key", "Bearer nope", DefaultTenantID},
+		{"wrong tenant", DefaultAPIKey, "other-tenant"},
+		{"no credentials", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := loanpro.NewClient(server.URL, tt.apiKey, tt.tenantID)
+			_, err := client.GetLoan("101")
+			var apiErr *loanpro.APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+				t.Errorf("Expected 401, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFake_GetLoan(t *testing.T) {
+	client, fake := newTestClient(t)
+
+	loan, err := client.GetLoan("102")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if loan.GetDisplayID() != "LN00000102" {
+		t.Errorf("Expected display ID LN00000102, got %s", loan.GetDisplayID())
+	}
+	if loan.GetLoanAmount() != "22500.00" {
+		t.Errorf("Expected loan amount 22500.00, got %s", loan.GetLoanAmount())
+	}
+	if loan.GetPrimaryCustomerName() != "John Doe" {
+		t.Errorf("Expected customer John Doe, got %s", loan.GetPrimaryCustomerName())
+	}
+	if loan.GetLoanStatus() != "Open" {
+		t.Errorf("Expected status Open, got %s", loan.GetLoanStatus())
+	}
+	if loan.GetDaysPastDue() == "0" {
+		t.Error("Expected past due loan to have days past due")
+	}
+	if loan.GetContractDate() != "2023-02-01" {
+		t.Errorf("Expected contract date 2023-02-01, got %s", loan.GetContractDate())
+	}
+	if loan.GetCustomFields()["Channel"] != "Online" {
+		t.Errorf("Expected Channel custom field Online, got %v", loan.GetCustomFields())
+	}
+	if ids := loan.GetPortfolioIDs(); len(ids) != 1 || ids[0] != "1" {
+		t.Errorf("Expected portfolio 1, got %v", ids)
+	}
+
+	// The expanded navigation properties are the ones GetLoan asks for
+	var loanRequest *Request
+	for _, req := range fake.Requests() {
+		if req.Path == "/public/api/1/odata.svc/Loans(102)" {
+			loanRequest = &req
+		}
+	}
+	if loanRequest == nil {
+		t.Fatal("Expected a request for Loans(102)")
+	}
+	if expand := loanRequest.Query.Get("$expand"); !strings.Contains(expand, "StatusArchive") {
+		t.Errorf("Expected StatusArchive to be expanded, got %q", expand)
+	}
+}
+
+func TestFake_GetLoanNotFound(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	_, err := client.GetLoan("999")
+	var apiErr *loanpro.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected 404, got %v", err)
+	}
+	if !strings.Contains(apiErr.Body, "Loans(999) not found") {
+		t.Errorf("Expected LoanPro error envelope, got %s", apiErr.Body)
+	}
+}
+
+func TestFake_UnexpandedNavigationIsDeferred(t *testing.T) {
+	server := httptest.NewServer(NewServer(DefaultAPIKey, DefaultTenantID))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/public/api/1/odata.svc/Loans(101)?$select=displayId", nil)
+	req.Header.Set("Authorization", DefaultAPIKey)
+	req.Header.Set("Autopal-Instance-Id", DefaultTenantID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		D map[string]any `json:"d"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+
+	if _, ok := body.D["Payments"].(map[string]any)["__deferred"]; !ok {
+		t.Errorf("Expected deferred Payments link, got %v", body.D["Payments"])
+	}
+	if _, ok := body.D["title"]; ok {
+		t.Error("Expected $select to drop unselected fields")
+	}
+	if body.D["displayId"] != "LN00000101" {
+		t.Errorf("Expected selected displayId, got %v", body.D["displayId"])
+	}
+}
+
+func TestFake_UnsupportedQueryOption(t *testing.T) {
+	server := httptest.NewServer(NewServer(DefaultAPIKey, DefaultTenantID))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/public/api/1/odata.svc/Loans?$filter=id%20eq%201", nil)
+	req.Header.Set("Authorization", DefaultAPIKey)
+	req.Header.Set("Autopal-Instance-Id", DefaultTenantID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unsupported option, got %d", resp.StatusCode)
+	}
+}
+
+func TestFake_TransactionsPaging(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	first, err := client.GetLoanTransactionsWithMetadata("101", &loanpro.TransactionOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(first.Transactions) != 10 || !first.HasMore {
+		t.Fatalf("Expected a full first page with more results, got %d (has more %v)", len(first.Transactions), first.HasMore)
+	}
+
+	second, err := client.GetLoanTransactionsWithMetadata("101", &loanpro.TransactionOptions{Limit: 10, Offset: 10})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if second.Total != first.Total {
+		t.Errorf("Expected the same total on each page, got %d and %d", first.Total, second.Total)
+	}
+	if first.Transactions[0].ID == second.Transactions[0].ID {
+		t.Error("Expected $skip to move to a different page")
+	}
+
+	count := 0
+	for _, err := range client.IterateLoanTransactions(context.Background(), "101", 7) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		count++
+	}
+	if count != first.Total {
+		t.Errorf("Expected iterator to visit all %d transactions, got %d", first.Total, count)
+	}
+}
+
+func TestFake_Payments(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	payments, err := client.GetLoanPayments("103")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(payments) == 0 {
+		t.Fatal("Expected seeded payments")
+	}
+
+	count := 0
+	for payment, err := range client.IterateLoanPayments(context.Background(), "103", 4) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !payment.Amount.IsSet() {
+			t.Errorf("Expected payment amount, got %+v", payment)
+		}
+		count++
+	}
+	if count != len(payments) {
+		t.Errorf("Expected paged iteration to return %d payments, got %d", len(payments), count)
+	}
+}
+
+func TestFake_PaymentsAcrossPageCap(t *testing.T) {
+	client, fake := newTestClient(t)
+	all, err := client.GetLoanPayments("103")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(all) < 3 {
+		t.Fatalf("Expected at least 3 seeded payments, got %d", len(all))
+	}
+
+	// With pages capped below the payment count, every page is still fetched
+	fake.SetMaxPageSize(2)
+	capped, err := client.GetLoanPayments("103")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(capped) != len(all) {
+		t.Errorf("Expected %d payments across capped pages, got %d", len(all), len(capped))
+	}
+	for i := range capped {
+		if capped[i].ID != all[i].ID {
+			t.Errorf("Expected payment %d to be %s, got %s", i, all[i].ID, capped[i].ID)
+		}
+	}
+}
+
+func TestFake_SearchLoans(t *testing.T) {
+	client, _ := newTestClient(t)
+	minBalance := 5000.0
+
+	tests := []struct {
+		name     string
+		opts     loanpro.LoanSearchOptions
+		expected []string
+	}{
+		{"all", loanpro.LoanSearchOptions{Limit: 10, SortBy: "id"}, []string{"101", "102", "103", "104", "105"}},
+		{"text", loanpro.LoanSearchOptions{SearchTerm: "Smith", Limit: 10, SortBy: "id"}, []string{"101", "105"}},
+		{"display ID", loanpro.LoanSearchOptions{SearchTerm: "LN00000103", Limit: 10}, []string{"103"}},
+		{"status", loanpro.LoanSearchOptions{Status: "Paid Off", Limit: 10}, []string{"104"}},
+		{"sub-status", loanpro.LoanSearchOptions{SubStatus: "Collections", Limit: 10}, []string{"103"}},
+		{"portfolio", loanpro.LoanSearchOptions{PortfolioID: "2", Limit: 10, SortBy: "id"}, []string{"103", "104"}},
+		{"balance", loanpro.LoanSearchOptions{MinBalance: &minBalance, Limit: 10, SortBy: "principalBalance", SortDescending: true}, []string{"102", "101"}},
+		{"created", loanpro.LoanSearchOptions{CreatedAfter: "2025-01-01", Limit: 10}, []string{"105"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loans, err := client.SearchLoansWithOptions(&tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var ids []string
+			for _, loan := range loans {
+				ids = append(ids, loan.GetID())
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected loans %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestFake_SearchLoansCursor(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	var ids []string
+	cursor := ""
+	for {
+		result, err := client.SearchLoansWithMetadata(&loanpro.LoanSearchOptions{Limit: 2, SortBy: "id", Cursor: cursor})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.TotalHits != 5 {
+			t.Errorf("Expected 5 total hits, got %d", result.TotalHits)
+		}
+		for _, loan := range result.Loans {
+			ids = append(ids, loan.GetID())
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	if strings.Join(ids, ",") != "101,102,103,104,105" {
+		t.Errorf("Expected cursor pages to cover every loan once, got %v", ids)
+	}
+}
+
+func TestFake_SearchCustomers(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	tests := []struct {
+		name     string
+		opts     loanpro.CustomerSearchOptions
+		expected []int
+	}{
+		{"name", loanpro.CustomerSearchOptions{Name: "maria garcia", Size: 10}, []int{3}},
+		{"company", loanpro.CustomerSearchOptions{Name: "Hauling", Size: 10}, []int{4}},
+		{"email", loanpro.CustomerSearchOptions{Email: "John.Doe@example.com", Size: 10}, []int{2}},
+		{"phone", loanpro.CustomerSearchOptions{Phone: "(555) 123-4567", Size: 10}, []int{1}},
+		{"ssn last four", loanpro.CustomerSearchOptions{SSNLast4: "3333", Size: 10}, []int{3, 4}},
+		{"birth date", loanpro.CustomerSearchOptions{BirthDate: "1968-02-29", Size: 10}, []int{4}},
+		{"no match", loanpro.CustomerSearchOptions{Name: "Nobody", Size: 10}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.SearchCustomersWithMetadata(&tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var ids []int
+			for _, customer := range result.Customers {
+				ids = append(ids, customer.GetID())
+			}
+			if len(ids) != len(tt.expected) || result.TotalHits != len(tt.expected) {
+				t.Fatalf("Expected customers %v, got %v (total %d)", tt.expected, ids, result.TotalHits)
+			}
+			for i := range ids {
+				if ids[i] != tt.expected[i] {
+					t.Errorf("Expected customers %v, got %v", tt.expected, ids)
+				}
+			}
+		})
+	}
+}
+
+func TestFake_SearchResultsOmitSSN(t *testing.T) {
+	client, fake := newTestClient(t)
+
+	if _, err := client.SearchCustomersWithMetadata(&loanpro.CustomerSearchOptions{SSNLast4: "6789", Size: 1}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	req, _ := http.NewRequest("POST", server.URL+"/public/api/1/Customers/Autopal.Search()", strings.NewReader(`{"query": {"match_all": {}}, "size": 10}`))
+	req.Header.Set("Authorization", DefaultAPIKey)
+	req.Header.Set("Autopal-Instance-Id", DefaultTenantID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]any
+	json.NewDecoder(resp.Body).Decode(&body)
+	encoded, _ := json.Marshal(body)
+	if strings.Contains(string(encoded), "123456789") || strings.Contains(string(encoded), `"ssn"`) {
+		t.Errorf("Expected SSNs to be omitted from search results, got %s", encoded)
+	}
+}
+
+func TestFake_UnsupportedSearchClause(t *testing.T) {
+	server := httptest.NewServer(NewServer(DefaultAPIKey, DefaultTenantID))
+	defer server.Close()
+
+	req, _ := http.NewRequest("POST", server.URL+"/public/api/1/Loans/Autopal.Search()", strings.NewReader(`{"query": {"fuzzy": {"title": "x"}}}`))
+	req.Header.Set("Authorization", DefaultAPIKey)
+	req.Header.Set("Autopal-Instance-Id", DefaultTenantID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unsupported clause, got %d", resp.StatusCode)
+	}
+}
+
+func TestFake_PortfoliosAndCustomFields(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	portfolios, err := client.GetPortfolios()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(portfolios) != 2 || len(portfolios[0].GetSubPortfolios()) != 2 {
+		t.Errorf("Expected 2 portfolios with expanded sub-portfolios, got %+v", portfolios)
+	}
+
+	fields, err := client.GetCustomFields()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fields["201"].Name != "FICO at Origination" {
+		t.Errorf("Expected FICO custom field, got %+v", fields)
+	}
+
+	customer, err := client.GetCustomer("1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if customer.GetCustomFields()["Preferred Contact"] != "Email" {
+		t.Errorf("Expected Preferred Contact custom field, got %v", customer.GetCustomFields())
+	}
+}
@@ -0,0 +1,31 @@
+package loanpro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// CheckConfig reports settings that are missing or malformed, with which every
+// request would fail
+func (c *Client) CheckConfig() error {
+	u, err := url.Parse(c.baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid API URL %q", c.baseURL)
+	}
+	if c.tenantID == "" {
+		return errors.New("tenant ID is not set")
+	}
+	if c.apiKey() == "" {
+		return errors.New("API key is not set")
+	}
+	return nil
+}
+
+// Ping makes a small authenticated request to check that LoanPro is reachable and
+// accepts the client's credentials. The response cache is bypassed.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Fresh().getODataContext(ctx, NewODataQuery("Portfolios").Select("id").Top(1))
+	return err
+}
@@ -0,0 +1,63 @@
+package loanpro
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestClient_CheckConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		client    *Client
+		expectErr bool
+	}{
+		{"valid", NewClient("https://loanpro.example.com", "Bearer key", "5200001"), false},
+		{"relative URL", NewClient("loanpro.example.com", "Bearer key", "5200001"), true},
+		{"no tenant", NewClient("https://loanpro.example.com", "Bearer key", ""), true},
+		{"no key", NewClient("https://loanpro.example.com", "", "5200001"), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.client.CheckConfig()
+			if test.expectErr && err == nil {
+				t.Error("Expected an error")
+			}
+			if !test.expectErr && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_Ping(t *testing.T) {
+	server, requests := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/public/api/1/odata.svc/Portfolios" || r.URL.Query().Get("$top") != "1" {
+			t.Errorf("Expected a single portfolio to be requested, got %s", r.URL)
+		}
+		w.Write([]byte(`{"d": {"results": []}}`))
+	})
+
+	client := NewClient(server.URL, "Bearer good", "tenant")
+	client.EnableCache(CacheConfig{})
+	for range 2 {
+		if err := client.Ping(context.Background()); err != nil {
+			t.Fatalf("Expected ping to succeed, got %v", err)
+		}
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected every ping to reach LoanPro, got %d requests", requests.Load())
+	}
+
+	var apiErr *APIError
+	err := NewClient(server.URL, "Bearer bad", "tenant").Ping(context.Background())
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 error for a bad key, got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package loanpro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"strconv"
+)
+
+// DefaultPageSize is the page size used by iterators when none is given
+const DefaultPageSize = 50
+
+// maxUnknownPages bounds iteration when responses don't report a total, so a
+// server that ignores $skip and keeps returning full pages can't loop forever
+const maxUnknownPages = 1000
+
+// ErrTooManyPages is returned when an iteration without a known total reaches
+// maxUnknownPages
+var ErrTooManyPages = errors.New("too many pages")
+
+// pageFetcher fetches up to limit items starting at offset. It returns the total
+// number of items available, or -1 when the response doesn't report one.
+type pageFetcher[T any] func(ctx context.Context, offset, limit int) ([]T, int, error)
+
+// paginate lazily fetches pages and yields their items one at a time. Iteration stops
+// when the total is reached, a page comes back empty, the consumer stops, or ctx is
+// cancelled. Without a total, a short page ends it and more than maxUnknownPages
+// yields ErrTooManyPages. Errors are yielded once and end the iteration.
+func paginate[T any](ctx context.Context, offset, pageSize int, fetch pageFetcher[T]) iter.Seq2[T, error] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	return func(yield func(T, error) bool) {
+		var zero T
+		for pages := 1; ; pages++ {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			items, total, err := fetch(ctx, offset, pageSize)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			offset += len(items)
+
+			if len(items) == 0 {
+				return
+			}
+			if total >= 0 {
+				// With a known total, keep going even if the server capped the page below pageSize
+				if offset >= total {
+					return
+				}
+			} else if len(items) < pageSize {
+				return
+			} else if pages >= maxUnknownPages {
+				yield(zero, fmt.Errorf("%w: stopped after %d pages without a total", ErrTooManyPages, pages))
+				return
+			}
+		}
+	}
+}
+
+// IterateLoanTransactions lazily iterates over a loan's full transaction history,
+// fetching pageSize transactions per request
+func (c *Client) IterateLoanTransactions(ctx context.Context, loanID string, pageSize int) iter.Seq2[Transaction, error] {
+	return c.iterateLoanTransactions(ctx, loanID, 0, pageSize)
+}
+
+// iterateLoanTransactions iterates over a loan's transactions starting at offset
+func (c *Client) iterateLoanTransactions(ctx context.Context, loanID string, offset, pageSize int) iter.Seq2[Transaction, error] {
+	return paginate(ctx, offset, pageSize, func(ctx context.Context, offset, limit int) ([]Transaction, int, error) {
+		return c.fetchTransactionsPage(ctx, loanID, offset, limit)
+	})
+}
+
+// IterateLoanPayments lazily iterates over a loan's payments, fetching pageSize payments per request
+func (c *Client) IterateLoanPayments(ctx context.Context, loanID string, pageSize int) iter.Seq2[Payment, error] {
+	return paginate(ctx, 0, pageSize, func(ctx context.Context, offset, limit int) ([]Payment, int, error) {
+		return c.fetchPaymentsPage(ctx, loanID, offset, limit)
+	})
+}
+
+// fetchPaymentsPage retrieves a single page of a loan's payments
+func (c *Client) fetchPaymentsPage(ctx context.Context, loanID string, offset, limit int) ([]Payment, int, error) {
+	query := NewODataQuery("Loans").Key(loanID).Nav("Payments").Top(limit).Skip(offset).InlineCount()
+
+	body, err := c.getODataContext(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var response struct {
+		D struct {
+			Results []Payment `json:"results"`
+			Count   string    `json:"__count"`
+		} `json:"d"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		slog.ErrorContext(ctx, "Failed to parse GetLoanPayments page response", "error", err, "body", string(body))
+		return nil, 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	total := -1
+	if count, err := strconv.Atoi(response.D.Count); err == nil {
+		total = count
+	}
+
+	return response.D.Results, total, nil
+}
+
+// IterateLoanSearch lazily iterates over every loan matching a query, using the query's
+// size as the page size. The query itself is not modified.
+func (c *Client) IterateLoanSearch(ctx context.Context, q *LoanSearchQuery) iter.Seq2[Loan, error] {
+	return paginate(ctx, q.offset, q.size, func(ctx context.Context, offset, limit int) ([]Loan, int, error) {
+		page := *q
+		page.offset = offset
+		page.size = limit
+
+		result, err := c.searchLoansContext(ctx, &page)
+		if err != nil {
+			return nil, 0, err
+		}
+		return result.Loans, result.TotalHits, nil
+	})
+}
+
+// IterateCustomerSearch lazily iterates over every customer matching the options, using
+// opts.Size as the page size and starting at opts.From
+func (c *Client) IterateCustomerSearch(ctx context.Context, opts CustomerSearchOptions) iter.Seq2[Customer, error] {
+	return paginate(ctx, opts.From, opts.Size, func(ctx context.Context, offset, limit int) ([]Customer, int, error) {
+		page := opts
+		page.From = offset
+		page.Size = limit
+
+		result, err := c.searchCustomersContext(ctx, &page)
+		if err != nil {
+			return nil, 0, err
+		}
+		return result.Customers, result.TotalHits, nil
+	})
+}
@@ -0,0 +1,277 @@
+package loanpro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// newTransactionsServer serves total transactions, returning at most maxPage per request
+// regardless of $top, and counts the requests it receives
+func newTransactionsServer(t *testing.T, total, maxPage int, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		top, _ := strconv.Atoi(r.URL.Query().Get("$top"))
+		skip, _ := strconv.Atoi(r.URL.Query().Get("$skip"))
+		if top <= 0 || top > maxPage {
+			top = maxPage
+		}
+
+		results := []map[string]any{}
+		for i := skip; i < total && i < skip+top; i++ {
+			results = append(results, map[string]any{"id": i + 1, "title": fmt.Sprintf("Tx %d", i+1)})
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"d": map[string]any{
+				"results": results,
+				"summary": map[string]any{"total": total},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPaginateBoundaries(t *testing.T) {
+	tests := []struct {
+		name         string
+		total        int
+		maxPage      int
+		pageSize     int
+		wantRequests int32
+	}{
+		{"exact multiple", 6, 100, 3, 2},
+		{"partial last page", 7, 100, 3, 3},
+		{"single page", 2, 100, 3, 1},
+		{"empty", 0, 100, 3, 1},
+		{"server caps page size", 7, 2, 5, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := newTransactionsServer(t, tt.total, tt.maxPage, &requests)
+			client := NewClient(server.URL, "key", "tenant")
+
+			var ids []string
+			for tx, err := range client.IterateLoanTransactions(context.Background(), "1", tt.pageSize) {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				ids = append(ids, tx.GetID())
+			}
+
+			if len(ids) != tt.total {
+				t.Errorf("Expected %d transactions, got %d", tt.total, len(ids))
+			}
+			for i, id := range ids {
+				if id != strconv.Itoa(i+1) {
+					t.Errorf("Expected transaction %d to have ID %d, got %s", i, i+1, id)
+				}
+			}
+			if requests.Load() != tt.wantRequests {
+				t.Errorf("Expected %d requests, got %d", tt.wantRequests, requests.Load())
+			}
+		})
+	}
+}
+
+func TestPaginateEarlyBreak(t *testing.T) {
+	var requests atomic.Int32
+	server := newTransactionsServer(t, 100, 100, &requests)
+	client := NewClient(server.URL, "key", "tenant")
+
+	count := 0
+	for _, err := range client.IterateLoanTransactions(context.Background(), "1", 10) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		count++
+		if count == 15 {
+			break
+		}
+	}
+
+	if requests.Load() != 2 {
+		t.Errorf("Expected 2 requests after breaking on item 15, got %d", requests.Load())
+	}
+}
+
+func TestPaginateContextCancel(t *testing.T) {
+	var requests atomic.Int32
+	server := newTransactionsServer(t, 100, 100, &requests)
+	client := NewClient(server.URL, "key", "tenant")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	count := 0
+	var gotErr error
+	for _, err := range client.IterateLoanTransactions(ctx, "1", 10) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		count++
+		if count == 10 {
+			cancel()
+		}
+	}
+
+	if !errors.Is(gotErr, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", gotErr)
+	}
+	if count != 10 {
+		t.Errorf("Expected 10 transactions before cancel, got %d", count)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 request, got %d", requests.Load())
+	}
+}
+
+func TestPaginateUnknownTotal(t *testing.T) {
+	fetched := 0
+	fetch := func(ctx context.Context, offset, limit int) ([]int, int, error) {
+		fetched++
+		var items []int
+		for i := offset; i < 5 && i < offset+limit; i++ {
+			items = append(items, i)
+		}
+		return items, -1, nil
+	}
+
+	var items []int
+	for item, err := range paginate(context.Background(), 0, 2, fetch) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		items = append(items, item)
+	}
+
+	if len(items) != 5 {
+		t.Errorf("Expected 5 items, got %d", len(items))
+	}
+	if fetched != 3 {
+		t.Errorf("Expected 3 fetches, got %d", fetched)
+	}
+}
+
+func TestPaginateUnknownTotalMaxPages(t *testing.T) {
+	// The server ignores the offset and always returns the same full page
+	fetched := 0
+	fetch := func(ctx context.Context, offset, limit int) ([]int, int, error) {
+		fetched++
+		return []int{1, 2}, -1, nil
+	}
+
+	var err error
+	for _, err = range paginate(context.Background(), 0, 2, fetch) {
+		if err != nil {
+			break
+		}
+	}
+
+	if !errors.Is(err, ErrTooManyPages) {
+		t.Errorf("Expected ErrTooManyPages, got %v", err)
+	}
+	if fetched != maxUnknownPages {
+		t.Errorf("Expected %d fetches, got %d", maxUnknownPages, fetched)
+	}
+}
+
+func TestPaginateFetchError(t *testing.T) {
+	fetchErr := errors.New("boom")
+	fetch := func(ctx context.Context, offset, limit int) ([]int, int, error) {
+		if offset > 0 {
+			return nil, 0, fetchErr
+		}
+		return []int{1, 2}, 10, nil
+	}
+
+	count := 0
+	var gotErr error
+	for _, err := range paginate(context.Background(), 0, 2, fetch) {
+		if err != nil {
+			gotErr = err
+			continue
+		}
+		count++
+	}
+
+	if !errors.Is(gotErr, fetchErr) {
+		t.Errorf("Expected fetch error, got %v", gotErr)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 items before the error, got %d", count)
+	}
+}
+
+func TestGetLoanTransactionsCollectsAllPages(t *testing.T) {
+	var requests atomic.Int32
+	server := newTransactionsServer(t, 120, DefaultPageSize, &requests)
+	client := NewClient(server.URL, "key", "tenant")
+
+	transactions, err := client.GetLoanTransactions("1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(transactions) != 120 {
+		t.Errorf("Expected 120 transactions, got %d", len(transactions))
+	}
+	if requests.Load() != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests.Load())
+	}
+
+	result, err := client.GetLoanTransactionsWithMetadata("1", &TransactionOptions{Limit: 10, Offset: 100})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Transactions) != 10 || result.Total != 120 || !result.HasMore {
+		t.Errorf("Expected 10 of 120 with more, got %d of %d (has more: %v)", len(result.Transactions), result.Total, result.HasMore)
+	}
+}
+
+func TestIterateCustomerSearch(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		from, _ := body["from"].(float64)
+		size, _ := body["size"].(float64)
+
+		results := []map[string]any{}
+		for i := int(from); i < 5 && i < int(from+size); i++ {
+			results = append(results, map[string]any{"id": i + 1})
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"d": map[string]any{
+				"results": results,
+				"summary": map[string]any{"totalHits": 5},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", "tenant")
+	count := 0
+	for _, err := range client.IterateCustomerSearch(context.Background(), CustomerSearchOptions{Name: "Smith", Size: 2}) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		count++
+	}
+
+	if count != 5 {
+		t.Errorf("Expected 5 customers, got %d", count)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests.Load())
+	}
+}
@@ -38,24 +38,29 @@ func (l *Loan) GetDaysPastDue() string {
 	return "N/A"
 }
 
+// GetCustomFields returns the loan's custom field values keyed by field name
+func (l *Loan) GetCustomFields() map[string]any {
+	return l.CustomFields
+}
+
 // GetLoanAmount returns the loan amount
 func (l *Loan) GetLoanAmount() string {
 	if l.LoanSetup != nil {
-		return l.LoanSetup.LoanAmount
+		return l.LoanSetup.LoanAmount.String()
 	}
 	return ""
 }
 
 // GetPrincipalBalance returns the principal balance from various sources
 func (l *Loan) GetPrincipalBalance() string {
-	// Try search result field first
-	if l.PrincipalBalance != "" {
-		return l.PrincipalBalance
+	// Try search result field first, showing it as received when it couldn't be parsed
+	if l.PrincipalBalance.IsSet() || !l.PrincipalBalance.IsValid() {
+		return l.PrincipalBalance.String()
 	}
 	// Fallback to StatusArchive for detailed loan view
 	if l.StatusArchive != nil && len(l.StatusArchive.Results) > 0 {
 		latest := l.StatusArchive.Results[len(l.StatusArchive.Results)-1]
-		return latest.PrincipalBalance
+		return latest.PrincipalBalance.String()
 	}
 	return "N/A"
 }
@@ -65,27 +70,27 @@ func (l *Loan) GetPayoffAmount() string {
 	if l.StatusArchive != nil && len(l.StatusArchive.Results) > 0 {
 		// Get the most recent status archive entry (should be sorted by date)
 		latest := l.StatusArchive.Results[len(l.StatusArchive.Results)-1]
-		return latest.Payoff
+		return latest.Payoff.String()
 	}
 	return "N/A"
 }
 
 // GetNextPaymentAmount returns the next payment amount from various sources
 func (l *Loan) GetNextPaymentAmount() string {
-	// Try search result field first
-	if l.NextPaymentAmount != "" {
-		return l.NextPaymentAmount
+	// Try search result field first, showing it as received when it couldn't be parsed
+	if l.NextPaymentAmount.IsSet() || !l.NextPaymentAmount.IsValid() {
+		return l.NextPaymentAmount.String()
 	}
 	// Try StatusArchive next (more current)
 	if l.StatusArchive != nil && len(l.StatusArchive.Results) > 0 {
 		latest := l.StatusArchive.Results[len(l.StatusArchive.Results)-1]
-		if latest.NextPaymentAmount != "" {
-			return latest.NextPaymentAmount
+		if latest.NextPaymentAmount.IsSet() {
+			return latest.NextPaymentAmount.String()
 		}
 	}
 	// Fallback to LoanSetup
 	if l.LoanSetup != nil {
-		return l.LoanSetup.Payment
+		return l.LoanSetup.Payment.String()
 	}
 	return ""
 }
@@ -127,50 +132,50 @@ func (l *Loan) GetPrimaryCustomerName() string {
 	return ""
 }
 
-// GetCreatedDate returns the created date in human-readable format
-func (l *Loan) GetCreatedDate() string {
-	if parsed, err := parseLoanProDateTime(l.Created); err == nil {
-		return parsed
+// GetPortfolioIDs returns the IDs of the portfolios the loan belongs to, from search
+// results or the expanded Portfolios of a detailed view
+func (l *Loan) GetPortfolioIDs() []string {
+	ids := []string{}
+	for _, id := range l.PortfolioIDs {
+		ids = append(ids, string(id))
+	}
+	if l.Portfolios != nil {
+		for _, portfolio := range l.Portfolios.Results {
+			ids = append(ids, portfolio.GetID())
+		}
 	}
-	return l.Created
+	return ids
 }
 
-// GetContractDate returns the contract date in human-readable format
+// GetCreatedDate returns the created timestamp in the tenant's timezone
+func (l *Loan) GetCreatedDate() string {
+	return l.Created.String()
+}
+
+// GetContractDate returns the contract date as YYYY-MM-DD
 func (l *Loan) GetContractDate() string {
 	if l.LoanSetup != nil {
-		if parsed, err := parseLoanProDate(l.LoanSetup.ContractDate); err == nil {
-			return parsed
-		}
-		return l.LoanSetup.ContractDate
+		return l.LoanSetup.ContractDate.String()
 	}
 	return ""
 }
 
-// GetNextPaymentDate returns the next payment date in human-readable format
+// GetNextPaymentDate returns the next payment date as YYYY-MM-DD
 func (l *Loan) GetNextPaymentDate() string {
 	// Try search result field first
-	if l.NextPaymentDate != "" {
-		if parsed, err := parseLoanProDate(l.NextPaymentDate); err == nil {
-			return parsed
-		}
-		return l.NextPaymentDate
+	if l.NextPaymentDate.IsSet() {
+		return l.NextPaymentDate.String()
 	}
 	// Try StatusArchive next (more current)
 	if l.StatusArchive != nil && len(l.StatusArchive.Results) > 0 {
 		latest := l.StatusArchive.Results[len(l.StatusArchive.Results)-1]
-		if latest.NextPaymentDate != "" {
-			if parsed, err := parseLoanProDate(latest.NextPaymentDate); err == nil {
-				return parsed
-			}
-			return latest.NextPaymentDate
+		if latest.NextPaymentDate.IsSet() {
+			return latest.NextPaymentDate.String()
 		}
 	}
 	// Fallback to LoanSetup
 	if l.LoanSetup != nil {
-		if parsed, err := parseLoanProDate(l.LoanSetup.FirstPaymentDate); err == nil {
-			return parsed
-		}
-		return l.LoanSetup.FirstPaymentDate
+		return l.LoanSetup.FirstPaymentDate.String()
 	}
 	return ""
 }
@@ -12,24 +12,24 @@ func TestLoan_GetMethods(t *testing.T) {
 		Title:     "Test Loan",
 		Active:    json.Number("1"),
 		Archived:  json.Number("0"),
-		Created:   "/Date(1427829732)/",
+		Created:   MustParseDateTime("/Date(1427829732)/"),
 		LoanSettings: &LoanSettings{
 			LoanStatusID: json.Number("2"),
 		},
 		LoanSetup: &LoanSetup{
-			LoanAmount:       "25000.00",
-			ContractDate:     "/Date(1427829732)/",
-			FirstPaymentDate: "/Date(1430421732)/",
-			Payment:          "500.00",
+			LoanAmount:       MustParseMoney("25000.00"),
+			ContractDate:     MustParseDate("/Date(1427829732)/"),
+			FirstPaymentDate: MustParseDate("/Date(1430421732)/"),
+			Payment:          MustParseMoney("500.00"),
 		},
 		StatusArchive: &StatusArchiveWrapper{
 			Results: []StatusArchiveEntry{
 				{
-					PrincipalBalance:  "24500.00",
-					Payoff:            "24750.00",
+					PrincipalBalance:  MustParseMoney("24500.00"),
+					Payoff:            MustParseMoney("24750.00"),
 					DaysPastDue:       json.Number("0"),
-					NextPaymentAmount: "500.00",
-					NextPaymentDate:   "/Date(1430421732)/",
+					NextPaymentAmount: MustParseMoney("500.00"),
+					NextPaymentDate:   MustParseDate("/Date(1430421732)/"),
 					LoanStatusText:    "Current",
 				},
 			},
@@ -45,10 +45,10 @@ func TestLoan_GetMethods(t *testing.T) {
 		// Search result fields
 		PrimaryCustomerName: "Jane Smith",
 		LoanStatusText:      "Active",
-		PrincipalBalance:    "23000.00",
+		PrincipalBalance:    MustParseMoney("23000.00"),
 		DaysPastDue:         json.Number("5"),
-		NextPaymentAmount:   "450.00",
-		NextPaymentDate:     "2025-04-15",
+		NextPaymentAmount:   MustParseMoney("450.00"),
+		NextPaymentDate:     MustParseDate("2025-04-15"),
 	}
 
 	// Test GetID
@@ -132,11 +132,11 @@ func TestLoan_FallbackBehavior(t *testing.T) {
 		StatusArchive: &StatusArchiveWrapper{
 			Results: []StatusArchiveEntry{
 				{
-					PrincipalBalance:  "15000.00",
+					PrincipalBalance:  MustParseMoney("15000.00"),
 					DaysPastDue:       json.Number("30"),
 					LoanStatusText:    "Past Due",
-					NextPaymentAmount: "300.00",
-					NextPaymentDate:   "/Date(1430421732)/",
+					NextPaymentAmount: MustParseMoney("300.00"),
+					NextPaymentDate:   MustParseDate("/Date(1430421732)/"),
 				},
 			},
 		},
@@ -0,0 +1,249 @@
+package loanpro
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// ErrInvalidCursor is returned when a search cursor is malformed or was issued for a different query
+var ErrInvalidCursor = errors.New("invalid search cursor")
+
+// Range represents the bounds of a range clause. Nil bounds are omitted.
+type Range struct {
+	GT  any
+	GTE any
+	LT  any
+	LTE any
+}
+
+// LoanSearchQuery builds an Autopal (Elasticsearch-style) loan search request.
+// Clauses added with Match, Term, Range and Exists must all match; the free-text
+// term set with Text only needs to match one of the text fields.
+type LoanSearchQuery struct {
+	text   string
+	must   []map[string]any
+	sort   []map[string]any
+	size   int
+	offset int
+}
+
+// LoanSearchResult contains loans and pagination metadata from a search
+type LoanSearchResult struct {
+	Loans      []Loan
+	TotalHits  int    // Total number of loans matching the query
+	NextCursor string // Opaque cursor for the next page, empty when there are no more results
+}
+
+// NewLoanSearchQuery creates an empty loan search query returning size results per page
+func NewLoanSearchQuery(size int) *LoanSearchQuery {
+	return &LoanSearchQuery{size: size}
+}
+
+// Text matches a free-text term against display ID, primary customer name and title
+func (q *LoanSearchQuery) Text(term string) *LoanSearchQuery {
+	q.text = term
+	return q
+}
+
+// Match adds an analyzed full-text match clause, e.g. for status text fields
+func (q *LoanSearchQuery) Match(field string, value any) *LoanSearchQuery {
+	q.must = append(q.must, map[string]any{
+		"match": map[string]any{field: value},
+	})
+	return q
+}
+
+// Term adds an exact-value term clause, e.g. for IDs
+func (q *LoanSearchQuery) Term(field string, value any) *LoanSearchQuery {
+	q.must = append(q.must, map[string]any{
+		"term": map[string]any{field: value},
+	})
+	return q
+}
+
+// Range adds a range clause. The clause is skipped when no bounds are set.
+func (q *LoanSearchQuery) Range(field string, r Range) *LoanSearchQuery {
+	bounds := map[string]any{}
+	if r.GT != nil {
+		bounds["gt"] = r.GT
+	}
+	if r.GTE != nil {
+		bounds["gte"] = r.GTE
+	}
+	if r.LT != nil {
+		bounds["lt"] = r.LT
+	}
+	if r.LTE != nil {
+		bounds["lte"] = r.LTE
+	}
+	if len(bounds) == 0 {
+		return q
+	}
+
+	q.must = append(q.must, map[string]any{
+		"range": map[string]any{field: bounds},
+	})
+	return q
+}
+
+// Exists adds a clause requiring the field to have a value
+func (q *LoanSearchQuery) Exists(field string) *LoanSearchQuery {
+	q.must = append(q.must, map[string]any{
+		"exists": map[string]any{"field": field},
+	})
+	return q
+}
+
+// Sort adds a sort clause. Sorts are applied in the order they are added.
+func (q *LoanSearchQuery) Sort(field string, descending bool) *LoanSearchQuery {
+	order := "asc"
+	if descending {
+		order = "desc"
+	}
+	q.sort = append(q.sort, map[string]any{
+		field: map[string]any{"order": order},
+	})
+	return q
+}
+
+// Cursor positions the query at the page identified by a cursor from a previous result.
+// An empty cursor starts from the first page.
+func (q *LoanSearchQuery) Cursor(cursor string) error {
+	if cursor == "" {
+		q.offset = 0
+		return nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	var decoded searchCursor
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Offset < 0 {
+		return ErrInvalidCursor
+	}
+	if decoded.Fingerprint != q.fingerprint() {
+		return fmt.Errorf("%w: cursor was issued for a different query", ErrInvalidCursor)
+	}
+
+	q.offset = decoded.Offset
+	return nil
+}
+
+// Body returns the Autopal search request body
+func (q *LoanSearchQuery) Body() map[string]any {
+	body := map[string]any{
+		"query": q.query(),
+		"size":  q.size,
+	}
+	if q.offset > 0 {
+		body["from"] = q.offset
+	}
+	if len(q.sort) > 0 {
+		body["sort"] = q.sort
+	}
+	return body
+}
+
+// query builds the bool query from the text term and filter clauses
+func (q *LoanSearchQuery) query() map[string]any {
+	if q.text == "" && len(q.must) == 0 {
+		// If no filters, use match_all query
+		return map[string]any{
+			"match_all": map[string]any{},
+		}
+	}
+
+	boolQuery := map[string]any{}
+	if len(q.must) > 0 {
+		boolQuery["must"] = q.must
+	}
+	if q.text != "" {
+		boolQuery["should"] = []map[string]any{
+			{
+				"query_string": map[string]any{
+					"query":            "*" + q.text + "*",
+					"fields":           []string{"displayId", "primaryCustomerName", "title"},
+					"default_operator": "and",
+				},
+			},
+			{
+				"match": map[string]any{
+					"displayId": q.text,
+				},
+			},
+			{
+				"match": map[string]any{
+					"primaryCustomerName": q.text,
+				},
+			},
+		}
+		boolQuery["minimum_should_match"] = 1
+	}
+
+	return map[string]any{
+		"bool": boolQuery,
+	}
+}
+
+// searchCursor is the decoded form of an opaque search cursor
+type searchCursor struct {
+	Offset      int    `json:"o"`
+	Fingerprint string `json:"f"`
+}
+
+// fingerprint identifies the query independent of its page position, so a cursor
+// can't be replayed against a different set of filters
+func (q *LoanSearchQuery) fingerprint() string {
+	data, _ := json.Marshal(map[string]any{
+		"query": q.query(),
+		"sort":  q.sort,
+		"size":  q.size,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// nextCursor returns the cursor for the page after one holding count results
+func (q *LoanSearchQuery) nextCursor(count, totalHits int) string {
+	next := q.offset + count
+	if count == 0 || next >= totalHits {
+		return ""
+	}
+
+	data, _ := json.Marshal(searchCursor{Offset: next, Fingerprint: q.fingerprint()})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// SearchLoansWithQuery searches for loans using a query builder and returns pagination metadata
+func (c *Client) SearchLoansWithQuery(q *LoanSearchQuery) (*LoanSearchResult, error) {
+	return c.searchLoansContext(c.requestContext(), q)
+}
+
+// searchLoansContext runs a loan search that is cancelled along with ctx
+func (c *Client) searchLoansContext(ctx context.Context, q *LoanSearchQuery) (*LoanSearchResult, error) {
+	body, err := c.makeRequestWithContext(ctx, "POST", "/public/api/1/Loans/Autopal.Search()", nil, q.Body())
+	if err != nil {
+		return nil, err
+	}
+
+	var response SearchResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		slog.ErrorContext(ctx, "Failed to parse SearchLoans response", "error", err, "body", string(body))
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	c.localizeLoans(response.D.Results)
+
+	return &LoanSearchResult{
+		Loans:      response.D.Results,
+		TotalHits:  response.D.Summary.TotalHits,
+		NextCursor: q.nextCursor(len(response.D.Results), response.D.Summary.TotalHits),
+	}, nil
+}
@@ -0,0 +1,228 @@
+package loanpro
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// marshalBody renders a search body as JSON for comparison (map keys are sorted)
+func marshalBody(t *testing.T, body map[string]any) string {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Failed to marshal search body: %v", err)
+	}
+	return string(data)
+}
+
+func TestLoanSearchQuery_Body(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    *LoanSearchQuery
+		expected string
+	}{
+		{
+			name:     "Match all",
+			query:    NewLoanSearchQuery(10),
+			expected: `{"query":{"match_all":{}},"size":10}`,
+		},
+		{
+			name:     "Term",
+			query:    NewLoanSearchQuery(10).Term("portfolios", "1"),
+			expected: `{"query":{"bool":{"must":[{"term":{"portfolios":"1"}}]}},"size":10}`,
+		},
+		{
+			name:     "Range with both bounds",
+			query:    NewLoanSearchQuery(10).Range("principalBalance", Range{GTE: 1000.0, LTE: 5000.0}),
+			expected: `{"query":{"bool":{"must":[{"range":{"principalBalance":{"gte":1000,"lte":5000}}}]}},"size":10}`,
+		},
+		{
+			name:     "Exclusive range",
+			query:    NewLoanSearchQuery(10).Range("daysPastDue", Range{GT: 30, LT: 60}),
+			expected: `{"query":{"bool":{"must":[{"range":{"daysPastDue":{"gt":30,"lt":60}}}]}},"size":10}`,
+		},
+		{
+			name:     "Empty range is skipped",
+			query:    NewLoanSearchQuery(10).Range("principalBalance", Range{}),
+			expected: `{"query":{"match_all":{}},"size":10}`,
+		},
+		{
+			name:     "Exists",
+			query:    NewLoanSearchQuery(10).Exists("nextPaymentDate"),
+			expected: `{"query":{"bool":{"must":[{"exists":{"field":"nextPaymentDate"}}]}},"size":10}`,
+		},
+		{
+			name:     "Multiple sorts",
+			query:    NewLoanSearchQuery(5).Sort("daysPastDue", true).Sort("displayId", false),
+			expected: `{"query":{"match_all":{}},"size":5,"sort":[{"daysPastDue":{"order":"desc"}},{"displayId":{"order":"asc"}}]}`,
+		},
+		{
+			name:  "Text with filter",
+			query: NewLoanSearchQuery(10).Text("Doe").Match("loanStatusText", "Open"),
+			expected: `{"query":{"bool":{"minimum_should_match":1,"must":[{"match":{"loanStatusText":"Open"}}],"should":[` +
+				`{"query_string":{"default_operator":"and","fields":["displayId","primaryCustomerName","title"],"query":"*Doe*"}},` +
+				`{"match":{"displayId":"Doe"}},{"match":{"primaryCustomerName":"Doe"}}]}},"size":10}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := marshalBody(t, tt.query.Body())
+			if result != tt.expected {
+				t.Errorf("Unexpected search body:\n got: %s\nwant: %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoanSearchOptions_Query(t *testing.T) {
+	minBalance := 1000.0
+	maxBalance := 5000.0
+	minDPD := 30
+
+	opts := &LoanSearchOptions{
+		Status:         "Open",
+		SubStatus:      "Collections",
+		PortfolioID:    "1",
+		SubPortfolioID: "10",
+		MinBalance:     &minBalance,
+		MaxBalance:     &maxBalance,
+		MinDaysPastDue: &minDPD,
+		CreatedAfter:   "2025-01-01",
+		SortBy:         "principalBalance",
+		SortDescending: true,
+		Limit:          25,
+	}
+
+	q, err := opts.Query()
+	if err != nil {
+		t.Fatalf("Failed to build query: %v", err)
+	}
+
+	expected := `{"query":{"bool":{"must":[` +
+		`{"match":{"loanStatusText":"Open"}},` +
+		`{"match":{"loanSubStatusText":"Collections"}},` +
+		`{"term":{"portfolios":"1"}},` +
+		`{"term":{"subPortfolios":"10"}},` +
+		`{"range":{"principalBalance":{"gte":1000,"lte":5000}}},` +
+		`{"range":{"daysPastDue":{"gte":30}}},` +
+		`{"range":{"created":{"gte":"2025-01-01"}}}]}},` +
+		`"size":25,"sort":[{"principalBalance":{"order":"desc"}}]}`
+
+	if result := marshalBody(t, q.Body()); result != expected {
+		t.Errorf("Unexpected search body:\n got: %s\nwant: %s", result, expected)
+	}
+}
+
+func TestLoanSearchOptions_PortfolioFilters(t *testing.T) {
+	q, err := (&LoanSearchOptions{
+		Status:         "Open",
+		PortfolioID:    "1",
+		SubPortfolioID: "10",
+		Limit:          25,
+	}).Query()
+	if err != nil {
+		t.Fatalf("Failed to build query: %v", err)
+	}
+
+	expected := `{"query":{"bool":{"must":[{"match":{"loanStatusText":"Open"}},{"term":{"portfolios":"1"}},{"term":{"subPortfolios":"10"}}]}},"size":25}`
+	if result := marshalBody(t, q.Body()); result != expected {
+		t.Errorf("Unexpected search body:\n got: %s\nwant: %s", result, expected)
+	}
+}
+
+func TestLoanSearchOptions_MatchAll(t *testing.T) {
+	q, err := (&LoanSearchOptions{Limit: 10}).Query()
+	if err != nil {
+		t.Fatalf("Failed to build query: %v", err)
+	}
+
+	expected := `{"query":{"match_all":{}},"size":10}`
+	if result := marshalBody(t, q.Body()); result != expected {
+		t.Errorf("Unexpected search body:\n got: %s\nwant: %s", result, expected)
+	}
+}
+
+func TestLoanSearchQuery_Cursor(t *testing.T) {
+	q := NewLoanSearchQuery(10).Match("loanStatusText", "Open")
+
+	// No cursor when the first page holds every hit
+	if cursor := q.nextCursor(8, 8); cursor != "" {
+		t.Errorf("Expected no cursor on last page, got %s", cursor)
+	}
+
+	cursor := q.nextCursor(10, 25)
+	if cursor == "" {
+		t.Fatal("Expected a cursor when more results are available")
+	}
+
+	// The same query resumes at the next page
+	next := NewLoanSearchQuery(10).Match("loanStatusText", "Open")
+	if err := next.Cursor(cursor); err != nil {
+		t.Fatalf("Expected cursor to be accepted, got %v", err)
+	}
+	if body := next.Body(); body["from"] != 10 {
+		t.Errorf("Expected from=10, got %v", body["from"])
+	}
+
+	// Cursors are chained from the current position
+	last := next.nextCursor(10, 25)
+	final := NewLoanSearchQuery(10).Match("loanStatusText", "Open")
+	if err := final.Cursor(last); err != nil {
+		t.Fatalf("Expected chained cursor to be accepted, got %v", err)
+	}
+	if body := final.Body(); body["from"] != 20 {
+		t.Errorf("Expected from=20, got %v", body["from"])
+	}
+	if cursor := final.nextCursor(5, 25); cursor != "" {
+		t.Errorf("Expected no cursor past the last hit, got %s", cursor)
+	}
+
+	// A cursor can't be replayed against different filters
+	other := NewLoanSearchQuery(10).Match("loanStatusText", "Closed")
+	if err := other.Cursor(cursor); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor for a different query, got %v", err)
+	}
+
+	// Garbage is rejected
+	if err := NewLoanSearchQuery(10).Cursor("not-a-cursor!"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor for malformed cursor, got %v", err)
+	}
+}
+
+func TestSearchLoansWithQuery(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/public/api/1/Loans/Autopal.Search()" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &requestBody)
+		w.Write([]byte(`{"d":{"results":[{"id":1,"displayId":"LN1"},{"id":2,"displayId":"LN2"}],"summary":{"totalHits":5,"totalTime":3}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", "tenant")
+
+	result, err := client.SearchLoansWithQuery(NewLoanSearchQuery(2).Term("portfolios", "1"))
+	if err != nil {
+		t.Fatalf("SearchLoansWithQuery failed: %v", err)
+	}
+
+	if len(result.Loans) != 2 {
+		t.Errorf("Expected 2 loans, got %d", len(result.Loans))
+	}
+	if result.TotalHits != 5 {
+		t.Errorf("Expected 5 total hits, got %d", result.TotalHits)
+	}
+	if result.NextCursor == "" {
+		t.Error("Expected a next cursor")
+	}
+	if requestBody["size"] != float64(2) {
+		t.Errorf("Expected size 2 in request, got %v", requestBody["size"])
+	}
+}
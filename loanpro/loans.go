@@ -3,122 +3,146 @@ package loanpro
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 )
 
 // GetLoan retrieves a loan by ID with expanded data
 func (c *Client) GetLoan(loanID string) (*Loan, error) {
-	// Use OData expand to include related data that provides loan amounts, status, and customer info
-	params := map[string]string{
-		"$expand": "LoanSettings,LoanSetup,Customers,StatusArchive",
-	}
+	// Use OData expand to include related data that provides loan amounts, status, customer info, portfolios and custom fields
+	query := NewODataQuery("Loans").Key(loanID).
+		Expand("LoanSettings", "LoanSetup", "Customers", "StatusArchive", "Portfolios", "CustomFieldValues")
 
-	body, err := c.makeRequest("/public/api/1/odata.svc/Loans("+loanID+")", params)
+	body, err := c.getOData(query)
 	if err != nil {
 		return nil, err
 	}
 
 	var response ODataResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		fmt.Fprintf(os.Stderr, "[ERROR] Failed to parse GetLoan response: %v\nResponse body: %s\n", err, string(body))
+		slog.ErrorContext(c.requestContext(), "Failed to parse GetLoan response", "error", err, "body", string(body))
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	loanData, err := json.Marshal(response.D)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[ERROR] Failed to marshal loan data: %v\n", err)
+		slog.ErrorContext(c.requestContext(), "Failed to marshal loan data", "error", err)
 		return nil, fmt.Errorf("failed to marshal loan data: %w", err)
 	}
 
 	var loan Loan
 	if err := json.Unmarshal(loanData, &loan); err != nil {
-		fmt.Fprintf(os.Stderr, "[ERROR] Failed to parse loan struct: %v\nLoan data: %s\n", err, string(loanData))
+		slog.ErrorContext(c.requestContext(), "Failed to parse loan struct", "error", err, "data", string(loanData))
 		return nil, fmt.Errorf("failed to parse loan: %w", err)
 	}
 
+	loan.CustomFields = c.resolveCustomFields(loan.CustomFieldValues)
+	loan.Created = loan.Created.In(c.location)
+
 	return &loan, nil
 }
 
+// LoanSearchOptions contains search term, filter, sort and pagination options for loan searches
+type LoanSearchOptions struct {
+	SearchTerm     string   // Free-text term matched against display ID, customer name and title
+	Status         string   // Loan status text filter
+	SubStatus      string   // Loan sub-status text filter
+	PortfolioID    string   // Portfolio ID filter
+	SubPortfolioID string   // Sub-portfolio ID filter
+	MinBalance     *float64 // Minimum principal balance (inclusive)
+	MaxBalance     *float64 // Maximum principal balance (inclusive)
+	MinDaysPastDue *int     // Minimum days past due (inclusive)
+	MaxDaysPastDue *int     // Maximum days past due (inclusive)
+	CreatedAfter   string   // Only loans created on or after this date (YYYY-MM-DD)
+	CreatedBefore  string   // Only loans created on or before this date (YYYY-MM-DD)
+	SortBy         string   // Field to sort by
+	SortDescending bool     // Sort in descending order
+	Limit          int      // Maximum number of results per page
+	Cursor         string   // Cursor from a previous result to fetch the next page
+}
+
+// Query builds a LoanSearchQuery from the options
+func (o *LoanSearchOptions) Query() (*LoanSearchQuery, error) {
+	q := NewLoanSearchQuery(o.Limit).Text(o.SearchTerm)
+
+	if o.Status != "" {
+		q.Match("loanStatusText", o.Status)
+	}
+	if o.SubStatus != "" {
+		q.Match("loanSubStatusText", o.SubStatus)
+	}
+	if o.PortfolioID != "" {
+		q.Term("portfolios", o.PortfolioID)
+	}
+	if o.SubPortfolioID != "" {
+		q.Term("subPortfolios", o.SubPortfolioID)
+	}
+
+	balance := Range{}
+	if o.MinBalance != nil {
+		balance.GTE = *o.MinBalance
+	}
+	if o.MaxBalance != nil {
+		balance.LTE = *o.MaxBalance
+	}
+	q.Range("principalBalance", balance)
+
+	daysPastDue := Range{}
+	if o.MinDaysPastDue != nil {
+		daysPastDue.GTE = *o.MinDaysPastDue
+	}
+	if o.MaxDaysPastDue != nil {
+		daysPastDue.LTE = *o.MaxDaysPastDue
+	}
+	q.Range("daysPastDue", daysPastDue)
+
+	created := Range{}
+	if o.CreatedAfter != "" {
+		created.GTE = o.CreatedAfter
+	}
+	if o.CreatedBefore != "" {
+		created.LTE = o.CreatedBefore
+	}
+	q.Range("created", created)
+
+	if o.SortBy != "" {
+		q.Sort(o.SortBy, o.SortDescending)
+	}
+
+	if err := q.Cursor(o.Cursor); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
 // SearchLoans searches for loans using the search API
 func (c *Client) SearchLoans(searchTerm, status string, limit int) ([]Loan, error) {
-	// Build the search query according to LoanPro API format
-	searchBody := map[string]any{
-		"size": limit, // Use 'size' for pagination limit
-	}
-
-	// Build query conditions
-	var mustConditions []map[string]any
-	var shouldConditions []map[string]any
-
-	// Add search term conditions if provided
-	if searchTerm != "" {
-		shouldConditions = append(shouldConditions,
-			map[string]any{
-				"query_string": map[string]any{
-					"query":            "*" + searchTerm + "*",
-					"fields":           []string{"displayId", "primaryCustomerName", "title"},
-					"default_operator": "and",
-				},
-			},
-			map[string]any{
-				"match": map[string]any{
-					"displayId": searchTerm,
-				},
-			},
-			map[string]any{
-				"match": map[string]any{
-					"primaryCustomerName": searchTerm,
-				},
-			},
-		)
-	}
-
-	// Add status filter if provided
-	if status != "" {
-		mustConditions = append(mustConditions, map[string]any{
-			"match": map[string]any{
-				"loanStatusText": status,
-			},
-		})
-	}
-
-	// Build the final query
-	if len(mustConditions) > 0 || len(shouldConditions) > 0 {
-		boolQuery := map[string]any{}
-
-		if len(mustConditions) > 0 {
-			if len(mustConditions) == 1 {
-				boolQuery["must"] = mustConditions[0]
-			} else {
-				boolQuery["must"] = mustConditions
-			}
-		}
-
-		if len(shouldConditions) > 0 {
-			boolQuery["should"] = shouldConditions
-			boolQuery["minimum_should_match"] = 1
-		}
-
-		searchBody["query"] = map[string]any{
-			"bool": boolQuery,
-		}
-	} else {
-		// If no filters, use match_all query
-		searchBody["query"] = map[string]any{
-			"match_all": map[string]any{},
-		}
-	}
-
-	body, err := c.makePostRequest("/public/api/1/Loans/Autopal.Search()", searchBody)
+	return c.SearchLoansWithOptions(&LoanSearchOptions{
+		SearchTerm: searchTerm,
+		Status:     status,
+		Limit:      limit,
+	})
+}
+
+// SearchLoansWithOptions searches for loans using the search API with filter options
+func (c *Client) SearchLoansWithOptions(opts *LoanSearchOptions) ([]Loan, error) {
+	result, err := c.SearchLoansWithMetadata(opts)
 	if err != nil {
 		return nil, err
 	}
+	return result.Loans, nil
+}
 
-	var response SearchResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		fmt.Fprintf(os.Stderr, "[ERROR] Failed to parse SearchLoans response: %v\nResponse body: %s\n", err, string(body))
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+// SearchLoansWithMetadata searches for loans with filter options and returns pagination metadata
+func (c *Client) SearchLoansWithMetadata(opts *LoanSearchOptions) (*LoanSearchResult, error) {
+	if opts == nil {
+		opts = &LoanSearchOptions{}
+	}
+
+	q, err := opts.Query()
+	if err != nil {
+		return nil, err
 	}
 
-	return response.D.Results, nil
+	return c.SearchLoansWithQuery(q)
 }
@@ -0,0 +1,73 @@
+package loanpro
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"loanpro-mcp-server/metrics"
+)
+
+var (
+	apiRequests = metrics.Default.NewCounter("loanpro_api_requests_total",
+		"LoanPro API requests by endpoint, method and HTTP status, or \"error\" when no response was received.",
+		"endpoint", "method", "status")
+	apiRequestDuration = metrics.Default.NewHistogram("loanpro_api_request_duration_seconds",
+		"LoanPro API request latency by endpoint.", metrics.DefaultBuckets, "endpoint")
+	apiRetries = metrics.Default.NewCounter("loanpro_api_retries_total",
+		"LoanPro API requests retried, by endpoint and reason.", "endpoint", "reason")
+	cacheLookups = metrics.Default.NewCounter("loanpro_cache_lookups_total",
+		"Response cache lookups by resource and result (hit or miss).", "resource", "result")
+)
+
+// observeRequest records a LoanPro API request. status is the HTTP status code, or
+// 0 when no response was received.
+func observeRequest(endpoint, method string, status int, duration time.Duration) {
+	label := "error"
+	if status > 0 {
+		label = strconv.Itoa(status)
+	}
+	apiRequests.Inc(endpoint, method, label)
+	apiRequestDuration.Observe(duration.Seconds(), endpoint)
+}
+
+// knownEndpoints are the endpoint labels the client requests. Any other path is
+// labelled "other", so label values stay a fixed set.
+var knownEndpoints = map[string]bool{
+	"Loans":                    true,
+	"Loans/Transactions":       true,
+	"Loans/Payments":           true,
+	"Loans/Autopal.Search":     true,
+	"Customers":                true,
+	"Customers/Autopal.Search": true,
+	"Portfolios":               true,
+	"CustomFields":             true,
+}
+
+// endpointLabel reduces an escaped request path to its entity sets and navigation
+// properties, e.g. "Loans/Transactions" for /public/api/1/odata.svc/Loans(102)/Transactions,
+// so no IDs end up in metric labels. Keys are escaped, so a key holding a slash
+// stays inside its parentheses. Paths that aren't a known endpoint are "other".
+func endpointLabel(path string) string {
+	if i := strings.Index(path, odataBasePath); i >= 0 {
+		path = path[i+len(odataBasePath):]
+	} else if i := strings.Index(path, "/public/api/1/"); i >= 0 {
+		path = path[i+len("/public/api/1/"):]
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if i := strings.Index(segment, "("); i >= 0 {
+			segment = segment[:i]
+		}
+		if segment == "" {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	label := strings.Join(segments, "/")
+	if !knownEndpoints[label] {
+		return "other"
+	}
+	return label
+}
@@ -0,0 +1,64 @@
+package loanpro
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"loanpro-mcp-server/metrics"
+)
+
+func TestEndpointLabel(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/api/public/api/1/odata.svc/Loans(102)", "Loans"},
+		{"/public/api/1/odata.svc/Loans(102)/Transactions", "Loans/Transactions"},
+		{"/public/api/1/odata.svc/Customers(id=5)", "Customers"},
+		{"/public/api/1/odata.svc/Portfolios", "Portfolios"},
+		{"/public/api/1/Loans/Autopal.Search()", "Loans/Autopal.Search"},
+		{"/public/api/1/Customers/Autopal.Search()", "Customers/Autopal.Search"},
+		{"/public/api/1/odata.svc/Loans(%271%2Fabc%2Fdef%27)/Payments", "Loans/Payments"},
+		{"/public/api/1/Loans/102/Autopal.GetStatus()", "other"},
+		{"/public/api/1/odata.svc/Loans(1)/abc/def", "other"},
+		{"/", "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if label := endpointLabel(tt.path); label != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, label)
+			}
+		})
+	}
+}
+
+func TestClient_EndpointLabelsBounded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "key", "tenant")
+
+	// IDs with slashes must not become path segments, and so label values
+	for i := range 20 {
+		client.GetLoan(fmt.Sprintf("%d/abc%d/def", i, i))
+		client.GetLoanTransactions(fmt.Sprintf("x/%d", i))
+	}
+
+	w := httptest.NewRecorder()
+	metrics.Default.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if !strings.HasPrefix(line, "loanpro_api_requests_total{") {
+			continue
+		}
+		start := strings.Index(line, `endpoint="`) + len(`endpoint="`)
+		endpoint := line[start : start+strings.Index(line[start:], `"`)]
+		if endpoint != "other" && !knownEndpoints[endpoint] {
+			t.Errorf("Expected a known endpoint label, got %q", endpoint)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package loanpro
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// moneyScale is the number of decimal places Money holds exactly. LoanPro reports
+// currency with two places and average daily balances with up to six.
+const moneyScale = 6
+
+// moneyUnit is the number of stored units in one whole currency unit
+const moneyUnit = 1_000_000
+
+// ErrInvalidMoney is returned when an amount can't be parsed, doesn't fit in Money,
+// or is used in arithmetic after failing to parse
+var ErrInvalidMoney = errors.New("invalid money amount")
+
+// Money is an exact decimal currency amount. Arithmetic and comparisons are done on
+// integer micro-units, so totals never pick up floating point error.
+//
+// The zero Money is unset: it compares equal to zero, but IsSet reports false and it
+// formats as an empty string, matching how LoanPro omits amounts it doesn't have.
+// An amount received in JSON that can't be parsed is also unset, but is invalid and
+// keeps the value received.
+type Money struct {
+	micros int64
+	set    bool
+	raw    string // Value received when it couldn't be parsed
+}
+
+// NewMoneyFromCents creates an amount from a whole number of cents
+func NewMoneyFromCents(cents int64) Money {
+	return Money{micros: cents * (moneyUnit / 100), set: true}
+}
+
+// ParseMoney parses a decimal amount such as "1234.5", "-0.00" or "1,234.50".
+// Places beyond the sixth are rounded half to even. An empty string yields an
+// unset amount.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Money{}, nil
+	}
+
+	raw := s
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+	s = strings.ReplaceAll(s, ",", "")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, raw)
+	}
+	if !allDigits(whole) || !allDigits(frac) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, raw)
+	}
+	var dropped string
+	if len(frac) > moneyScale {
+		frac, dropped = frac[:moneyScale], frac[moneyScale:]
+	}
+
+	digits := whole + frac + strings.Repeat("0", moneyScale-len(frac))
+	micros, err := strconv.ParseInt(digits, 10, 64)
+	if err == nil && roundsUp(dropped, micros) {
+		if micros == math.MaxInt64 {
+			err = strconv.ErrRange
+		}
+		micros++
+	}
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q is out of range", ErrInvalidMoney, raw)
+	}
+	if negative {
+		micros = -micros
+	}
+	return Money{micros: micros, set: true}, nil
+}
+
+// MustParseMoney is like ParseMoney but panics on invalid input. It is intended
+// for constants and tests.
+func MustParseMoney(s string) Money {
+	m, err := ParseMoney(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// roundsUp reports whether digits dropped past the last kept place round the
+// magnitude kept up, rounding half to even
+func roundsUp(dropped string, kept int64) bool {
+	if dropped == "" || dropped[0] < '5' {
+		return false
+	}
+	if dropped[0] > '5' || strings.TrimRight(dropped[1:], "0") != "" {
+		return true
+	}
+	return kept%2 == 1
+}
+
+// allDigits reports whether s contains only ASCII digits
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSet reports whether the amount was present and valid
+func (m Money) IsSet() bool {
+	return m.set
+}
+
+// IsValid reports whether the amount is set or unset, rather than received but
+// unparseable
+func (m Money) IsValid() bool {
+	return m.raw == ""
+}
+
+// IsZero reports whether the amount is zero or unset
+func (m Money) IsZero() bool {
+	return m.micros == 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the amount
+func (m Money) Sign() int {
+	switch {
+	case m.micros < 0:
+		return -1
+	case m.micros > 0:
+		return 1
+	}
+	return 0
+}
+
+// Add returns m + other. Adding an invalid amount, or a sum out of Money's range,
+// returns ErrInvalidMoney.
+func (m Money) Add(other Money) (Money, error) {
+	if !m.IsValid() || !other.IsValid() {
+		return Money{}, fmt.Errorf("%w: can't add %q and %q", ErrInvalidMoney, m.String(), other.String())
+	}
+	sum := m.micros + other.micros
+	overflow := (other.micros > 0 && sum < m.micros) || (other.micros < 0 && sum > m.micros)
+	if overflow || sum == math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: sum of %s and %s is out of range", ErrInvalidMoney, m, other)
+	}
+	return Money{micros: sum, set: m.set || other.set}, nil
+}
+
+// Sub returns m - other, with the same errors as Add
+func (m Money) Sub(other Money) (Money, error) {
+	return m.Add(other.Neg())
+}
+
+// Neg returns -m. An invalid amount stays invalid.
+func (m Money) Neg() Money {
+	return Money{micros: -m.micros, set: m.set, raw: m.raw}
+}
+
+// Abs returns the absolute value of m
+func (m Money) Abs() Money {
+	if m.micros < 0 {
+		return m.Neg()
+	}
+	return m
+}
+
+// Cmp compares m and other, returning -1, 0 or +1. Unset amounts compare as zero.
+func (m Money) Cmp(other Money) int {
+	switch {
+	case m.micros < other.micros:
+		return -1
+	case m.micros > other.micros:
+		return 1
+	}
+	return 0
+}
+
+// Equal reports whether m and other are the same amount
+func (m Money) Equal(other Money) bool {
+	return m.micros == other.micros
+}
+
+// SumMoney totals a list of amounts. The result is set even when the list is empty.
+// It returns ErrInvalidMoney like Add.
+func SumMoney(amounts ...Money) (Money, error) {
+	total := Money{set: true}
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// String formats the amount with at least two decimal places, e.g. "1234.50" or
+// "-0.125". Unset amounts format as an empty string, and invalid ones as the value
+// received.
+func (m Money) String() string {
+	if !m.set {
+		return m.raw
+	}
+
+	micros := m.micros
+	sign := ""
+	if micros < 0 {
+		sign = "-"
+	}
+
+	whole, frac := splitMicros(micros)
+	fracStr := fmt.Sprintf("%0*d", moneyScale, frac)
+	fracStr = strings.TrimRight(fracStr, "0")
+	for len(fracStr) < 2 {
+		fracStr += "0"
+	}
+	return sign + strconv.FormatUint(whole, 10) + "." + fracStr
+}
+
+// splitMicros splits the magnitude of an amount into whole units and micro-units
+func splitMicros(micros int64) (uint64, uint64) {
+	magnitude := uint64(micros)
+	if micros < 0 {
+		magnitude = -magnitude
+	}
+	return magnitude / moneyUnit, magnitude % moneyUnit
+}
+
+// Float64 returns the amount as a float64, for display or APIs that need one.
+// Do not use the result for further arithmetic.
+func (m Money) Float64() float64 {
+	return float64(m.micros) / moneyUnit
+}
+
+// UnmarshalJSON accepts LoanPro amounts sent as strings or numbers. null and ""
+// leave the amount unset. An amount that can't be parsed or is out of range also
+// leaves it unset, but invalid and keeping its text, so one bad amount doesn't
+// fail the whole entity.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*m = Money{}
+		return nil
+	}
+
+	parsed, err := unmarshalMoney(data)
+	if err != nil {
+		*m = Money{raw: rawDate(data)}
+		return nil
+	}
+	*m = parsed
+	return nil
+}
+
+// unmarshalMoney parses a JSON string or number amount
+func unmarshalMoney(data []byte) (Money, error) {
+	var s string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return Money{}, fmt.Errorf("%w: %v", ErrInvalidMoney, err)
+		}
+	} else {
+		s = string(data)
+		if strings.ContainsAny(s, "eE") {
+			// Numbers in exponent form are normalized through the exact decimal formatter
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return Money{}, fmt.Errorf("%w: %s", ErrInvalidMoney, s)
+			}
+			s = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+	return ParseMoney(s)
+}
+
+// MarshalJSON encodes the amount as a decimal string, null when unset, or the value
+// received when invalid
+func (m Money) MarshalJSON() ([]byte, error) {
+	if !m.set {
+		if m.raw != "" {
+			return json.Marshal(m.raw)
+		}
+		return []byte("null"), nil
+	}
+	return json.Marshal(m.String())
+}
@@ -0,0 +1,219 @@
+package loanpro
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		hasError bool
+	}{
+		{name: "Two decimals", input: "1234.50", expected: "1234.50"},
+		{name: "Whole number", input: "75000", expected: "75000.00"},
+		{name: "One decimal", input: "12.5", expected: "12.50"},
+		{name: "Extra zero decimals", input: "0.000", expected: "0.00"},
+		{name: "Negative zero", input: "-0.00", expected: "0.00"},
+		{name: "Negative", input: "-86.69", expected: "-86.69"},
+		{name: "Leading plus", input: "+5", expected: "5.00"},
+		{name: "Thousands separators", input: "1,234.50", expected: "1234.50"},
+		{name: "Six decimals", input: "0.123456", expected: "0.123456"},
+		{name: "Trailing zeros past scale", input: "1.50000000", expected: "1.50"},
+		{name: "Leading decimal point", input: ".5", expected: "0.50"},
+		{name: "Empty", input: "", expected: ""},
+		{name: "Seven decimals round down", input: "0.1234564", expected: "0.123456"},
+		{name: "Seven decimals round up", input: "0.1234566", expected: "0.123457"},
+		{name: "Half rounds to even down", input: "0.1234565", expected: "0.123456"},
+		{name: "Half rounds to even up", input: "0.1234575", expected: "0.123458"},
+		{name: "Past half rounds up", input: "0.12345650001", expected: "0.123457"},
+		{name: "Rounding carries", input: "-0.9999999", expected: "-1.00"},
+		{name: "Not a number", input: "abc", hasError: true},
+		{name: "Sign only", input: "-", hasError: true},
+		{name: "Double point", input: "1.2.3", hasError: true},
+		{name: "Out of range", input: "99999999999999999999", hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseMoney(tt.input)
+
+			if tt.hasError {
+				if !errors.Is(err, ErrInvalidMoney) {
+					t.Errorf("Expected ErrInvalidMoney, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if result.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	// 0.1 + 0.2 is the classic float failure
+	sum, err := MustParseMoney("0.1").Add(MustParseMoney("0.2"))
+	if err != nil || !sum.Equal(MustParseMoney("0.3")) {
+		t.Errorf("Expected 0.30, got %s (%v)", sum, err)
+	}
+
+	amounts := make([]Money, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		amounts = append(amounts, MustParseMoney("0.01"))
+	}
+	if total, err := SumMoney(amounts...); err != nil || total.String() != "10.00" {
+		t.Errorf("Expected 10.00, got %s (%v)", total, err)
+	}
+
+	if total, err := SumMoney(); err != nil || !total.IsSet() || total.String() != "0.00" {
+		t.Errorf("Expected empty sum to be 0.00, got %q (%v)", total.String(), err)
+	}
+
+	diff, err := MustParseMoney("100").Sub(MustParseMoney("100.01"))
+	if err != nil || diff.String() != "-0.01" || diff.Sign() != -1 {
+		t.Errorf("Expected -0.01, got %s (%v)", diff, err)
+	}
+	if diff.Abs().String() != "0.01" {
+		t.Errorf("Expected 0.01, got %s", diff.Abs())
+	}
+	if NewMoneyFromCents(12345).String() != "123.45" {
+		t.Errorf("Expected 123.45, got %s", NewMoneyFromCents(12345))
+	}
+}
+
+func TestMoneyArithmeticErrors(t *testing.T) {
+	largest := MustParseMoney("9223372036854.775807")
+	var invalid Money
+	json.Unmarshal([]byte(`"12abc"`), &invalid)
+
+	tests := []struct {
+		name string
+		op   func() (Money, error)
+	}{
+		{"Overflow", func() (Money, error) { return largest.Add(MustParseMoney("0.000001")) }},
+		{"Negative overflow", func() (Money, error) { return largest.Neg().Sub(MustParseMoney("1")) }},
+		{"Invalid operand", func() (Money, error) { return MustParseMoney("1").Add(invalid) }},
+		{"Invalid in sum", func() (Money, error) { return SumMoney(MustParseMoney("1"), invalid) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.op(); !errors.Is(err, ErrInvalidMoney) {
+				t.Errorf("Expected ErrInvalidMoney, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMoneyCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.00", "1", 0},
+		{"0.000", "-0.00", 0},
+		{"1.01", "1.1", -1},
+		{"-5", "-6", 1},
+		{"", "0", 0},
+	}
+
+	for _, tt := range tests {
+		if got := MustParseMoney(tt.a).Cmp(MustParseMoney(tt.b)); got != tt.expected {
+			t.Errorf("Expected Cmp(%q, %q) = %d, got %d", tt.a, tt.b, tt.expected, got)
+		}
+	}
+
+	unset := Money{}
+	if unset.IsSet() || !unset.IsZero() || unset.String() != "" {
+		t.Errorf("Expected zero Money to be unset and zero, got set=%v string=%q", unset.IsSet(), unset.String())
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var decoded struct {
+		String  Money `json:"string"`
+		Number  Money `json:"number"`
+		Integer Money `json:"integer"`
+		Null    Money `json:"null"`
+		Empty   Money `json:"empty"`
+		Missing Money `json:"missing"`
+	}
+	data := `{"string": "86.69", "number": 111.98, "integer": 0, "null": null, "empty": ""}`
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if decoded.String.String() != "86.69" {
+		t.Errorf("Expected 86.69, got %s", decoded.String)
+	}
+	if decoded.Number.String() != "111.98" {
+		t.Errorf("Expected 111.98, got %s", decoded.Number)
+	}
+	if !decoded.Integer.IsSet() || decoded.Integer.String() != "0.00" {
+		t.Errorf("Expected set 0.00, got %q", decoded.Integer.String())
+	}
+	for name, m := range map[string]Money{"null": decoded.Null, "empty": decoded.Empty, "missing": decoded.Missing} {
+		if m.IsSet() {
+			t.Errorf("Expected %s amount to be unset", name)
+		}
+	}
+
+	encoded, err := json.Marshal(map[string]Money{"set": MustParseMoney("5"), "unset": {}})
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if string(encoded) != `{"set":"5.00","unset":null}` {
+		t.Errorf("Unexpected JSON: %s", encoded)
+	}
+
+	// Malformed and out of range amounts are kept as received instead of failing
+	for _, input := range []string{`"12abc"`, `99999999999999999999`} {
+		var invalid Money
+		if err := json.Unmarshal([]byte(input), &invalid); err != nil {
+			t.Fatalf("Expected no error for %s, got %v", input, err)
+		}
+		if invalid.IsSet() || invalid.IsValid() || invalid.String() != strings.Trim(input, `"`) {
+			t.Errorf("Expected %s to be kept as invalid, got set=%v valid=%v string=%q", input, invalid.IsSet(), invalid.IsValid(), invalid.String())
+		}
+		if encoded, _ := json.Marshal(invalid); string(encoded) != `"`+strings.Trim(input, `"`)+`"` {
+			t.Errorf("Expected %s to be encoded as received, got %s", input, encoded)
+		}
+	}
+}
+
+func TestTransactionUnmarshal_MalformedAmount(t *testing.T) {
+	var tx Transaction
+	data := `{"id": 1, "paymentAmount": "12abc", "chargeAmount": "5.00"}`
+	if err := json.Unmarshal([]byte(data), &tx); err != nil {
+		t.Fatalf("Expected one bad amount not to fail the transaction, got %v", err)
+	}
+	if tx.PaymentAmount.String() != "12abc" || tx.ChargeAmount.String() != "5.00" {
+		t.Errorf("Expected the bad amount kept and the others parsed, got %q and %q", tx.PaymentAmount, tx.ChargeAmount)
+	}
+}
+
+func TestTransactionBreakdownZeroForms(t *testing.T) {
+	tx := Transaction{
+		PaymentPrincipal: MustParseMoney("0.000"),
+		PaymentInterest:  MustParseMoney("-0.00"),
+		ChargeFees:       MustParseMoney("0.000"),
+	}
+
+	if tx.HasPaymentBreakdown() {
+		t.Error("Expected HasPaymentBreakdown to be false for 0.000 and -0.00")
+	}
+	if tx.HasChargeBreakdown() {
+		t.Error("Expected HasChargeBreakdown to be false for 0.000")
+	}
+	if tx.GetAmount() != "0.00" {
+		t.Errorf("Expected amount 0.00, got %s", tx.GetAmount())
+	}
+}
@@ -0,0 +1,300 @@
+package loanpro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// odataBasePath is the path prefix for LoanPro OData entity sets
+const odataBasePath = "/public/api/1/odata.svc/"
+
+// ErrInvalidODataQuery is returned when an OData query contains an invalid identifier or key
+var ErrInvalidODataQuery = errors.New("invalid OData query")
+
+var (
+	// odataIdentifier matches entity sets, properties and slash-separated navigation paths
+	odataIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(/[A-Za-z_][A-Za-z0-9_]*)*$`)
+	// odataIntegerKey matches integer entity keys, which are sent unquoted
+	odataIntegerKey = regexp.MustCompile(`^-?[0-9]+$`)
+)
+
+// ODataQuery builds a LoanPro OData request path and its system query options.
+// Identifiers are validated and literals are escaped, so caller-supplied IDs and
+// filter values can't alter the shape of the request.
+type ODataQuery struct {
+	segments    []string
+	selects     []string
+	expands     []string
+	filter      *ODataFilter
+	orderBy     []string
+	top         int
+	skip        int
+	inlineCount bool
+	err         error
+}
+
+// NewODataQuery creates a query against an entity set, e.g. "Loans"
+func NewODataQuery(entitySet string) *ODataQuery {
+	q := &ODataQuery{}
+	q.segments = append(q.segments, q.identifier(entitySet))
+	return q
+}
+
+// Key addresses a single entity by ID. Integer keys are sent bare and any other
+// value is sent as an escaped string literal.
+func (q *ODataQuery) Key(id string) *ODataQuery {
+	if id == "" {
+		q.setErr(fmt.Errorf("%w: empty key", ErrInvalidODataQuery))
+		return q
+	}
+	if len(q.segments) == 0 {
+		return q
+	}
+
+	key := id
+	if !odataIntegerKey.MatchString(id) {
+		key = url.PathEscape(formatODataString(id))
+	}
+	q.segments[len(q.segments)-1] += "(" + key + ")"
+	return q
+}
+
+// Nav navigates to a related entity set, e.g. Loans(1)/Transactions
+func (q *ODataQuery) Nav(property string) *ODataQuery {
+	q.segments = append(q.segments, q.identifier(property))
+	return q
+}
+
+// Select limits the returned properties ($select)
+func (q *ODataQuery) Select(fields ...string) *ODataQuery {
+	for _, field := range fields {
+		q.selects = appendUnique(q.selects, q.identifier(field))
+	}
+	return q
+}
+
+// Expand includes related entities ($expand). Nested expansions use slash-separated
+// paths, e.g. "Customers/PrimaryAddress".
+func (q *ODataQuery) Expand(paths ...string) *ODataQuery {
+	for _, path := range paths {
+		q.expands = appendUnique(q.expands, q.identifier(path))
+	}
+	return q
+}
+
+// Filter restricts the results ($filter). Repeated calls are combined with "and".
+func (q *ODataQuery) Filter(filter ODataFilter) *ODataQuery {
+	if filter.err != nil {
+		q.setErr(filter.err)
+		return q
+	}
+	if q.filter != nil {
+		filter = And(*q.filter, filter)
+	}
+	q.filter = &filter
+	return q
+}
+
+// OrderBy sorts the results ($orderby). Sorts are applied in the order they are added.
+func (q *ODataQuery) OrderBy(field string, descending bool) *ODataQuery {
+	clause := q.identifier(field)
+	if descending {
+		clause += " desc"
+	} else {
+		clause += " asc"
+	}
+	q.orderBy = append(q.orderBy, clause)
+	return q
+}
+
+// Top limits the number of results ($top). Zero means no limit.
+func (q *ODataQuery) Top(n int) *ODataQuery {
+	q.top = n
+	return q
+}
+
+// Skip skips a number of results ($skip)
+func (q *ODataQuery) Skip(n int) *ODataQuery {
+	q.skip = n
+	return q
+}
+
+// InlineCount requests the total count of matching entities ($inlinecount=allpages)
+func (q *ODataQuery) InlineCount() *ODataQuery {
+	q.inlineCount = true
+	return q
+}
+
+// Build returns the request path and query parameters
+func (q *ODataQuery) Build() (string, map[string]string, error) {
+	if q.err != nil {
+		return "", nil, q.err
+	}
+
+	params := make(map[string]string)
+	if len(q.selects) > 0 {
+		params["$select"] = strings.Join(q.selects, ",")
+	}
+	if len(q.expands) > 0 {
+		params["$expand"] = strings.Join(q.expands, ",")
+	}
+	if q.filter != nil && q.filter.expr != "" {
+		params["$filter"] = q.filter.expr
+	}
+	if len(q.orderBy) > 0 {
+		params["$orderby"] = strings.Join(q.orderBy, ",")
+	}
+	if q.top > 0 {
+		params["$top"] = strconv.Itoa(q.top)
+	}
+	if q.skip > 0 {
+		params["$skip"] = strconv.Itoa(q.skip)
+	}
+	if q.inlineCount {
+		params["$inlinecount"] = "allpages"
+	}
+
+	return odataBasePath + strings.Join(q.segments, "/"), params, nil
+}
+
+// identifier validates an entity set, property or navigation path
+func (q *ODataQuery) identifier(name string) string {
+	if !odataIdentifier.MatchString(name) {
+		q.setErr(fmt.Errorf("%w: invalid identifier %q", ErrInvalidODataQuery, name))
+	}
+	return name
+}
+
+// setErr records the first error encountered while building
+func (q *ODataQuery) setErr(err error) {
+	if q.err == nil {
+		q.err = err
+	}
+}
+
+// appendUnique appends value unless it is already present
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// ODataFilter is a $filter expression built from comparisons and logical operators
+type ODataFilter struct {
+	expr string
+	err  error
+}
+
+// String returns the filter expression
+func (f ODataFilter) String() string {
+	return f.expr
+}
+
+// Eq compares field equal to value
+func Eq(field string, value any) ODataFilter { return comparison(field, "eq", value) }
+
+// Ne compares field not equal to value
+func Ne(field string, value any) ODataFilter { return comparison(field, "ne", value) }
+
+// Gt compares field greater than value
+func Gt(field string, value any) ODataFilter { return comparison(field, "gt", value) }
+
+// Ge compares field greater than or equal to value
+func Ge(field string, value any) ODataFilter { return comparison(field, "ge", value) }
+
+// Lt compares field less than value
+func Lt(field string, value any) ODataFilter { return comparison(field, "lt", value) }
+
+// Le compares field less than or equal to value
+func Le(field string, value any) ODataFilter { return comparison(field, "le", value) }
+
+// And combines filters so that all must match
+func And(filters ...ODataFilter) ODataFilter { return logical("and", filters) }
+
+// Or combines filters so that any may match
+func Or(filters ...ODataFilter) ODataFilter { return logical("or", filters) }
+
+// Not negates a filter
+func Not(filter ODataFilter) ODataFilter {
+	if filter.err != nil {
+		return filter
+	}
+	return ODataFilter{expr: "not (" + filter.expr + ")"}
+}
+
+// comparison builds "field op literal" with a validated field and escaped literal
+func comparison(field, op string, value any) ODataFilter {
+	if !odataIdentifier.MatchString(field) {
+		return ODataFilter{err: fmt.Errorf("%w: invalid filter field %q", ErrInvalidODataQuery, field)}
+	}
+	literal, err := formatODataLiteral(value)
+	if err != nil {
+		return ODataFilter{err: err}
+	}
+	return ODataFilter{expr: field + " " + op + " " + literal}
+}
+
+// logical joins filters with an operator, parenthesizing each operand
+func logical(op string, filters []ODataFilter) ODataFilter {
+	parts := make([]string, 0, len(filters))
+	for _, filter := range filters {
+		if filter.err != nil {
+			return filter
+		}
+		parts = append(parts, "("+filter.expr+")")
+	}
+	if len(parts) == 1 {
+		return filters[0]
+	}
+	return ODataFilter{expr: strings.Join(parts, " "+op+" ")}
+}
+
+// formatODataLiteral renders a Go value as an OData literal
+func formatODataLiteral(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case string:
+		return formatODataString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return "datetime'" + v.UTC().Format("2006-01-02T15:04:05") + "'", nil
+	default:
+		return "", fmt.Errorf("%w: unsupported literal type %T", ErrInvalidODataQuery, value)
+	}
+}
+
+// formatODataString quotes a string literal, doubling embedded single quotes
+func formatODataString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// getOData performs a GET request for an OData query
+func (c *Client) getOData(q *ODataQuery) ([]byte, error) {
+	return c.getODataContext(c.requestContext(), q)
+}
+
+// getODataContext performs a GET request for an OData query that is cancelled along with ctx
+func (c *Client) getODataContext(ctx context.Context, q *ODataQuery) ([]byte, error) {
+	endpoint, params, err := q.Build()
+	if err != nil {
+		return nil, err
+	}
+	return c.makeRequestWithContext(ctx, "GET", endpoint, params, nil)
+}
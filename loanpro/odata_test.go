@@ -0,0 +1,157 @@
+package loanpro
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestODataQuery_Build(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          *ODataQuery
+		expectedPath   string
+		expectedParams map[string]string
+	}{
+		{
+			name:           "Entity set",
+			query:          NewODataQuery("Portfolios"),
+			expectedPath:   "/public/api/1/odata.svc/Portfolios",
+			expectedParams: map[string]string{},
+		},
+		{
+			name:         "Integer key with expand",
+			query:        NewODataQuery("Loans").Key("630").Expand("LoanSetup", "Customers/PrimaryAddress", "LoanSetup"),
+			expectedPath: "/public/api/1/odata.svc/Loans(630)",
+			expectedParams: map[string]string{
+				"$expand": "LoanSetup,Customers/PrimaryAddress",
+			},
+		},
+		{
+			name:         "Navigation with paging",
+			query:        NewODataQuery("Loans").Key("630").Nav("Transactions").Top(50).Skip(100).InlineCount(),
+			expectedPath: "/public/api/1/odata.svc/Loans(630)/Transactions",
+			expectedParams: map[string]string{
+				"$top":         "50",
+				"$skip":        "100",
+				"$inlinecount": "allpages",
+			},
+		},
+		{
+			name: "Select, filter and orderby",
+			query: NewODataQuery("Payments").
+				Select("id", "amount").
+				Filter(Eq("active", 1)).
+				Filter(Ge("date", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))).
+				OrderBy("date", true).
+				OrderBy("id", false),
+			expectedPath: "/public/api/1/odata.svc/Payments",
+			expectedParams: map[string]string{
+				"$select":  "id,amount",
+				"$filter":  "(active eq 1) and (date ge datetime'2025-01-01T00:00:00')",
+				"$orderby": "date desc,id asc",
+			},
+		},
+		{
+			name:           "String key is quoted and escaped",
+			query:          NewODataQuery("Loans").Key("1)/Customers(2"),
+			expectedPath:   "/public/api/1/odata.svc/Loans(%271%29%2FCustomers%282%27)",
+			expectedParams: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, params, err := tt.query.Build()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if path != tt.expectedPath {
+				t.Errorf("Expected path %s, got %s", tt.expectedPath, path)
+			}
+			if len(params) != len(tt.expectedParams) {
+				t.Errorf("Expected params %v, got %v", tt.expectedParams, params)
+			}
+			for key, expected := range tt.expectedParams {
+				if params[key] != expected {
+					t.Errorf("Expected %s=%q, got %q", key, expected, params[key])
+				}
+			}
+		})
+	}
+}
+
+func TestODataFilter_Escaping(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   ODataFilter
+		expected string
+	}{
+		{"String literal", Eq("title", "O'Brien"), "title eq 'O''Brien'"},
+		{"Injection attempt stays a literal", Eq("title", "x' or 1 eq 1 or 'a"), "title eq 'x'' or 1 eq 1 or ''a'"},
+		{"Bool", Ne("archived", true), "archived ne true"},
+		{"Float", Lt("amount", 10.5), "amount lt 10.5"},
+		{"Null", Eq("deleted", nil), "deleted eq null"},
+		{"Or", Or(Eq("a", 1), Eq("b", 2)), "(a eq 1) or (b eq 2)"},
+		{"Not", Not(Gt("daysPastDue", 30)), "not (daysPastDue gt 30)"},
+		{"Single operand", And(Le("x", 1)), "x le 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.filter.err != nil {
+				t.Fatalf("Expected no error, got %v", tt.filter.err)
+			}
+			if tt.filter.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, tt.filter.String())
+			}
+		})
+	}
+}
+
+func TestODataQuery_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *ODataQuery
+	}{
+		{"Empty key", NewODataQuery("Loans").Key("")},
+		{"Entity set with query string", NewODataQuery("Loans?$top=1")},
+		{"Expand injection", NewODataQuery("Loans").Expand("Payments&$top=1")},
+		{"Filter field injection", NewODataQuery("Loans").Filter(Eq("id eq 1 or id", 2))},
+		{"Unsupported literal", NewODataQuery("Loans").Filter(Eq("id", []int{1}))},
+		{"Orderby injection", NewODataQuery("Loans").OrderBy("id desc,title", false)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.query.Build(); !errors.Is(err, ErrInvalidODataQuery) {
+				t.Errorf("Expected ErrInvalidODataQuery, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGetLoan_EscapesID(t *testing.T) {
+	var requestedPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.EscapedPath()
+		w.Write([]byte(`{"d":{"id":1}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", "tenant")
+
+	if _, err := client.GetLoan("630"); err != nil {
+		t.Fatalf("GetLoan failed: %v", err)
+	}
+	if requestedPath != "/public/api/1/odata.svc/Loans(630)" {
+		t.Errorf("Unexpected path for integer ID: %s", requestedPath)
+	}
+
+	client.GetLoan("1)/Customers(2")
+	if requestedPath != "/public/api/1/odata.svc/Loans(%271%29%2FCustomers%282%27)" {
+		t.Errorf("Expected crafted ID to stay inside the key literal, got %s", requestedPath)
+	}
+}
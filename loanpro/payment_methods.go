@@ -9,15 +9,12 @@ func (p *Payment) GetID() string {
 
 // GetAmount returns the payment amount
 func (p *Payment) GetAmount() string {
-	return p.Amount
+	return p.Amount.String()
 }
 
-// GetDate returns the payment date (with date parsing if needed)
+// GetDate returns the payment date as YYYY-MM-DD
 func (p *Payment) GetDate() string {
-	if parsed, err := parseLoanProDate(p.Date); err == nil {
-		return parsed
-	}
-	return p.Date
+	return p.Date.String()
 }
 
 // GetStatus returns the payment status as a human-readable string
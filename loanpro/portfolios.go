@@ -0,0 +1,97 @@
+package loanpro
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+// Portfolio represents a LoanPro portfolio (e.g. a lending partner or securitization)
+type Portfolio struct {
+	ID            json.Number           `json:"id"`
+	Title         string                `json:"title"`
+	NumPrefix     string                `json:"numPrefix"`
+	NumSuffix     string                `json:"numSuffix"`
+	Active        json.Number           `json:"active"`
+	SubPortfolios *SubPortfoliosWrapper `json:"SubPortfolios,omitempty"`
+}
+
+// SubPortfolio represents a sub-portfolio nested under a portfolio
+type SubPortfolio struct {
+	ID       json.Number `json:"id"`
+	ParentID json.Number `json:"parent"`
+	Title    string      `json:"title"`
+	Active   json.Number `json:"active"`
+}
+
+// PortfoliosWrapper wraps the portfolios expanded on a loan
+type PortfoliosWrapper struct {
+	Results []Portfolio `json:"results"`
+}
+
+// SubPortfoliosWrapper wraps sub-portfolio results
+type SubPortfoliosWrapper struct {
+	Results []SubPortfolio `json:"results"`
+}
+
+// GetPortfolios retrieves all portfolios with their sub-portfolios
+func (c *Client) GetPortfolios() ([]Portfolio, error) {
+	body, err := c.getOData(NewODataQuery("Portfolios").Expand("SubPortfolios"))
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		D struct {
+			Results []Portfolio `json:"results"`
+		} `json:"d"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		slog.ErrorContext(c.requestContext(), "Failed to parse GetPortfolios response", "error", err, "body", string(body))
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return response.D.Results, nil
+}
+
+// Helper methods for Portfolio
+
+// GetID returns the portfolio ID as string
+func (p *Portfolio) GetID() string {
+	return string(p.ID)
+}
+
+// GetTitle returns the portfolio title
+func (p *Portfolio) GetTitle() string {
+	return p.Title
+}
+
+// IsActive checks if the portfolio is active
+func (p *Portfolio) IsActive() bool {
+	return string(p.Active) == "1"
+}
+
+// GetSubPortfolios returns the portfolio's sub-portfolios
+func (p *Portfolio) GetSubPortfolios() []SubPortfolio {
+	if p.SubPortfolios == nil {
+		return []SubPortfolio{}
+	}
+	return p.SubPortfolios.Results
+}
+
+// Helper methods for SubPortfolio
+
+// GetID returns the sub-portfolio ID as string
+func (s *SubPortfolio) GetID() string {
+	return string(s.ID)
+}
+
+// GetTitle returns the sub-portfolio title
+func (s *SubPortfolio) GetTitle() string {
+	return s.Title
+}
+
+// IsActive checks if the sub-portfolio is active
+func (s *SubPortfolio) IsActive() bool {
+	return string(s.Active) == "1"
+}
@@ -0,0 +1,67 @@
+package loanpro
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const mockPortfoliosResponse = `{
+    "d": {
+        "results": [
+            {
+                "id": 1,
+                "title": "Partner Bank",
+                "numPrefix": "PB",
+                "numSuffix": "",
+                "active": 1,
+                "SubPortfolios": {
+                    "results": [
+                        {"id": 10, "parent": 1, "title": "2025-A Securitization", "active": 1},
+                        {"id": 11, "parent": 1, "title": "2019-A Securitization", "active": 0}
+                    ]
+                }
+            },
+            {"id": 2, "title": "Legacy Program", "active": 0}
+        ]
+    }
+}`
+
+func TestPortfolioUnmarshal(t *testing.T) {
+	var response struct {
+		D struct {
+			Results []Portfolio `json:"results"`
+		} `json:"d"`
+	}
+	if err := json.Unmarshal([]byte(mockPortfoliosResponse), &response); err != nil {
+		t.Fatalf("Failed to unmarshal portfolios: %v", err)
+	}
+
+	portfolios := response.D.Results
+	if len(portfolios) != 2 {
+		t.Fatalf("Expected 2 portfolios, got %d", len(portfolios))
+	}
+
+	partner := portfolios[0]
+	if partner.GetID() != "1" || partner.GetTitle() != "Partner Bank" || !partner.IsActive() {
+		t.Errorf("Unexpected portfolio: %+v", partner)
+	}
+
+	subs := partner.GetSubPortfolios()
+	if len(subs) != 2 {
+		t.Fatalf("Expected 2 sub-portfolios, got %d", len(subs))
+	}
+	if subs[0].GetID() != "10" || !subs[0].IsActive() {
+		t.Errorf("Unexpected sub-portfolio: %+v", subs[0])
+	}
+	if subs[1].IsActive() {
+		t.Error("Expected sub-portfolio 11 to be inactive")
+	}
+
+	legacy := portfolios[1]
+	if legacy.IsActive() {
+		t.Error("Expected legacy portfolio to be inactive")
+	}
+	if len(legacy.GetSubPortfolios()) != 0 {
+		t.Error("Expected no sub-portfolios when not expanded")
+	}
+}
@@ -0,0 +1,151 @@
+package loanpro
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimitConfig configures outbound request throttling. Zero values leave that
+// limit off.
+type RateLimitConfig struct {
+	RequestsPerSecond float64 // Sustained request rate
+	Burst             int     // Requests allowed at once before the rate applies (defaults to 1)
+	MaxConcurrent     int     // Maximum requests in flight
+}
+
+// RateLimitStats contains the current state of the outbound limiter
+type RateLimitStats struct {
+	Queued   int64 `json:"queued"`    // Requests waiting for a token or a concurrency slot
+	InFlight int64 `json:"in_flight"` // Requests currently being sent
+}
+
+// rateLimiter combines a token bucket with a semaphore capping requests in flight
+type rateLimiter struct {
+	rate  float64 // tokens added per second, 0 for no rate limit
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64 // may go negative while callers hold reservations
+	last   time.Time
+
+	slots chan struct{} // nil for no concurrency limit
+
+	queued   atomic.Int64
+	inFlight atomic.Int64
+}
+
+// newRateLimiter creates a limiter with a full bucket
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	burst := float64(config.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	l := &rateLimiter{
+		rate:   config.RequestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+	if config.MaxConcurrent > 0 {
+		l.slots = make(chan struct{}, config.MaxConcurrent)
+	}
+	return l
+}
+
+// acquire waits for a concurrency slot and a token. The returned function must be
+// called when the request completes.
+func (l *rateLimiter) acquire(ctx context.Context) (func(), error) {
+	l.queued.Add(1)
+	queued := true
+	defer func() {
+		if queued {
+			l.queued.Add(-1)
+		}
+	}()
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for LoanPro request slot: %w", ctx.Err())
+		}
+	}
+
+	if err := l.waitForToken(ctx); err != nil {
+		if l.slots != nil {
+			<-l.slots
+		}
+		return nil, fmt.Errorf("waiting for LoanPro rate limit: %w", err)
+	}
+
+	l.queued.Add(-1)
+	queued = false
+	l.inFlight.Add(1)
+
+	return func() {
+		l.inFlight.Add(-1)
+		if l.slots != nil {
+			<-l.slots
+		}
+	}, nil
+}
+
+// waitForToken reserves a token and sleeps until it is available. Reserving up
+// front keeps waiting callers in arrival order.
+func (l *rateLimiter) waitForToken(ctx context.Context) error {
+	if l.rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give the reservation back so later callers don't wait for it
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// stats returns a snapshot of the limiter state
+func (l *rateLimiter) stats() RateLimitStats {
+	return RateLimitStats{
+		Queued:   l.queued.Load(),
+		InFlight: l.inFlight.Load(),
+	}
+}
+
+// EnableRateLimit throttles outbound requests to LoanPro. It should be called
+// before the client is used.
+func (c *Client) EnableRateLimit(config RateLimitConfig) {
+	c.limiter = newRateLimiter(config)
+}
+
+// RateLimitStats returns the outbound limiter state. It returns zero stats when
+// rate limiting is disabled.
+func (c *Client) RateLimitStats() RateLimitStats {
+	if c.limiter == nil {
+		return RateLimitStats{}
+	}
+	return c.limiter.stats()
+}
@@ -0,0 +1,134 @@
+package loanpro
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	server, requests := newCountingServer(t, nil)
+	client := NewClient(server.URL, "key", "tenant")
+	client.EnableRateLimit(RateLimitConfig{RequestsPerSecond: 20, Burst: 2})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := client.GetLoan("1"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	elapsed := time.Since(start)
+
+	// Two requests use the burst, the next two wait 50ms each
+	if elapsed < 90*time.Millisecond {
+		t.Errorf("Expected requests beyond the burst to be throttled, took %v", elapsed)
+	}
+	if requests.Load() != 4 {
+		t.Errorf("Expected 4 requests, got %d", requests.Load())
+	}
+}
+
+func TestRateLimiterMaxConcurrent(t *testing.T) {
+	var current, peak atomic.Int32
+	release := make(chan struct{})
+	server, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		n := current.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		current.Add(-1)
+		w.Write([]byte(`{"d": {"id": 1}}`))
+	})
+	client := NewClient(server.URL, "key", "tenant")
+	client.EnableRateLimit(RateLimitConfig{MaxConcurrent: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.GetLoan("1")
+		}()
+	}
+
+	// Two requests are in flight and three are queued behind them
+	deadline := time.Now().Add(5 * time.Second)
+	for (client.RateLimitStats().Queued < 3 || current.Load() < 2) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stats := client.RateLimitStats()
+	if stats.Queued != 3 || stats.InFlight != 2 {
+		t.Errorf("Expected 3 queued and 2 in flight, got %+v", stats)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if peak.Load() != 2 {
+		t.Errorf("Expected at most 2 concurrent requests, got %d", peak.Load())
+	}
+	if stats := client.RateLimitStats(); stats.Queued != 0 || stats.InFlight != 0 {
+		t.Errorf("Expected empty queue after completion, got %+v", stats)
+	}
+}
+
+func TestRateLimiterContextCancel(t *testing.T) {
+	l := newRateLimiter(RateLimitConfig{RequestsPerSecond: 1})
+
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Expected first token from the burst, got %v", err)
+	}
+	release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded while queued, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Expected cancelled wait to return promptly, took %v", time.Since(start))
+	}
+	if stats := l.stats(); stats.Queued != 0 {
+		t.Errorf("Expected cancelled caller to leave the queue, got %+v", stats)
+	}
+
+	// The cancelled reservation is returned, so the next wait is at most one interval
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -0.01 {
+		t.Errorf("Expected cancelled reservation to be returned, tokens=%v", tokens)
+	}
+}
+
+func TestRateLimiterSlotCancel(t *testing.T) {
+	l := newRateLimiter(RateLimitConfig{MaxConcurrent: 1})
+
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.acquire(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled waiting for a slot, got %v", err)
+	}
+
+	release()
+	release2, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Expected slot to be free after release, got %v", err)
+	}
+	release2()
+}
@@ -0,0 +1,238 @@
+package loanpro
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ErrNoFixture is returned in replay mode when no fixture was recorded for a request
+var ErrNoFixture = errors.New("no recorded fixture for request")
+
+// Fixture is a recorded request/response pair
+type Fixture struct {
+	Request  FixtureRequest  `json:"request"`
+	Response FixtureResponse `json:"response"`
+}
+
+// FixtureRequest is the sanitized request of a fixture
+type FixtureRequest struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"` // Path and query, without the host
+	Header http.Header     `json:"header,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	Text   string          `json:"text,omitempty"` // Body when it isn't JSON
+}
+
+// FixtureResponse is the sanitized response of a fixture
+type FixtureResponse struct {
+	StatusCode int             `json:"statusCode"`
+	Header     http.Header     `json:"header,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	Text       string          `json:"text,omitempty"` // Body when it isn't JSON
+}
+
+// fixtureName keeps fixture file names to characters safe on every filesystem
+var fixtureName = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// fixtureKey identifies a request by its method, URL and body scrubbed with
+// placeholders, so the same request made while recording and while replaying maps
+// to the same fixture. Requests differing only in names or emails share a fixture.
+func fixtureKey(method, requestURL string, body []byte) string {
+	sum := sha256.Sum256([]byte(method + " " + requestURL + "\n" + string(body)))
+	return hex.EncodeToString(sum[:6])
+}
+
+// fixtureFile returns the file a fixture is stored in, e.g.
+// "GET_Loans_101_Transactions_1a2b3c4d5e6f.json"
+func fixtureFile(dir, method, requestURL string, body []byte) string {
+	path, _, _ := strings.Cut(requestURL, "?")
+	path = strings.TrimPrefix(path, odataBasePath)
+	path = strings.TrimPrefix(path, "/public/api/1/")
+	path = strings.Trim(fixtureName.ReplaceAllString(path, "_"), "_")
+	return filepath.Join(dir, method+"_"+path+"_"+fixtureKey(method, requestURL, body)+".json")
+}
+
+// Recorder is an http.RoundTripper that sends requests to LoanPro and saves a
+// scrubbed copy of each request and response to a directory
+type Recorder struct {
+	dir       string
+	transport http.RoundTripper
+	scrubber  *Scrubber
+}
+
+// NewRecorder creates a recorder that saves fixtures to dir. A nil transport uses
+// http.DefaultTransport and a nil scrubber uses DefaultScrubber.
+func NewRecorder(dir string, transport http.RoundTripper, scrubber *Scrubber) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if scrubber == nil {
+		scrubber = DefaultScrubber()
+	}
+	return &Recorder{dir: dir, transport: transport, scrubber: scrubber}
+}
+
+// RoundTrip sends the request and records it. Recording failures are logged and
+// don't affect the response.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.save(req, requestBody, resp, responseBody); err != nil {
+		slog.ErrorContext(req.Context(), "Failed to record LoanPro fixture", "url", req.URL.Path, "error", err)
+	}
+	return resp, nil
+}
+
+// save writes a scrubbed fixture to disk
+func (r *Recorder) save(req *http.Request, requestBody []byte, resp *http.Response, responseBody []byte) error {
+	method, requestURL, scrubbedRequest := scrubRequest(r.scrubber, req, requestBody)
+	_, keyURL, keyRequest := scrubRequest(r.scrubber.withPlaceholders(), req, requestBody)
+
+	fixture := Fixture{
+		Request: FixtureRequest{
+			Method: method,
+			URL:    requestURL,
+			Header: r.scrubber.ScrubHeaders(req.Header),
+		},
+		Response: FixtureResponse{
+			StatusCode: resp.StatusCode,
+			Header:     r.scrubber.ScrubHeaders(resp.Header),
+		},
+	}
+	fixture.Request.Body, fixture.Request.Text = splitBody(scrubbedRequest)
+	fixture.Response.Body, fixture.Response.Text = splitBody(r.scrubber.ScrubBody(responseBody))
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(fixtureFile(r.dir, method, keyURL, keyRequest), append(data, '\n'), 0o644)
+}
+
+// Replayer is an http.RoundTripper that serves fixtures saved by a Recorder
+// instead of contacting LoanPro
+type Replayer struct {
+	dir      string
+	scrubber *Scrubber
+}
+
+// NewReplayer creates a replayer serving fixtures from dir. The scrubber must remove
+// the same fields as the one used to record; nil uses DefaultScrubber.
+func NewReplayer(dir string, scrubber *Scrubber) *Replayer {
+	if scrubber == nil {
+		scrubber = DefaultScrubber()
+	}
+	return &Replayer{dir: dir, scrubber: scrubber}
+}
+
+// RoundTrip returns the recorded response for the request, or ErrNoFixture
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	method, requestURL, scrubbedRequest := scrubRequest(r.scrubber.withPlaceholders(), req, requestBody)
+	data, err := os.ReadFile(fixtureFile(r.dir, method, requestURL, scrubbedRequest))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoFixture, method, requestURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture for %s %s: %w", method, requestURL, err)
+	}
+
+	body := []byte(fixture.Response.Body)
+	if len(body) == 0 {
+		body = []byte(fixture.Response.Text)
+	}
+	header := fixture.Response.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Response.StatusCode, http.StatusText(fixture.Response.StatusCode)),
+		StatusCode:    fixture.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// scrubRequest returns the scrubbed method, path with query, and body of a request
+func scrubRequest(scrubber *Scrubber, req *http.Request, body []byte) (string, string, []byte) {
+	requestURL := req.URL.Path
+	if req.URL.RawQuery != "" {
+		query, _ := url.QueryUnescape(req.URL.RawQuery)
+		requestURL += "?" + scrubber.ScrubString(query, nil)
+	}
+	return req.Method, requestURL, scrubber.ScrubBody(body)
+}
+
+// readBody reads a request or response body and replaces it with a fresh reader
+// over the same bytes
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// splitBody returns a body as JSON when it is valid JSON and as text otherwise
+func splitBody(body []byte) (json.RawMessage, string) {
+	if len(body) == 0 {
+		return nil, ""
+	}
+	if json.Valid(body) {
+		return json.RawMessage(body), ""
+	}
+	return nil, string(body)
+}
+
+// SetTransport replaces the HTTP transport used to reach LoanPro, e.g. with a
+// Recorder or Replayer. It should be called before the client is used.
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.client.Transport = transport
+}
@@ -0,0 +1,130 @@
+package loanpro
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"loanpro-mcp-server/loanpro/fake"
+)
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID))
+	defer server.Close()
+	dir := t.TempDir()
+
+	recording := NewClient(server.URL, fake.DefaultAPIKey, fake.DefaultTenantID)
+	recording.SetTransport(NewRecorder(dir, nil, nil))
+
+	recordedLoan, err := recording.GetLoan("101")
+	if err != nil {
+		t.Fatalf("Expected no error while recording, got %v", err)
+	}
+	recordedCustomers, err := recording.SearchCustomers("Smith", 5)
+	if err != nil {
+		t.Fatalf("Expected no error while recording, got %v", err)
+	}
+	if len(recordedCustomers) == 0 {
+		t.Fatal("Expected the fake to return customers")
+	}
+
+	// GetLoan also looks up custom field names
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) != 3 {
+		t.Fatalf("Expected 3 fixtures, got %v (%v)", files, err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, leaked := range []string{"Jane", "Smith", "jane.smith@example.com", "123456789", "5551234567", "fake-loanpro-token", fake.DefaultTenantID} {
+			if strings.Contains(string(data), leaked) {
+				t.Errorf("Expected %s not to contain %q", filepath.Base(file), leaked)
+			}
+		}
+	}
+
+	// The replaying client never reaches a server
+	replaying := NewClient("http://loanpro.invalid", "Bearer other", "other")
+	replaying.SetTransport(NewReplayer(dir, nil))
+
+	loan, err := replaying.GetLoan("101")
+	if err != nil {
+		t.Fatalf("Expected no error while replaying, got %v", err)
+	}
+	if loan.ID != recordedLoan.ID || loan.DisplayID != recordedLoan.DisplayID {
+		t.Errorf("Expected replayed loan %s, got %s", recordedLoan.ID, loan.ID)
+	}
+	customers, err := replaying.SearchCustomers("Smith", 5)
+	if err != nil {
+		t.Fatalf("Expected no error while replaying, got %v", err)
+	}
+	if len(customers) != len(recordedCustomers) {
+		t.Errorf("Expected %d replayed customers, got %d", len(recordedCustomers), len(customers))
+	}
+	if len(customers) > 0 && customers[0].LastName == "Smith" {
+		t.Error("Expected replayed customer names to be pseudonyms")
+	}
+
+	if _, err := replaying.GetLoan("999"); !errors.Is(err, ErrNoFixture) {
+		t.Errorf("Expected ErrNoFixture for an unrecorded request, got %v", err)
+	}
+}
+
+func TestRecorder_RecordsErrors(t *testing.T) {
+	server := httptest.NewServer(fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID))
+	defer server.Close()
+	dir := t.TempDir()
+
+	recording := NewClient(server.URL, fake.DefaultAPIKey, fake.DefaultTenantID)
+	recording.SetTransport(NewRecorder(dir, nil, nil))
+	if _, err := recording.GetLoan("999"); err == nil {
+		t.Fatal("Expected an error for an unknown loan")
+	}
+
+	replaying := NewClient("http://loanpro.invalid", "", "")
+	replaying.SetTransport(NewReplayer(dir, nil))
+	_, err := replaying.GetLoan("999")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 404 {
+		t.Errorf("Expected the recorded 404 to be replayed, got %v", err)
+	}
+}
+
+func TestRecorder_PseudonymsPerRecording(t *testing.T) {
+	server := httptest.NewServer(fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID))
+	defer server.Close()
+
+	// The same search recorded twice is saved under the same name with different pseudonyms
+	var recorded []string
+	var names []string
+	for range 2 {
+		dir := t.TempDir()
+		client := NewClient(server.URL, fake.DefaultAPIKey, fake.DefaultTenantID)
+		client.SetTransport(NewRecorder(dir, nil, nil))
+		if _, err := client.SearchCustomers("Smith", 5); err != nil {
+			t.Fatalf("Expected no error while recording, got %v", err)
+		}
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil || len(files) != 1 {
+			t.Fatalf("Expected 1 fixture, got %v (%v)", files, err)
+		}
+		data, err := os.ReadFile(files[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorded = append(recorded, string(data))
+		names = append(names, filepath.Base(files[0]))
+	}
+
+	if names[0] != names[1] {
+		t.Errorf("Expected the same fixture name, got %s and %s", names[0], names[1])
+	}
+	if recorded[0] == recorded[1] {
+		t.Error("Expected each recording to use its own pseudonyms")
+	}
+}
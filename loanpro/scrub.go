@@ -0,0 +1,243 @@
+package loanpro
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	// emailPattern finds email addresses in free text
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// ssnPattern finds dashed SSNs in free text. Undashed nine-digit numbers are
+	// left alone since they are indistinguishable from IDs.
+	ssnPattern = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+)
+
+// Scrubber removes personal data and credentials from recorded requests and
+// responses. Names and emails are replaced with pseudonyms keyed with a random
+// secret that is never saved, so the same customer gets the same pseudonym within a
+// recording but names can't be recovered from fixtures by hashing guesses. SSNs,
+// phone numbers and birth dates are masked to a fixed value of the same shape, so
+// they still parse.
+type Scrubber struct {
+	Headers     []string // Headers removed entirely, e.g. Authorization
+	NameFields  []string // JSON fields holding personal names
+	EmailFields []string // JSON fields holding email addresses
+	MaskFields  []string // JSON fields whose digits are zeroed, e.g. SSNs and phone numbers
+
+	key          []byte    // HMAC key of pseudonyms, created on first use
+	keyOnce      sync.Once // Guards key
+	placeholders bool      // Replace names and emails with a fixed placeholder instead
+}
+
+// DefaultScrubber returns a scrubber covering the LoanPro fields that hold customer
+// personal data and the headers that carry credentials
+func DefaultScrubber() *Scrubber {
+	return &Scrubber{
+		Headers: []string{"Authorization", "Autopal-Instance-Id", "Cookie", "Set-Cookie"},
+		NameFields: []string{
+			"firstName", "middleName", "lastName", "companyName", "primaryCustomerName",
+			"customerName", "accountHolderName", "query",
+		},
+		EmailFields: []string{"email"},
+		MaskFields: []string{
+			"ssn", "phone", "primaryPhone", "phoneNumber", "birthDate", "driverLicense",
+			"accountNumber", "routingNumber", "address1", "address2",
+		},
+	}
+}
+
+// withPlaceholders returns a scrubber removing the same data, but replacing every
+// name and email with the same placeholder. Fixtures are found by the request
+// scrubbed this way, which doesn't depend on the recording's secret.
+func (s *Scrubber) withPlaceholders() *Scrubber {
+	return &Scrubber{
+		Headers:      s.Headers,
+		NameFields:   s.NameFields,
+		EmailFields:  s.EmailFields,
+		MaskFields:   s.MaskFields,
+		placeholders: true,
+	}
+}
+
+// ScrubHeaders returns a copy of h without the scrubbed headers
+func (s *Scrubber) ScrubHeaders(h http.Header) http.Header {
+	scrubbed := h.Clone()
+	for _, name := range s.Headers {
+		scrubbed.Del(name)
+	}
+	return scrubbed
+}
+
+// ScrubBody scrubs a request or response body. JSON bodies are scrubbed field by
+// field and then for names seen in those fields appearing in other text; anything
+// else is scrubbed for email addresses and SSNs.
+func (s *Scrubber) ScrubBody(body []byte) []byte {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return []byte(s.ScrubString(string(body), nil))
+	}
+
+	names := map[string]string{}
+	value = s.scrubValue(value, "", names)
+	value = s.scrubText(value, names)
+
+	scrubbed, err := json.Marshal(value)
+	if err != nil {
+		return []byte(s.ScrubString(string(body), names))
+	}
+	return scrubbed
+}
+
+// ScrubString replaces email addresses, SSNs and the given names in free text.
+// names maps lower-cased name words to their pseudonyms.
+func (s *Scrubber) ScrubString(text string, names map[string]string) string {
+	text = emailPattern.ReplaceAllStringFunc(text, s.pseudonymEmail)
+	text = ssnPattern.ReplaceAllString(text, "000-00-0000")
+	if len(names) == 0 {
+		return text
+	}
+
+	words := make([]string, 0, len(names))
+	for word := range names {
+		words = append(words, regexp.QuoteMeta(word))
+	}
+	re := regexp.MustCompile(`(?i)\b(` + strings.Join(words, "|") + `)\b`)
+	return re.ReplaceAllStringFunc(text, func(word string) string {
+		return names[strings.ToLower(word)]
+	})
+}
+
+// scrubValue replaces sensitive fields in decoded JSON, recording the name words it
+// replaced so they can also be removed from free text
+func (s *Scrubber) scrubValue(value any, key string, names map[string]string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = s.scrubValue(item, k, names)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = s.scrubValue(item, key, names)
+		}
+		return v
+	case string:
+		switch {
+		case containsFold(s.NameFields, key):
+			return s.pseudonymName(v, names)
+		case containsFold(s.EmailFields, key):
+			return emailPattern.ReplaceAllStringFunc(v, s.pseudonymEmail)
+		case containsFold(s.MaskFields, key):
+			return maskDigits(v)
+		}
+		return v
+	case json.Number:
+		if containsFold(s.MaskFields, key) {
+			// Zero-padded digits aren't a valid JSON number
+			return json.Number("0")
+		}
+		return v
+	}
+	return value
+}
+
+// scrubText applies ScrubString to every string left in decoded JSON
+func (s *Scrubber) scrubText(value any, names map[string]string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = s.scrubText(item, names)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = s.scrubText(item, names)
+		}
+		return v
+	case string:
+		return s.ScrubString(v, names)
+	}
+	return value
+}
+
+// pseudonymName replaces each word of a name with a pseudonym such as "N3fa1c2d0",
+// recording the replacements in names
+func (s *Scrubber) pseudonymName(name string, names map[string]string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		// Search wildcards stay in place around the pseudonym
+		trimmed := strings.Trim(word, "*?")
+		if trimmed == "" {
+			continue
+		}
+		pseudonym := "N" + s.shortHash(strings.ToLower(trimmed))
+		names[strings.ToLower(trimmed)] = pseudonym
+		words[i] = strings.Replace(word, trimmed, pseudonym, 1)
+	}
+	return strings.Join(words, " ")
+}
+
+// pseudonymEmail replaces an email address with one at example.com
+func (s *Scrubber) pseudonymEmail(email string) string {
+	return "user-" + s.shortHash(strings.ToLower(email)) + "@example.com"
+}
+
+// maskDigits replaces digits with 0 and letters with X, keeping punctuation so
+// formats still parse. Dates are masked to a valid date instead.
+func maskDigits(s string) string {
+	if loanProTimestamp.MatchString(s) {
+		return "/Date(0)/"
+	}
+	if _, err := ParseDate(s); err == nil && len(s) >= 10 && s[4] == '-' {
+		return "1900-01-01" + s[10:]
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return '0'
+		}
+		if r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' {
+			return 'X'
+		}
+		return r
+	}, s)
+}
+
+// shortHash returns the first 8 hex digits of the HMAC-SHA256 of value under the
+// scrubber's key, or zeros when the scrubber uses placeholders
+func (s *Scrubber) shortHash(value string) string {
+	if s.placeholders {
+		return "00000000"
+	}
+	s.keyOnce.Do(func() {
+		s.key = make([]byte, 32)
+		rand.Read(s.key)
+	})
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:4])
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
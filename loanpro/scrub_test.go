@@ -0,0 +1,98 @@
+package loanpro
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestScrubber_ScrubBody(t *testing.T) {
+	body := `{"d": {
+		"id": 42,
+		"title": "Auto loan for Jane Smith",
+		"Customers": {"results": [{"id": 7, "firstName": "Jane", "lastName": "Smith", "email": "Jane.Smith@example.org", "ssn": "123-45-6789", "phone": "(555) 123-4567", "birthDate": "1985-04-12"}]},
+		"info": "Contacted jane.smith@example.org, SSN on file 123-45-6789",
+		"ssn": 123456789,
+		"principalBalance": "1234.56"
+	}}`
+
+	scrubbed := string(DefaultScrubber().ScrubBody([]byte(body)))
+
+	for _, leaked := range []string{"Jane", "Smith", "jane.smith", "123-45-6789", "123456789", "123-4567", "1985"} {
+		if strings.Contains(strings.ToLower(scrubbed), strings.ToLower(leaked)) {
+			t.Errorf("Expected %q to be scrubbed, got %s", leaked, scrubbed)
+		}
+	}
+	for _, kept := range []string{`"id":42`, `"principalBalance":"1234.56"`, `"birthDate":"1900-01-01"`, `"phone":"(000) 000-0000"`} {
+		if !strings.Contains(scrubbed, kept) {
+			t.Errorf("Expected scrubbed body to contain %s, got %s", kept, scrubbed)
+		}
+	}
+
+	// Pseudonyms are consistent, so the name in the title matches the customer record
+	var decoded struct {
+		D struct {
+			Title     string `json:"title"`
+			Customers struct {
+				Results []struct {
+					FirstName string `json:"firstName"`
+					LastName  string `json:"lastName"`
+					Email     string `json:"email"`
+				} `json:"results"`
+			} `json:"Customers"`
+		} `json:"d"`
+	}
+	if err := json.Unmarshal([]byte(scrubbed), &decoded); err != nil {
+		t.Fatalf("Expected scrubbed body to be valid JSON: %v", err)
+	}
+	customer := decoded.D.Customers.Results[0]
+	if decoded.D.Title != "Auto loan for "+customer.FirstName+" "+customer.LastName {
+		t.Errorf("Expected title to use the customer's pseudonym, got %q and %+v", decoded.D.Title, customer)
+	}
+	if !strings.HasSuffix(customer.Email, "@example.com") {
+		t.Errorf("Expected pseudonymous email, got %q", customer.Email)
+	}
+}
+
+func TestScrubber_Pseudonyms(t *testing.T) {
+	body := []byte(`{"query": {"multi_match": {"query": "Jane Smith"}}, "email": "a@b.com"}`)
+	scrubber := DefaultScrubber()
+	first := scrubber.ScrubBody(body)
+	if second := scrubber.ScrubBody(body); string(first) != string(second) {
+		t.Errorf("Expected a scrubber to give the same pseudonyms, got %s and %s", first, second)
+	}
+	if strings.Contains(string(first), "Jane") {
+		t.Errorf("Expected search terms to be scrubbed, got %s", first)
+	}
+
+	// Pseudonyms are keyed per scrubber, so they can't be recomputed from a guessed name
+	if other := DefaultScrubber().ScrubBody(body); string(other) == string(first) {
+		t.Errorf("Expected another scrubber to give different pseudonyms, got %s", other)
+	}
+}
+
+func TestScrubber_ScrubBodyText(t *testing.T) {
+	scrubbed := string(DefaultScrubber().ScrubBody([]byte("error for bob@example.org with 987-65-4321")))
+	if strings.Contains(scrubbed, "bob@") || strings.Contains(scrubbed, "987-65-4321") {
+		t.Errorf("Expected text body to be scrubbed, got %q", scrubbed)
+	}
+}
+
+func TestScrubber_ScrubHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer secret")
+	h.Set("Autopal-Instance-Id", "5200243")
+	h.Set("Content-Type", "application/json")
+
+	scrubbed := DefaultScrubber().ScrubHeaders(h)
+	if scrubbed.Get("Authorization") != "" || scrubbed.Get("Autopal-Instance-Id") != "" {
+		t.Errorf("Expected credentials to be removed, got %v", scrubbed)
+	}
+	if scrubbed.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected other headers to be kept, got %v", scrubbed)
+	}
+	if h.Get("Authorization") == "" {
+		t.Error("Expected original headers to be unchanged")
+	}
+}
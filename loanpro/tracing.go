@@ -0,0 +1,45 @@
+package loanpro
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"loanpro-mcp-server/redact"
+)
+
+// tracer creates the spans of LoanPro API requests
+var tracer = otel.Tracer("loanpro-mcp-server/loanpro")
+
+// EnableTracePropagation adds the W3C trace context headers to LoanPro requests,
+// which otherwise leave without them. It should be called before the client is used.
+func (c *Client) EnableTracePropagation() {
+	c.propagateTrace = true
+}
+
+// startRequestSpan starts the span of a LoanPro API request, named after the
+// endpoint without IDs, e.g. "LoanPro GET Loans/Transactions"
+func startRequestSpan(ctx context.Context, method, endpoint string) (context.Context, trace.Span) {
+	label := endpointLabel(endpoint)
+	return tracer.Start(ctx, "LoanPro "+method+" "+label,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(method),
+			attribute.String("loanpro.endpoint", label),
+		))
+}
+
+// endSpan records a request's error, if any, and ends its span. Error messages can
+// hold request URLs, so they are redacted like logs.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		message := redact.Default().String(err.Error())
+		span.AddEvent("exception", trace.WithAttributes(semconv.ExceptionMessage(message)))
+		span.SetStatus(codes.Error, message)
+	}
+	span.End()
+}
@@ -17,28 +17,19 @@ func (t *Transaction) GetEntityID() string {
 	return string(t.EntityID)
 }
 
-// GetDate returns the transaction date (with date parsing if needed)
+// GetDate returns the transaction date as YYYY-MM-DD
 func (t *Transaction) GetDate() string {
-	if parsed, err := parseLoanProDate(t.Date); err == nil {
-		return parsed
-	}
-	return t.Date
+	return t.Date.String()
 }
 
-// GetPeriodStart returns the period start date (with date parsing if needed)
+// GetPeriodStart returns the period start date as YYYY-MM-DD
 func (t *Transaction) GetPeriodStart() string {
-	if parsed, err := parseLoanProDate(t.PeriodStart); err == nil {
-		return parsed
-	}
-	return t.PeriodStart
+	return t.PeriodStart.String()
 }
 
-// GetPeriodEnd returns the period end date (with date parsing if needed)
+// GetPeriodEnd returns the period end date as YYYY-MM-DD
 func (t *Transaction) GetPeriodEnd() string {
-	if parsed, err := parseLoanProDate(t.PeriodEnd); err == nil {
-		return parsed
-	}
-	return t.PeriodEnd
+	return t.PeriodEnd.String()
 }
 
 // GetType returns the transaction type
@@ -68,105 +59,79 @@ func (t *Transaction) IsFuture() bool {
 
 // GetPaymentAmount returns the total payment amount
 func (t *Transaction) GetPaymentAmount() string {
-	return t.PaymentAmount
+	return t.PaymentAmount.String()
 }
 
 // GetPaymentPrincipal returns the principal portion of the payment
 func (t *Transaction) GetPaymentPrincipal() string {
-	return t.PaymentPrincipal
+	return t.PaymentPrincipal.String()
 }
 
 // GetPaymentInterest returns the interest portion of the payment
 func (t *Transaction) GetPaymentInterest() string {
-	return t.PaymentInterest
+	return t.PaymentInterest.String()
 }
 
 // GetPaymentFees returns the fees portion of the payment
 func (t *Transaction) GetPaymentFees() string {
-	return t.PaymentFees
+	return t.PaymentFees.String()
 }
 
 // GetPaymentEscrow returns the escrow portion of the payment
 func (t *Transaction) GetPaymentEscrow() string {
-	return t.PaymentEscrow
+	return t.PaymentEscrow.String()
 }
 
 // GetPaymentDiscount returns the discount portion of the payment
 func (t *Transaction) GetPaymentDiscount() string {
-	return t.PaymentDiscount
+	return t.PaymentDiscount.String()
 }
 
 // GetChargeAmount returns the total charge amount
 func (t *Transaction) GetChargeAmount() string {
-	return t.ChargeAmount
+	return t.ChargeAmount.String()
 }
 
 // GetChargePrincipal returns the principal portion of the charge
 func (t *Transaction) GetChargePrincipal() string {
-	return t.ChargePrincipal
+	return t.ChargePrincipal.String()
 }
 
 // GetChargeInterest returns the interest portion of the charge
 func (t *Transaction) GetChargeInterest() string {
-	return t.ChargeInterest
+	return t.ChargeInterest.String()
 }
 
 // GetChargeFees returns the fees portion of the charge
 func (t *Transaction) GetChargeFees() string {
-	return t.ChargeFees
+	return t.ChargeFees.String()
 }
 
 // GetChargeEscrow returns the escrow portion of the charge
 func (t *Transaction) GetChargeEscrow() string {
-	return t.ChargeEscrow
+	return t.ChargeEscrow.String()
 }
 
 // GetChargeDiscount returns the discount portion of the charge
 func (t *Transaction) GetChargeDiscount() string {
-	return t.ChargeDiscount
+	return t.ChargeDiscount.String()
 }
 
 // GetPrincipalBalance returns the principal balance after this transaction
 func (t *Transaction) GetPrincipalBalance() string {
-	return t.PrincipalBalance
+	return t.PrincipalBalance.String()
 }
 
 // HasPaymentBreakdown checks if the transaction has payment breakdown data
 func (t *Transaction) HasPaymentBreakdown() bool {
-	// Check for non-empty and non-zero amounts
-	hasData := false
-	if t.PaymentPrincipal != "" && t.PaymentPrincipal != "0" && t.PaymentPrincipal != "0.00" {
-		hasData = true
-	}
-	if t.PaymentInterest != "" && t.PaymentInterest != "0" && t.PaymentInterest != "0.00" {
-		hasData = true
-	}
-	if t.PaymentFees != "" && t.PaymentFees != "0" && t.PaymentFees != "0.00" {
-		hasData = true
-	}
-	if t.PaymentEscrow != "" && t.PaymentEscrow != "0" && t.PaymentEscrow != "0.00" {
-		hasData = true
-	}
-	return hasData
+	return !t.PaymentPrincipal.IsZero() || !t.PaymentInterest.IsZero() ||
+		!t.PaymentFees.IsZero() || !t.PaymentEscrow.IsZero()
 }
 
 // HasChargeBreakdown checks if the transaction has charge breakdown data
 func (t *Transaction) HasChargeBreakdown() bool {
-	// Check for non-empty and non-zero amounts
-	hasData := false
-	if t.ChargePrincipal != "" && t.ChargePrincipal != "0" && t.ChargePrincipal != "0.00" {
-		hasData = true
-	}
-	if t.ChargeInterest != "" && t.ChargeInterest != "0" && t.ChargeInterest != "0.00" {
-		hasData = true
-	}
-	if t.ChargeFees != "" && t.ChargeFees != "0" && t.ChargeFees != "0.00" {
-		hasData = true
-	}
-	if t.ChargeEscrow != "" && t.ChargeEscrow != "0" && t.ChargeEscrow != "0.00" {
-		hasData = true
-	}
-	return hasData
+	return !t.ChargePrincipal.IsZero() || !t.ChargeInterest.IsZero() ||
+		!t.ChargeFees.IsZero() || !t.ChargeEscrow.IsZero()
 }
 
 // Legacy interface compatibility methods
@@ -175,13 +140,19 @@ func (t *Transaction) HasChargeBreakdown() bool {
 // GetAmount returns the total transaction amount (payment or charge)
 // For payments, returns payment amount; for charges, returns charge amount
 func (t *Transaction) GetAmount() string {
-	if t.PaymentAmount != "" && t.PaymentAmount != "0" && t.PaymentAmount != "0.00" {
+	return t.Amount().String()
+}
+
+// Amount returns the total transaction amount (payment or charge) as Money.
+// Transactions with neither return zero.
+func (t *Transaction) Amount() Money {
+	if !t.PaymentAmount.IsZero() {
 		return t.PaymentAmount
 	}
-	if t.ChargeAmount != "" && t.ChargeAmount != "0" && t.ChargeAmount != "0.00" {
+	if !t.ChargeAmount.IsZero() {
 		return t.ChargeAmount
 	}
-	return "0"
+	return NewMoneyFromCents(0)
 }
 
 // GetInfo returns transaction info/details
@@ -203,20 +174,20 @@ func (t *Transaction) GetStatus() string {
 
 // GetPrincipalAmount returns the principal amount from payment breakdown
 func (t *Transaction) GetPrincipalAmount() string {
-	return t.PaymentPrincipal
+	return t.PaymentPrincipal.String()
 }
 
 // GetInterestAmount returns the interest amount from payment breakdown
 func (t *Transaction) GetInterestAmount() string {
-	return t.PaymentInterest
+	return t.PaymentInterest.String()
 }
 
 // GetFeesAmount returns the fees amount from payment breakdown
 func (t *Transaction) GetFeesAmount() string {
-	return t.PaymentFees
+	return t.PaymentFees.String()
 }
 
 // GetEscrowAmount returns the escrow amount from payment breakdown
 func (t *Transaction) GetEscrowAmount() string {
-	return t.PaymentEscrow
+	return t.PaymentEscrow.String()
 }
@@ -1,10 +1,10 @@
 package loanpro
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"strconv"
+	"log/slog"
 )
 
 // TransactionOptions contains pagination and filtering options for transactions
@@ -34,82 +34,94 @@ func (c *Client) GetLoanTransactionsWithOptions(loanID string, opts *Transaction
 	return result.Transactions, nil
 }
 
-// GetLoanTransactionsWithMetadata retrieves transaction history with pagination metadata
+// GetLoanTransactionsWithMetadata retrieves transaction history with pagination metadata.
+// Without a limit the full history is fetched page by page, so results are not
+// truncated at the server's page size cap.
 func (c *Client) GetLoanTransactionsWithMetadata(loanID string, opts *TransactionOptions) (*TransactionResult, error) {
-	// Use the Transactions endpoint directly
-	endpoint := fmt.Sprintf("/public/api/1/odata.svc/Loans(%s)/Transactions", loanID)
-
-	// Build query parameters
-	params := make(map[string]string)
-	if opts != nil {
-		if opts.Limit > 0 {
-			params["$top"] = strconv.Itoa(opts.Limit)
+	if opts == nil || opts.Limit <= 0 {
+		offset := 0
+		if opts != nil {
+			offset = opts.Offset
 		}
-		if opts.Offset > 0 {
-			params["$skip"] = strconv.Itoa(opts.Offset)
+
+		transactions := []Transaction{}
+		for transaction, err := range c.iterateLoanTransactions(c.requestContext(), loanID, offset, DefaultPageSize) {
+			if err != nil {
+				return nil, err
+			}
+			transactions = append(transactions, transaction)
 		}
+
+		return &TransactionResult{
+			Transactions: transactions,
+			Total:        offset + len(transactions),
+			HasMore:      false,
+		}, nil
 	}
 
-	body, err := c.makeRequest(endpoint, params)
+	transactions, total, err := c.fetchTransactionsPage(c.requestContext(), loanID, opts.Offset, opts.Limit)
 	if err != nil {
 		return nil, err
 	}
+	if total < 0 {
+		total = opts.Offset + len(transactions)
+	}
+
+	return &TransactionResult{
+		Transactions: transactions,
+		Total:        total,
+		HasMore:      opts.Offset+len(transactions) < total,
+	}, nil
+}
+
+// fetchTransactionsPage retrieves a single page of transactions. The returned total
+// is -1 when the response doesn't report one.
+func (c *Client) fetchTransactionsPage(ctx context.Context, loanID string, offset, limit int) ([]Transaction, int, error) {
+	// Use the Transactions endpoint directly
+	query := NewODataQuery("Loans").Key(loanID).Nav("Transactions").Top(limit).Skip(offset)
+
+	body, err := c.getODataContext(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	var response ODataResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		fmt.Fprintf(os.Stderr, "[ERROR] Failed to parse GetLoanTransactions response: %v\nResponse body: %s\n", err, string(body))
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		slog.ErrorContext(ctx, "Failed to parse GetLoanTransactions response", "error", err, "body", string(body))
+		return nil, 0, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	// The response.D could be a single object or an array
 	// Try to marshal and unmarshal to handle both cases
 	transactionsData, err := json.Marshal(response.D)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[ERROR] Failed to marshal transaction data: %v\n", err)
-		return nil, fmt.Errorf("failed to marshal transaction data: %w", err)
+		slog.ErrorContext(ctx, "Failed to marshal transaction data", "error", err)
+		return nil, 0, fmt.Errorf("failed to marshal transaction data: %w", err)
 	}
 
 	// Try parsing as a wrapper first
 	var transactionsWrapper TransactionsWrapper
 	if err := json.Unmarshal(transactionsData, &transactionsWrapper); err == nil {
 		// Convert json.Number to int for Total
-		total := 0
+		total := -1
 		if totalInt, err := transactionsWrapper.Summary.Total.Int64(); err == nil {
 			total = int(totalInt)
 		}
 
-		fmt.Fprintf(os.Stderr, "[DEBUG] Successfully parsed wrapper. Results count: %d, Summary.Total: %d\n", len(transactionsWrapper.Results), total)
+		slog.DebugContext(ctx, "Parsed transactions wrapper", "results", len(transactionsWrapper.Results), "total", total)
 
 		// Always return the wrapper result if it parsed successfully
 		// (even if Results is empty, the wrapper structure was present)
-		result := &TransactionResult{
-			Transactions: transactionsWrapper.Results,
-			Total:        total,
-		}
-
-		// Calculate if there are more results
-		if opts != nil && opts.Limit > 0 {
-			result.HasMore = opts.Offset+len(transactionsWrapper.Results) < total
-		}
-
-		return result, nil
+		return transactionsWrapper.Results, total, nil
 	}
 
 	// Try parsing as a direct array
 	var transactionsArray []Transaction
 	if err := json.Unmarshal(transactionsData, &transactionsArray); err == nil {
-		return &TransactionResult{
-			Transactions: transactionsArray,
-			Total:        len(transactionsArray),
-			HasMore:      false,
-		}, nil
+		return transactionsArray, -1, nil
 	}
 
 	// If neither works, log and return empty
-	fmt.Fprintf(os.Stderr, "[DEBUG] Could not parse transactions, returning empty array. Data: %s\n", string(transactionsData))
-	return &TransactionResult{
-		Transactions: []Transaction{},
-		Total:        0,
-		HasMore:      false,
-	}, nil
+	slog.DebugContext(ctx, "Could not parse transactions, returning empty array", "data", string(transactionsData))
+	return []Transaction{}, 0, nil
 }
@@ -350,13 +350,13 @@ func TestTransactionUnmarshal(t *testing.T) {
 	if tx3.Type != "payment" {
 		t.Errorf("Expected Type 'payment', got %s", tx3.Type)
 	}
-	if tx3.PaymentAmount != "75111.98" {
+	if tx3.PaymentAmount.String() != "75111.98" {
 		t.Errorf("Expected PaymentAmount '75111.98', got %s", tx3.PaymentAmount)
 	}
-	if tx3.PaymentPrincipal != "75000" {
-		t.Errorf("Expected PaymentPrincipal '75000', got %s", tx3.PaymentPrincipal)
+	if tx3.PaymentPrincipal.String() != "75000.00" {
+		t.Errorf("Expected PaymentPrincipal '75000.00', got %s", tx3.PaymentPrincipal)
 	}
-	if tx3.PaymentInterest != "111.98" {
+	if tx3.PaymentInterest.String() != "111.98" {
 		t.Errorf("Expected PaymentInterest '111.98', got %s", tx3.PaymentInterest)
 	}
 	if string(tx3.PaymentID) != "2436" {
@@ -371,10 +371,10 @@ func TestTransactionUnmarshal(t *testing.T) {
 	if tx4.Type != "scheduledPayment" {
 		t.Errorf("Expected Type 'scheduledPayment', got %s", tx4.Type)
 	}
-	if tx4.ChargeAmount != "86.69" {
+	if tx4.ChargeAmount.String() != "86.69" {
 		t.Errorf("Expected ChargeAmount '86.69', got %s", tx4.ChargeAmount)
 	}
-	if tx4.ChargeInterest != "86.69" {
+	if tx4.ChargeInterest.String() != "86.69" {
 		t.Errorf("Expected ChargeInterest '86.69', got %s", tx4.ChargeInterest)
 	}
 	if string(tx4.Future) != "1" {
@@ -386,11 +386,11 @@ func TestTransactionUnmarshal(t *testing.T) {
 	if tx6.Type != "fee" {
 		t.Errorf("Expected Type 'fee', got %s", tx6.Type)
 	}
-	if tx6.ChargeAmount != "1000" {
-		t.Errorf("Expected ChargeAmount '1000', got %s", tx6.ChargeAmount)
+	if tx6.ChargeAmount.String() != "1000.00" {
+		t.Errorf("Expected ChargeAmount '1000.00', got %s", tx6.ChargeAmount)
 	}
-	if tx6.ChargeFees != "1000" {
-		t.Errorf("Expected ChargeFees '1000', got %s", tx6.ChargeFees)
+	if tx6.ChargeFees.String() != "1000.00" {
+		t.Errorf("Expected ChargeFees '1000.00', got %s", tx6.ChargeFees)
 	}
 	if string(tx6.Period) != "12" {
 		t.Errorf("Expected Period '12', got %s", string(tx6.Period))
@@ -403,17 +403,17 @@ func TestTransactionMethods(t *testing.T) {
 		ID:               json.Number("247770"),
 		TxID:             "630-26-pay24360",
 		EntityID:         json.Number("630"),
-		Date:             "/Date(1764892800)/",
-		PeriodStart:      "/Date(1764547200)/",
-		PeriodEnd:        "/Date(1767139200)/",
+		Date:             MustParseDate("/Date(1764892800)/"),
+		PeriodStart:      MustParseDate("/Date(1764547200)/"),
+		PeriodEnd:        MustParseDate("/Date(1767139200)/"),
 		Title:            "Payment: Payoff",
 		Type:             "payment",
 		InfoOnly:         json.Number("0"),
-		PaymentAmount:    "75111.98",
-		PaymentPrincipal: "75000",
-		PaymentInterest:  "111.98",
-		PaymentFees:      "0",
-		PaymentEscrow:    "0",
+		PaymentAmount:    MustParseMoney("75111.98"),
+		PaymentPrincipal: MustParseMoney("75000"),
+		PaymentInterest:  MustParseMoney("111.98"),
+		PaymentFees:      MustParseMoney("0"),
+		PaymentEscrow:    MustParseMoney("0"),
 		Future:           json.Number("0"),
 	}
 
@@ -444,8 +444,8 @@ func TestTransactionMethods(t *testing.T) {
 	if paymentTx.GetPaymentAmount() != "75111.98" {
 		t.Errorf("Expected PaymentAmount '75111.98', got %s", paymentTx.GetPaymentAmount())
 	}
-	if paymentTx.GetPaymentPrincipal() != "75000" {
-		t.Errorf("Expected PaymentPrincipal '75000', got %s", paymentTx.GetPaymentPrincipal())
+	if paymentTx.GetPaymentPrincipal() != "75000.00" {
+		t.Errorf("Expected PaymentPrincipal '75000.00', got %s", paymentTx.GetPaymentPrincipal())
 	}
 	if paymentTx.GetPaymentInterest() != "111.98" {
 		t.Errorf("Expected PaymentInterest '111.98', got %s", paymentTx.GetPaymentInterest())
@@ -490,11 +490,11 @@ func TestTransactionChargeBreakdown(t *testing.T) {
 	chargeTx := Transaction{
 		ID:              json.Number("247539"),
 		Type:            "scheduledPayment",
-		ChargeAmount:    "86.69",
-		ChargeInterest:  "86.69",
-		ChargePrincipal: "0",
-		ChargeFees:      "0",
-		ChargeEscrow:    "0",
+		ChargeAmount:    MustParseMoney("86.69"),
+		ChargeInterest:  MustParseMoney("86.69"),
+		ChargePrincipal: MustParseMoney("0"),
+		ChargeFees:      MustParseMoney("0"),
+		ChargeEscrow:    MustParseMoney("0"),
 		Future:          json.Number("1"),
 	}
 
@@ -523,21 +523,21 @@ func TestTransactionFee(t *testing.T) {
 		ID:              json.Number("247599"),
 		Type:            "fee",
 		Title:           "Fee: Extension fee",
-		ChargeAmount:    "1000",
-		ChargeFees:      "1000",
-		ChargeInterest:  "0",
-		ChargePrincipal: "0",
+		ChargeAmount:    MustParseMoney("1000"),
+		ChargeFees:      MustParseMoney("1000"),
+		ChargeInterest:  MustParseMoney("0"),
+		ChargePrincipal: MustParseMoney("0"),
 		Period:          json.Number("12"),
 	}
 
 	if feeTx.GetType() != "fee" {
 		t.Errorf("Expected Type 'fee', got %s", feeTx.GetType())
 	}
-	if feeTx.GetChargeAmount() != "1000" {
-		t.Errorf("Expected ChargeAmount '1000', got %s", feeTx.GetChargeAmount())
+	if feeTx.GetChargeAmount() != "1000.00" {
+		t.Errorf("Expected ChargeAmount '1000.00', got %s", feeTx.GetChargeAmount())
 	}
-	if feeTx.GetChargeFees() != "1000" {
-		t.Errorf("Expected ChargeFees '1000', got %s", feeTx.GetChargeFees())
+	if feeTx.GetChargeFees() != "1000.00" {
+		t.Errorf("Expected ChargeFees '1000.00', got %s", feeTx.GetChargeFees())
 	}
 	if !feeTx.HasChargeBreakdown() {
 		t.Error("Expected HasChargeBreakdown to be true for fee transaction")
@@ -547,12 +547,12 @@ func TestTransactionFee(t *testing.T) {
 func TestTransactionZeroAmounts(t *testing.T) {
 	// Create a transaction with all zero amounts
 	zeroTx := Transaction{
-		PaymentAmount:    "0",
-		PaymentPrincipal: "0.00",
-		PaymentInterest:  "0",
-		PaymentFees:      "0",
-		ChargeAmount:     "0.00",
-		ChargeInterest:   "0",
+		PaymentAmount:    MustParseMoney("0"),
+		PaymentPrincipal: MustParseMoney("0.00"),
+		PaymentInterest:  MustParseMoney("0"),
+		PaymentFees:      MustParseMoney("0"),
+		ChargeAmount:     MustParseMoney("0.00"),
+		ChargeInterest:   MustParseMoney("0"),
 	}
 
 	// Test that zero amounts don't trigger breakdown flags
@@ -1,12 +1,6 @@
 package loanpro
 
-import (
-	"encoding/json"
-	"fmt"
-	"regexp"
-	"strconv"
-	"time"
-)
+import "encoding/json"
 
 // LoanSettings represents loan settings data
 type LoanSettings struct {
@@ -21,12 +15,12 @@ type LoanSettings struct {
 type LoanSetup struct {
 	ID               json.Number `json:"id"`
 	LoanID           json.Number `json:"loanId"`
-	ContractDate     string      `json:"contractDate"`
+	ContractDate     Date        `json:"contractDate"`
 	LoanType         string      `json:"loanType"`
 	LoanClass        string      `json:"loanClass"`
-	LoanAmount       string      `json:"loanAmount"`
-	Payment          string      `json:"payment"`
-	FirstPaymentDate string      `json:"firstPaymentDate"`
+	LoanAmount       Money       `json:"loanAmount"`
+	Payment          Money       `json:"payment"`
+	FirstPaymentDate Date        `json:"firstPaymentDate"`
 	LoanRate         string      `json:"loanRate"`
 	LoanTerm         string      `json:"loanTerm"`
 }
@@ -48,13 +42,13 @@ type CustomersWrapper struct {
 type StatusArchiveEntry struct {
 	ID                json.Number `json:"id"`
 	LoanID            json.Number `json:"loanId"`
-	Date              string      `json:"date"`
-	PrincipalBalance  string      `json:"principalBalance"`
-	Payoff            string      `json:"payoff"`
-	AmountDue         string      `json:"amountDue"`
+	Date              Date        `json:"date"`
+	PrincipalBalance  Money       `json:"principalBalance"`
+	Payoff            Money       `json:"payoff"`
+	AmountDue         Money       `json:"amountDue"`
 	DaysPastDue       json.Number `json:"daysPastDue"`
-	NextPaymentDate   string      `json:"nextPaymentDate"`
-	NextPaymentAmount string      `json:"nextPaymentAmount"`
+	NextPaymentDate   Date        `json:"nextPaymentDate"`
+	NextPaymentAmount Money       `json:"nextPaymentAmount"`
 	LoanStatusText    string      `json:"loanStatusText"`
 }
 
@@ -70,38 +64,47 @@ type Loan struct {
 	Title         string                `json:"title"`
 	Active        json.Number           `json:"active"`
 	Archived      json.Number           `json:"archived"`
-	Created       string                `json:"created"`
+	Created       DateTime              `json:"created"`
 	LoanSettings  *LoanSettings         `json:"LoanSettings,omitempty"`
 	LoanSetup     *LoanSetup            `json:"LoanSetup,omitempty"`
 	Customers     *CustomersWrapper     `json:"Customers,omitempty"`
 	StatusArchive *StatusArchiveWrapper `json:"StatusArchive,omitempty"`
+	Portfolios    *PortfoliosWrapper    `json:"Portfolios,omitempty"`
+	// Expanded custom field values and their resolved name→value map
+	CustomFieldValues *CustomFieldValuesWrapper `json:"CustomFieldValues,omitempty"`
+	CustomFields      map[string]any            `json:"-"`
 	// Fields that appear in search results but not in individual loan retrieval
 	PrimaryCustomerName string      `json:"primaryCustomerName,omitempty"`
 	LoanStatusText      string      `json:"loanStatusText,omitempty"`
-	PrincipalBalance    string      `json:"principalBalance,omitempty"`
+	PrincipalBalance    Money       `json:"principalBalance"`
 	DaysPastDue         json.Number `json:"daysPastDue,omitempty"`
-	NextPaymentAmount   string      `json:"nextPaymentAmount,omitempty"`
-	NextPaymentDate     string      `json:"nextPaymentDate,omitempty"`
+	NextPaymentAmount   Money       `json:"nextPaymentAmount"`
+	NextPaymentDate     Date        `json:"nextPaymentDate"`
 	// Raw customers array from search results (lowercase)
 	CustomersArray []LoanCustomer `json:"customers,omitempty"`
+	// Portfolio IDs from search results (lowercase)
+	PortfolioIDs []json.Number `json:"portfolios,omitempty"`
 }
 
 // Customer represents customer data
 type Customer struct {
-	ID        int    `json:"id"`
-	FirstName string `json:"firstName"`
-	LastName  string `json:"lastName"`
-	Email     string `json:"email"`
-	Phone     string `json:"phone"`
-	CreatedAt string `json:"createdAt"`
+	ID        int      `json:"id"`
+	FirstName string   `json:"firstName"`
+	LastName  string   `json:"lastName"`
+	Email     string   `json:"email"`
+	Phone     string   `json:"phone"`
+	CreatedAt DateTime `json:"createdAt"`
+	// Expanded custom field values and their resolved name→value map
+	CustomFieldValues *CustomFieldValuesWrapper `json:"CustomFieldValues,omitempty"`
+	CustomFields      map[string]any            `json:"-"`
 }
 
 // Payment represents payment data
 type Payment struct {
 	ID              json.Number `json:"id"`
 	LoanID          json.Number `json:"loanId"`
-	Date            string      `json:"date"`
-	Amount          string      `json:"amount"`
+	Date            Date        `json:"date"`
+	Amount          Money       `json:"amount"`
 	PaymentTypeID   json.Number `json:"paymentTypeId"`
 	PaymentMethodID json.Number `json:"paymentMethodId"`
 	Info            string      `json:"info"`
@@ -120,30 +123,30 @@ type Transaction struct {
 	EntityType             string      `json:"entityType"`
 	EntityID               json.Number `json:"entityId"`
 	ModID                  json.Number `json:"modId"`
-	Date                   string      `json:"date"`
+	Date                   Date        `json:"date"`
 	Period                 json.Number `json:"period"`
-	PeriodStart            string      `json:"periodStart"`
-	PeriodEnd              string      `json:"periodEnd"`
+	PeriodStart            Date        `json:"periodStart"`
+	PeriodEnd              Date        `json:"periodEnd"`
 	Title                  string      `json:"title"`
 	Type                   string      `json:"type"`
 	InfoOnly               json.Number `json:"infoOnly"`
 	InfoDetails            string      `json:"infoDetails"`
 	PaymentID              json.Number `json:"paymentId"`
 	PaymentDisplayID       json.Number `json:"paymentDisplayId"`
-	PaymentAmount          string      `json:"paymentAmount"`
-	PaymentInterest        string      `json:"paymentInterest"`
-	PaymentPrincipal       string      `json:"paymentPrincipal"`
-	PaymentDiscount        string      `json:"paymentDiscount"`
-	PaymentFees            string      `json:"paymentFees"`
+	PaymentAmount          Money       `json:"paymentAmount"`
+	PaymentInterest        Money       `json:"paymentInterest"`
+	PaymentPrincipal       Money       `json:"paymentPrincipal"`
+	PaymentDiscount        Money       `json:"paymentDiscount"`
+	PaymentFees            Money       `json:"paymentFees"`
 	FeesPaidDetails        string      `json:"feesPaidDetails"`
-	PaymentEscrow          string      `json:"paymentEscrow"`
+	PaymentEscrow          Money       `json:"paymentEscrow"`
 	PaymentEscrowBreakdown string      `json:"paymentEscrowBreakdown"`
-	ChargeAmount           string      `json:"chargeAmount"`
-	ChargeInterest         string      `json:"chargeInterest"`
-	ChargePrincipal        string      `json:"chargePrincipal"`
-	ChargeDiscount         string      `json:"chargeDiscount"`
-	ChargeFees             string      `json:"chargeFees"`
-	ChargeEscrow           string      `json:"chargeEscrow"`
+	ChargeAmount           Money       `json:"chargeAmount"`
+	ChargeInterest         Money       `json:"chargeInterest"`
+	ChargePrincipal        Money       `json:"chargePrincipal"`
+	ChargeDiscount         Money       `json:"chargeDiscount"`
+	ChargeFees             Money       `json:"chargeFees"`
+	ChargeEscrow           Money       `json:"chargeEscrow"`
 	ChargeEscrowBreakdown  string      `json:"chargeEscrowBreakdown"`
 	Future                 json.Number `json:"future"`
 	PrincipalOnly          json.Number `json:"principalOnly"`
@@ -152,8 +155,8 @@ type Transaction struct {
 	ChargeOff              json.Number `json:"chargeOff"`
 	PaymentType            json.Number `json:"paymentType"`
 	AdbDays                json.Number `json:"adbDays"`
-	Adb                    string      `json:"adb"`
-	PrincipalBalance       string      `json:"principalBalance"`
+	Adb                    Money       `json:"adb"`
+	PrincipalBalance       Money       `json:"principalBalance"`
 	DisplayOrder           string      `json:"displayOrder"`
 }
 
@@ -191,54 +194,3 @@ type CustomerSearchResponse struct {
 		} `json:"summary"`
 	} `json:"d"`
 }
-
-// Utility functions for date parsing
-func parseLoanProDate(dateStr string) (string, error) {
-	if dateStr == "" {
-		return "", nil
-	}
-
-	// Match the LoanPro date format: /Date(1234567890)/
-	re := regexp.MustCompile(`/Date\((\d+)\)/`)
-	matches := re.FindStringSubmatch(dateStr)
-
-	if len(matches) != 2 {
-		// If it doesn't match the Unix format, assume it's already in YYYY-MM-DD format
-		return dateStr, nil
-	}
-
-	// Parse the Unix timestamp (in seconds)
-	timestamp, err := strconv.ParseInt(matches[1], 10, 64)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse timestamp: %w", err)
-	}
-
-	// Convert to time and format as YYYY-MM-DD
-	t := time.Unix(timestamp, 0).UTC()
-	return t.Format("2006-01-02"), nil
-}
-
-func parseLoanProDateTime(dateStr string) (string, error) {
-	if dateStr == "" {
-		return "", nil
-	}
-
-	// Match the LoanPro date format: /Date(1234567890)/
-	re := regexp.MustCompile(`/Date\((\d+)\)/`)
-	matches := re.FindStringSubmatch(dateStr)
-
-	if len(matches) != 2 {
-		// If it doesn't match the Unix format, assume it's already formatted
-		return dateStr, nil
-	}
-
-	// Parse the Unix timestamp (in seconds)
-	timestamp, err := strconv.ParseInt(matches[1], 10, 64)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse timestamp: %w", err)
-	}
-
-	// Convert to time and format as YYYY-MM-DD HH:MM:SS UTC
-	t := time.Unix(timestamp, 0).UTC()
-	return t.Format("2006-01-02 15:04:05 UTC"), nil
-}
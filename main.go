@@ -1,15 +1,29 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"log/slog"
+	"maps"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	_ "time/tzdata" // embed zone data so LOANPRO_TIMEZONE works in minimal containers
 
+	"loanpro-mcp-server/audit"
+	"loanpro-mcp-server/auth"
 	"loanpro-mcp-server/loanpro"
+	"loanpro-mcp-server/loanpro/fake"
+	"loanpro-mcp-server/redact"
+	"loanpro-mcp-server/requestid"
 	"loanpro-mcp-server/tools"
 	"loanpro-mcp-server/transport"
 
@@ -22,6 +36,9 @@ func configureSlog() {
 	// Default to INFO level
 	level := slog.LevelInfo
 
+	// Invalid settings are logged once the logger is set up
+	var invalidLevel, invalidFormat string
+
 	// Parse LOG_LEVEL environment variable
 	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
 		switch strings.ToUpper(logLevel) {
@@ -34,7 +51,7 @@ func configureSlog() {
 		case "ERROR":
 			level = slog.LevelError
 		default:
-			fmt.Fprintf(os.Stderr, "Invalid LOG_LEVEL '%s', using INFO\n", logLevel)
+			invalidLevel = logLevel
 		}
 	}
 
@@ -52,28 +69,111 @@ func configureSlog() {
 	case "TEXT", "":
 		handler = slog.NewTextHandler(os.Stderr, opts)
 	default:
-		fmt.Fprintf(os.Stderr, "Invalid LOG_FORMAT '%s', using TEXT\n", format)
+		invalidFormat = format
 		handler = slog.NewTextHandler(os.Stderr, opts)
+		format = "TEXT"
 	}
 
-	// Set the default logger
-	slog.SetDefault(slog.New(handler))
+	// Set the default logger, tagging records with their request ID and masking
+	// personal data in every record
+	slog.SetDefault(slog.New(requestid.NewHandler(redact.NewHandler(handler, nil))))
+
+	if invalidLevel != "" {
+		slog.Error("Invalid LOG_LEVEL, using INFO", "value", invalidLevel)
+	}
+	if invalidFormat != "" {
+		slog.Error("Invalid LOG_FORMAT, using TEXT", "value", invalidFormat)
+	}
 
 	slog.Info("Logger configured",
 		"level", level.String(),
 		"format", strings.ToLower(format))
 }
 
+// errorCodeForbidden is the JSON-RPC error code for tool calls the caller's
+// credential or role doesn't allow
+const errorCodeForbidden = tools.ErrorCodePermissionDenied
+
 // MCPServer implements the MCP protocol handler
 type MCPServer struct {
-	toolManager *tools.Manager
+	tenants    *TenantRegistry
+	toolScopes map[string]string // Scope required to call each tool
+	policy     *PolicyFile       // Role-based access policy, nil when not configured
+	auditLog   *audit.Log        // Record of tool calls, nil when auditing is off
 }
 
-// NewMCPServer creates a new MCP server
+// NewMCPServer creates a new MCP server for a single LoanPro tenant
 func NewMCPServer(loanProClient *loanpro.Client) *MCPServer {
-	return &MCPServer{
-		toolManager: tools.NewManager(&ClientAdapter{client: loanProClient}),
+	return NewMCPServerWithTenants(NewTenantRegistry("", NewTenant(defaultTenantName, loanProClient)))
+}
+
+// NewMCPServerWithTenants creates a new MCP server serving several LoanPro tenants
+func NewMCPServerWithTenants(tenants *TenantRegistry) *MCPServer {
+	return &MCPServer{tenants: tenants, toolScopes: defaultToolScopes}
+}
+
+// SetToolScopes replaces the mapping from tools to the scope needed to call them
+func (s *MCPServer) SetToolScopes(toolScopes map[string]string) {
+	s.toolScopes = toolScopes
+}
+
+// SetPolicy restricts authenticated callers to what their role allows
+func (s *MCPServer) SetPolicy(policy *PolicyFile) {
+	s.policy = policy
+}
+
+// authorizeTool checks that the caller's credential grants the scope a tool needs.
+// Unauthenticated callers, e.g. over stdio, and credentials without scopes may call
+// any tool. Tools missing from the mapping are refused to scoped credentials.
+func (s *MCPServer) authorizeTool(ctx context.Context, toolName string) error {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil || principal.Scopes == nil {
+		return nil
 	}
+	scope, ok := s.toolScopes[toolName]
+	if !ok {
+		return fmt.Errorf("insufficient_scope: tool %s is not available to scoped credentials", toolName)
+	}
+	if !principal.HasScope(scope) {
+		return fmt.Errorf("insufficient_scope: tool %s requires scope %s", toolName, scope)
+	}
+	return nil
+}
+
+// SetCustomFields configures which custom fields every tenant includes in loan and
+// customer output
+func (s *MCPServer) SetCustomFields(names []string) {
+	for _, name := range s.tenants.Names() {
+		tenant, _ := s.tenants.Get(name)
+		tenant.toolManager.SetCustomFields(names)
+	}
+}
+
+// HealthDetails reports response cache counters, outbound queue depth and circuit
+// breaker state for the health endpoint, per tenant when there are several. The
+// server reports itself degraded while any breaker is not closed.
+func (s *MCPServer) HealthDetails() map[string]any {
+	details := map[string]any{}
+	tenants := map[string]any{}
+	degraded := false
+	for _, name := range s.tenants.Names() {
+		tenant, _ := s.tenants.Get(name)
+		tenantDetails := tenant.HealthDetails()
+		tenants[name] = tenantDetails
+		if tenant.client.BreakerStats().State != loanpro.BreakerClosed {
+			degraded = true
+		}
+		if len(s.tenants.Names()) == 1 {
+			details = tenantDetails
+		}
+	}
+	if len(tenants) > 1 {
+		details["tenants"] = tenants
+	}
+	if degraded {
+		details["status"] = "degraded"
+	}
+	return details
 }
 
 // ClientAdapter adapts the loanpro.Client to implement the tools.LoanProClient interface
@@ -81,6 +181,26 @@ type ClientAdapter struct {
 	client *loanpro.Client
 }
 
+// adaptedError keeps a LoanPro error and its message while also matching the tools
+// error the tools check for, e.g. to report invalid arguments
+type adaptedError struct {
+	err  error
+	kind error
+}
+
+func (e *adaptedError) Error() string   { return e.err.Error() }
+func (e *adaptedError) Unwrap() []error { return []error{e.err, e.kind} }
+
+// Fresh returns an adapter whose reads bypass the response cache
+func (ca *ClientAdapter) Fresh() tools.LoanProClient {
+	return &ClientAdapter{client: ca.client.Fresh()}
+}
+
+// WithContext returns an adapter whose requests are made with ctx
+func (ca *ClientAdapter) WithContext(ctx context.Context) tools.LoanProClient {
+	return &ClientAdapter{client: ca.client.WithContext(ctx)}
+}
+
 func (ca *ClientAdapter) GetLoan(id string) (tools.Loan, error) {
 	loan, err := ca.client.GetLoan(id)
 	if err != nil {
@@ -102,6 +222,48 @@ func (ca *ClientAdapter) SearchLoans(searchTerm, status string, limit int) ([]to
 	return result, nil
 }
 
+func (ca *ClientAdapter) SearchLoansWithMetadata(opts *tools.LoanSearchOptions) (*tools.LoanSearchResult, error) {
+	// Convert tools.LoanSearchOptions to loanpro.LoanSearchOptions
+	var loanProOpts *loanpro.LoanSearchOptions
+	if opts != nil {
+		loanProOpts = &loanpro.LoanSearchOptions{
+			SearchTerm:     opts.SearchTerm,
+			Status:         opts.Status,
+			SubStatus:      opts.SubStatus,
+			PortfolioID:    opts.PortfolioID,
+			SubPortfolioID: opts.SubPortfolioID,
+			MinBalance:     opts.MinBalance,
+			MaxBalance:     opts.MaxBalance,
+			MinDaysPastDue: opts.MinDaysPastDue,
+			MaxDaysPastDue: opts.MaxDaysPastDue,
+			CreatedAfter:   opts.CreatedAfter,
+			CreatedBefore:  opts.CreatedBefore,
+			SortBy:         opts.SortBy,
+			SortDescending: opts.SortDescending,
+			Limit:          opts.Limit,
+			Cursor:         opts.Cursor,
+		}
+	}
+
+	searchResult, err := ca.client.SearchLoansWithMetadata(loanProOpts)
+	if errors.Is(err, loanpro.ErrInvalidCursor) {
+		return nil, &adaptedError{err: err, kind: tools.ErrInvalidCursor}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	loans := make([]tools.Loan, len(searchResult.Loans))
+	for i, loan := range searchResult.Loans {
+		loans[i] = &loan
+	}
+	return &tools.LoanSearchResult{
+		Loans:      loans,
+		TotalHits:  searchResult.TotalHits,
+		NextCursor: searchResult.NextCursor,
+	}, nil
+}
+
 func (ca *ClientAdapter) GetCustomer(id string) (tools.Customer, error) {
 	customer, err := ca.client.GetCustomer(id)
 	if err != nil {
@@ -123,6 +285,39 @@ func (ca *ClientAdapter) SearchCustomers(searchTerm string, limit int) ([]tools.
 	return result, nil
 }
 
+func (ca *ClientAdapter) SearchCustomersWithMetadata(opts *tools.CustomerSearchOptions) (*tools.CustomerSearchResult, error) {
+	// Convert tools.CustomerSearchOptions to loanpro.CustomerSearchOptions
+	var loanProOpts *loanpro.CustomerSearchOptions
+	if opts != nil {
+		loanProOpts = &loanpro.CustomerSearchOptions{
+			Name:      opts.Name,
+			Email:     opts.Email,
+			Phone:     opts.Phone,
+			SSNLast4:  opts.SSNLast4,
+			BirthDate: opts.BirthDate,
+			From:      opts.Offset,
+			Size:      opts.Limit,
+		}
+	}
+
+	searchResult, err := ca.client.SearchCustomersWithMetadata(loanProOpts)
+	if errors.Is(err, loanpro.ErrInvalidSearch) {
+		return nil, &adaptedError{err: err, kind: tools.ErrInvalidSearch}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	customers := make([]tools.Customer, len(searchResult.Customers))
+	for i, customer := range searchResult.Customers {
+		customers[i] = &customer
+	}
+	return &tools.CustomerSearchResult{
+		Customers: customers,
+		TotalHits: searchResult.TotalHits,
+	}, nil
+}
+
 func (ca *ClientAdapter) GetLoanPayments(loanID string) ([]tools.Payment, error) {
 	payments, err := ca.client.GetLoanPayments(loanID)
 	if err != nil {
@@ -162,17 +357,54 @@ func (ca *ClientAdapter) GetLoanTransactionsWithOptions(loanID string, opts *too
 	return result, nil
 }
 
+func (ca *ClientAdapter) GetPortfolios() ([]tools.Portfolio, error) {
+	portfolios, err := ca.client.GetPortfolios()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]tools.Portfolio, len(portfolios))
+	for i, portfolio := range portfolios {
+		result[i] = &PortfolioAdapter{portfolio: &portfolio}
+	}
+	return result, nil
+}
+
+// PortfolioAdapter adapts loanpro.Portfolio to implement the tools.Portfolio interface
+type PortfolioAdapter struct {
+	portfolio *loanpro.Portfolio
+}
+
+func (pa *PortfolioAdapter) GetID() string    { return pa.portfolio.GetID() }
+func (pa *PortfolioAdapter) GetTitle() string { return pa.portfolio.GetTitle() }
+func (pa *PortfolioAdapter) IsActive() bool   { return pa.portfolio.IsActive() }
+
+func (pa *PortfolioAdapter) GetSubPortfolios() []tools.SubPortfolio {
+	subPortfolios := pa.portfolio.GetSubPortfolios()
+	result := make([]tools.SubPortfolio, len(subPortfolios))
+	for i, sub := range subPortfolios {
+		result[i] = &sub
+	}
+	return result
+}
+
 // HandleMCPRequest handles MCP protocol requests
 func (s *MCPServer) HandleMCPRequest(req transport.MCPRequest) transport.MCPResponse {
+	return s.HandleMCPRequestContext(context.Background(), req)
+}
+
+// HandleMCPRequestContext handles MCP protocol requests, using the tenant pinned to
+// ctx if any
+func (s *MCPServer) HandleMCPRequestContext(ctx context.Context, req transport.MCPRequest) transport.MCPResponse {
 	switch req.Method {
 	case "initialize":
-		slog.Info("Processing initialize request", "method", req.Method)
+		slog.InfoContext(ctx, "Processing initialize request", "method", req.Method)
 
 		// Extract client's protocol version from params
 		clientProtocolVersion := "2024-11-05" // fallback default
 		if params, ok := req.Params["protocolVersion"].(string); ok {
 			clientProtocolVersion = params
-			slog.Info("Client protocol version", "version", clientProtocolVersion)
+			slog.InfoContext(ctx, "Client protocol version", "version", clientProtocolVersion)
 		}
 
 		response := transport.MCPResponse{
@@ -190,16 +422,16 @@ func (s *MCPServer) HandleMCPRequest(req transport.MCPRequest) transport.MCPResp
 			ID: req.ID,
 		}
 
-		slog.Info("Responding with protocol version", "version", clientProtocolVersion)
+		slog.InfoContext(ctx, "Responding with protocol version", "version", clientProtocolVersion)
 		return response
 
 	case "initialized":
-		slog.Debug("Received initialized notification (legacy)")
+		slog.DebugContext(ctx, "Received initialized notification (legacy)")
 		// This is a notification, no response needed
 		return transport.MCPResponse{} // Empty response indicates no reply
 
 	case "notifications/initialized": // Changed from "initialized"
-		slog.Debug("Received initialized notification")
+		slog.DebugContext(ctx, "Received initialized notification")
 		return transport.MCPResponse{} // No response for notifications
 
 	case "resources/list":
@@ -221,7 +453,28 @@ func (s *MCPServer) HandleMCPRequest(req transport.MCPRequest) transport.MCPResp
 		}
 
 	case "tools/list":
-		toolsList := s.toolManager.GetAllTools()
+		ctx, role, err := s.applyPolicy(ctx)
+		if err != nil {
+			return transport.MCPResponse{
+				JSONRPC: "2.0",
+				Error:   &transport.MCPError{Code: errorCodeForbidden, Message: err.Error()},
+				ID:      req.ID,
+			}
+		}
+
+		// List only the tools the caller's scopes and role allow
+		toolsList := []tools.Tool{}
+		for _, tool := range tools.NewManager(nil).ListTools(ctx) {
+			if s.authorizeTool(ctx, tool.Name) == nil {
+				toolsList = append(toolsList, tool)
+			}
+		}
+		// Advertise the tenant argument when the caller has a choice of tenants
+		if len(s.tenants.Names()) > 1 && tenantFromContext(ctx) == "" {
+			for _, tool := range toolsList {
+				addTenantProperty(tool, role.allowedTenants(s.tenants.Names()))
+			}
+		}
 		return transport.MCPResponse{
 			JSONRPC: "2.0",
 			Result: map[string]any{
@@ -231,25 +484,10 @@ func (s *MCPServer) HandleMCPRequest(req transport.MCPRequest) transport.MCPResp
 		}
 
 	case "tools/call":
-		toolName := req.Params["name"].(string)
-		arguments := req.Params["arguments"].(map[string]any)
-
-		response := s.toolManager.ExecuteTool(toolName, arguments)
-		// Convert tools.MCPResponse to transport.MCPResponse
-		return transport.MCPResponse{
-			JSONRPC: response.JSONRPC,
-			Result:  response.Result,
-			Error: func() *transport.MCPError {
-				if response.Error != nil {
-					return &transport.MCPError{
-						Code:    response.Error.Code,
-						Message: response.Error.Message,
-					}
-				}
-				return nil
-			}(),
-			ID: req.ID,
-		}
+		start := time.Now()
+		response := s.callTool(ctx, req)
+		observeToolCall(req, response, time.Since(start))
+		return response
 
 	default:
 		return transport.MCPResponse{
@@ -260,23 +498,401 @@ func (s *MCPServer) HandleMCPRequest(req transport.MCPRequest) transport.MCPResp
 	}
 }
 
+// callTool authorizes a tools/call request and runs it on the caller's tenant
+func (s *MCPServer) callTool(ctx context.Context, req transport.MCPRequest) transport.MCPResponse {
+	toolName, ok := req.Params["name"].(string)
+	if !ok || toolName == "" {
+		return s.rejectTool(ctx, req, "", -32602, errors.New("invalid params: name is required and must be a string"))
+	}
+	// Arguments are optional, but must be an object when given
+	arguments, ok := req.Params["arguments"].(map[string]any)
+	if !ok {
+		if req.Params["arguments"] != nil {
+			return s.rejectTool(ctx, req, "", -32602, errors.New("invalid params: arguments must be an object"))
+		}
+		arguments = map[string]any{}
+	}
+
+	tenantName, _ := arguments["tenant"].(string)
+	if err := s.authorizeTool(ctx, toolName); err != nil {
+		return s.rejectTool(ctx, req, tenantName, errorCodeForbidden, err)
+	}
+	ctx, role, err := s.applyPolicy(ctx)
+	if err != nil {
+		return s.rejectTool(ctx, req, tenantName, errorCodeForbidden, err)
+	}
+
+	tenant, err := s.tenants.Resolve(ctx, tenantName)
+	if err != nil {
+		return s.rejectTool(ctx, req, tenantName, -32602, err)
+	}
+	if !role.allowsTenant(tenant.Name) {
+		err := fmt.Errorf("%w: tenant %s is not allowed for this role", ErrPermissionDenied, tenant.Name)
+		return s.rejectTool(ctx, req, tenant.Name, errorCodeForbidden, err)
+	}
+
+	slog.DebugContext(ctx, "Executing tool", "tool", toolName, "tenant", tenant.Name, "principal", principalName(ctx))
+	tenant.toolCalls.Add(1)
+	response := tenant.toolManager.ExecuteToolContext(ctx, toolName, arguments)
+	if response.Error != nil {
+		tenant.toolErrors.Add(1)
+	}
+	// Convert tools.MCPResponse to transport.MCPResponse
+	return transport.MCPResponse{
+		JSONRPC: response.JSONRPC,
+		Result:  response.Result,
+		Error: func() *transport.MCPError {
+			if response.Error != nil {
+				return &transport.MCPError{
+					Code:    response.Error.Code,
+					Message: response.Error.Message,
+				}
+			}
+			return nil
+		}(),
+		ID: req.ID,
+	}
+}
+
+// principalName returns the subject of the authenticated caller for logs, or an
+// empty string for unauthenticated requests
+func principalName(ctx context.Context) string {
+	if principal := auth.PrincipalFromContext(ctx); principal != nil {
+		return principal.Subject
+	}
+	return ""
+}
+
+// loadCacheConfig reads response cache settings from the environment. Setting
+// LOANPRO_CACHE_TTL to 0 disables the cache.
+func loadCacheConfig() (loanpro.CacheConfig, bool) {
+	config := loanpro.CacheConfig{}
+
+	if ttl := os.Getenv("LOANPRO_CACHE_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			slog.Error("Invalid LOANPRO_CACHE_TTL, using default", "value", ttl, "error", err)
+		} else if d <= 0 {
+			return config, false
+		} else {
+			config.DefaultTTL = d
+		}
+	}
+
+	if maxEntries := os.Getenv("LOANPRO_CACHE_MAX_ENTRIES"); maxEntries != "" {
+		n, err := strconv.Atoi(maxEntries)
+		if err != nil || n <= 0 {
+			slog.Error("Invalid LOANPRO_CACHE_MAX_ENTRIES, using default", "value", maxEntries)
+		} else {
+			config.MaxEntries = n
+		}
+	}
+
+	// Per-resource TTLs, e.g. "Transactions=1m,Loans.Search=5s"
+	if ttls := os.Getenv("LOANPRO_CACHE_TTLS"); ttls != "" {
+		config.TTLs = make(map[string]time.Duration)
+		for _, pair := range strings.Split(ttls, ",") {
+			resource, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			d, err := time.ParseDuration(strings.TrimSpace(value))
+			if !ok || err != nil {
+				slog.Error("Invalid LOANPRO_CACHE_TTLS entry, skipping", "entry", pair)
+				continue
+			}
+			config.TTLs[strings.TrimSpace(resource)] = d
+		}
+	}
+
+	return config, true
+}
+
+// loadRateLimitConfig reads outbound rate limit settings from the environment.
+// Limiting is off unless LOANPRO_RATE_LIMIT or LOANPRO_MAX_CONCURRENT is set.
+func loadRateLimitConfig() (loanpro.RateLimitConfig, bool) {
+	config := loanpro.RateLimitConfig{}
+
+	if rate := os.Getenv("LOANPRO_RATE_LIMIT"); rate != "" {
+		r, err := strconv.ParseFloat(rate, 64)
+		if err != nil || r < 0 {
+			slog.Error("Invalid LOANPRO_RATE_LIMIT, ignoring", "value", rate)
+		} else {
+			config.RequestsPerSecond = r
+		}
+	}
+
+	if burst := os.Getenv("LOANPRO_RATE_BURST"); burst != "" {
+		b, err := strconv.Atoi(burst)
+		if err != nil || b < 1 {
+			slog.Error("Invalid LOANPRO_RATE_BURST, using 1", "value", burst)
+		} else {
+			config.Burst = b
+		}
+	}
+
+	if maxConcurrent := os.Getenv("LOANPRO_MAX_CONCURRENT"); maxConcurrent != "" {
+		n, err := strconv.Atoi(maxConcurrent)
+		if err != nil || n < 0 {
+			slog.Error("Invalid LOANPRO_MAX_CONCURRENT, ignoring", "value", maxConcurrent)
+		} else {
+			config.MaxConcurrent = n
+		}
+	}
+
+	return config, config.RequestsPerSecond > 0 || config.MaxConcurrent > 0
+}
+
+// loadBreakerConfig reads circuit breaker settings from the environment. Setting
+// LOANPRO_BREAKER_THRESHOLD to 0 disables the breaker.
+func loadBreakerConfig() (loanpro.BreakerConfig, bool) {
+	config := loanpro.BreakerConfig{}
+
+	if threshold := os.Getenv("LOANPRO_BREAKER_THRESHOLD"); threshold != "" {
+		n, err := strconv.Atoi(threshold)
+		if err != nil || n < 0 {
+			slog.Error("Invalid LOANPRO_BREAKER_THRESHOLD, using default", "value", threshold)
+		} else if n == 0 {
+			return config, false
+		} else {
+			config.FailureThreshold = n
+		}
+	}
+
+	if cooldown := os.Getenv("LOANPRO_BREAKER_COOLDOWN"); cooldown != "" {
+		d, err := time.ParseDuration(cooldown)
+		if err != nil || d <= 0 {
+			slog.Error("Invalid LOANPRO_BREAKER_COOLDOWN, using default", "value", cooldown)
+		} else {
+			config.Cooldown = d
+		}
+	}
+
+	if probes := os.Getenv("LOANPRO_BREAKER_PROBES"); probes != "" {
+		n, err := strconv.Atoi(probes)
+		if err != nil || n < 1 {
+			slog.Error("Invalid LOANPRO_BREAKER_PROBES, using 1", "value", probes)
+		} else {
+			config.HalfOpenProbes = n
+		}
+	}
+
+	return config, true
+}
+
+// startFakeLoanPro serves the seeded fake LoanPro API on a loopback port and returns
+// its URL and credentials
+func startFakeLoanPro() (string, string, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatalf("Failed to start fake LoanPro API: %v", err)
+	}
+	go http.Serve(listener, fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID))
+
+	apiURL := "http://" + listener.Addr().String()
+	slog.Warn("Using fake LoanPro API with seeded demo data", "url", apiURL)
+	return apiURL, fake.DefaultAPIKey, fake.DefaultTenantID
+}
+
+// newLoanProClient creates a client for a tenant with the cache, rate limit and
+// circuit breaker configured in the environment. Each tenant gets its own instance
+// of each so they stay isolated.
+func newLoanProClient(config TenantConfig, recordDir string) *loanpro.Client {
+	loanProClient := loanpro.NewClient(config.APIURL, config.APIKey, config.TenantID)
+
+	// Tenants files are validated on load and LOANPRO_TIMEZONE before it's used
+	if loc, err := time.LoadLocation(config.Timezone); err == nil {
+		loanProClient.SetLocation(loc)
+	}
+
+	// Save scrubbed request/response fixtures for use with loanpro.NewReplayer in tests
+	if recordDir != "" {
+		slog.Warn("Recording LoanPro traffic", "tenant", config.Name, "dir", recordDir)
+		loanProClient.SetTransport(loanpro.NewRecorder(recordDir, nil, nil))
+	}
+
+	if cacheConfig, enabled := loadCacheConfig(); enabled {
+		loanProClient.EnableCache(cacheConfig)
+	}
+
+	if rateLimitConfig, enabled := loadRateLimitConfig(); enabled {
+		loanProClient.EnableRateLimit(rateLimitConfig)
+	}
+
+	if breakerConfig, enabled := loadBreakerConfig(); enabled {
+		loanProClient.EnableCircuitBreaker(breakerConfig)
+	}
+
+	if loadTracePropagation() {
+		loanProClient.EnableTracePropagation()
+	}
+
+	// Pick up rotated keys from the secret file without a restart
+	if path := config.APIKeyFile; path != "" {
+		loanProClient.SetAPIKeyReloader(func() (string, error) {
+			return readSecretFile(path)
+		})
+		if interval := loadSecretPollInterval(); interval > 0 {
+			go watchSecretFile(context.Background(), path, interval, func(apiKey string) {
+				slog.Info("Rotated LoanPro API key", "tenant", config.Name, "path", path)
+				loanProClient.SetAPIKey(apiKey)
+			})
+		}
+	}
+
+	return loanProClient
+}
+
 func main() {
 	stdioMode := flag.Bool("stdio", false, "Use stdio transport instead of HTTP/SSE")
 	transportType := flag.String("transport", "http", "Transport type: stdio, sse, or http")
+	fakeLoanPro := flag.Bool("fake-loanpro", false, "Serve seeded demo data from an in-process fake LoanPro API")
 	flag.Parse()
 
 	godotenv.Load()
 
+	if flag.Arg(0) == "issue-token" {
+		if err := issueToken(flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	}
+	if flag.Arg(0) == "verify-audit" {
+		if err := verifyAudit(flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Configure structured logging
 	configureSlog()
 
-	loanProClient := loanpro.NewClient(
-		os.Getenv("LOANPRO_API_URL"),
-		os.Getenv("LOANPRO_API_KEY"),
-		os.Getenv("LOANPRO_TENANT_ID"),
-	)
+	// OpenTelemetry tracing of requests, tool calls and LoanPro calls
+	shutdownTracing, err := setupTracing(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer shutdownTracing(context.Background())
+
+	// Each of these can instead be read from the file named by its _FILE variant
+	envConfig := TenantConfig{Name: defaultTenantName}
+	if envConfig.APIURL, _, err = lookupSecret("LOANPRO_API_URL"); err != nil {
+		log.Fatal(err)
+	}
+	if envConfig.APIKey, envConfig.APIKeyFile, err = lookupSecret("LOANPRO_API_KEY"); err != nil {
+		log.Fatal(err)
+	}
+	if envConfig.TenantID, _, err = lookupSecret("LOANPRO_TENANT_ID"); err != nil {
+		log.Fatal(err)
+	}
+	tenantsFile := &TenantsFile{Tenants: []TenantConfig{envConfig}}
+
+	if *fakeLoanPro {
+		fakeConfig := TenantConfig{Name: defaultTenantName}
+		fakeConfig.APIURL, fakeConfig.APIKey, fakeConfig.TenantID = startFakeLoanPro()
+		tenantsFile = &TenantsFile{Tenants: []TenantConfig{fakeConfig}}
+	} else if path := os.Getenv("LOANPRO_TENANTS_FILE"); path != "" {
+		if tenantsFile, err = LoadTenantsFile(path); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Custom fields to include in loan and customer output, e.g. "FICO at Origination,Channel"
+	var customFields []string
+	for _, name := range strings.Split(os.Getenv("LOANPRO_CUSTOM_FIELDS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			customFields = append(customFields, name)
+		}
+	}
+
+	// Timezone timestamps render in for tenants that don't set their own, e.g. "America/New_York"
+	timezone := os.Getenv("LOANPRO_TIMEZONE")
+	if _, err := time.LoadLocation(timezone); err != nil {
+		slog.Error("Invalid LOANPRO_TIMEZONE, using UTC", "timezone", timezone, "error", err)
+		timezone = ""
+	}
+
+	var tenants []*Tenant
+	for _, config := range tenantsFile.Tenants {
+		if config.Timezone == "" {
+			config.Timezone = timezone
+		}
+		recordDir := os.Getenv("LOANPRO_RECORD_DIR")
+		if recordDir != "" && len(tenantsFile.Tenants) > 1 {
+			recordDir = filepath.Join(recordDir, config.Name)
+		}
+
+		tenant := NewTenant(config.Name, newLoanProClient(config, recordDir))
+		if config.CustomFields != nil {
+			tenant.toolManager.SetCustomFields(config.CustomFields)
+		} else {
+			tenant.toolManager.SetCustomFields(customFields)
+		}
+		tenants = append(tenants, tenant)
+	}
+	registry := NewTenantRegistry(tenantsFile.Default, tenants...)
+	if len(tenants) > 1 {
+		slog.Info("Serving multiple LoanPro tenants", "tenants", registry.Names(), "default", tenantsFile.Default)
+	}
+
+	server := NewMCPServerWithTenants(registry)
+
+	// Personal data redaction for logs and tool output
+	redactionRules, outputRedactor, err := loadRedaction()
+	if err != nil {
+		log.Fatal(err)
+	}
+	server.SetRedactor(outputRedactor)
+	slog.Info("Redacting personal data", "logs", redact.Default().Names(), "output", outputRedactor.Names())
+
+	// Bearer token authentication for the HTTP and SSE transports, and the scope
+	// each tool requires of scoped credentials
+	toolScopes := loadToolScopes()
+	server.SetToolScopes(toolScopes)
+	authentication, err := loadAuthConfig(toolScopes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Role-based access to tools, tenants, portfolios and fields for authenticated callers
+	if path := os.Getenv("MCP_POLICY_FILE"); path != "" {
+		policy, err := LoadPolicyFile(path, registry.Names())
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := policy.SetRedactionRules(redactionRules); err != nil {
+			log.Fatal(err)
+		}
+		server.SetPolicy(policy)
+		slog.Info("Access policy loaded", "roles", slices.Sorted(maps.Keys(policy.Roles)), "default_role", policy.DefaultRole)
+		if authentication.authenticator == nil {
+			slog.Warn("MCP_POLICY_FILE only applies to authenticated callers, but authentication is disabled")
+		}
+	}
+
+	// Tamper-evident record of every tool call, checked with the verify-audit subcommand
+	auditLog, err := loadAuditLog()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if auditLog != nil {
+		defer auditLog.Close()
+		server.SetAuditLog(auditLog)
+		slog.Info("Auditing tool calls", "file", os.Getenv("MCP_AUDIT_LOG_FILE"))
+	}
 
-	server := NewMCPServer(loanProClient)
+	// Timeouts and size limits of the HTTP and SSE servers, and how long every
+	// transport drains for
+	httpConfig := loadServerConfig()
+
+	// SIGINT and SIGTERM drain the transport and the metrics listener, and let
+	// deferred cleanup such as closing the audit log run
+	ctx, stop := shutdownContext()
+	defer stop()
+	defer serveMetrics(ctx, httpConfig)()
+
+	// Readiness checks for the HTTP and SSE transports
+	probeInterval, maxSessions := loadReadinessConfig()
+	server.SetProbeInterval(probeInterval)
 
 	// Handle stdio mode for backwards compatibility
 	if *stdioMode {
@@ -288,18 +904,26 @@ func main() {
 		// Run in stdio mode for MCP clients
 		slog.Info("Starting MCP server", "transport", "stdio")
 		stdioTransport := transport.NewStdioTransport(server)
-		if err := stdioTransport.Run(); err != nil {
+		if err := stdioTransport.Run(ctx, httpConfig.ShutdownTimeout); err != nil {
 			slog.Error("Stdio transport failed", "error", err)
 			log.Fatal(err)
 		}
+		slog.Info("Shutting down", "transport", "stdio")
 
 	case "sse":
 		// Run HTTP server with SSE transport
 		slog.Info("Starting MCP server", "transport", "sse")
+		authentication.warnIfUnauthenticated()
 		r := mux.NewRouter()
 		sseTransport := transport.NewSSETransport(server)
-		r.HandleFunc("/sse", sseTransport.HandleSSE).Methods("GET")
+		sseTransport.SetMaxSessions(maxSessions)
+		sseTransport.SetMaxBodyBytes(httpConfig.MaxBodyBytes)
+		r.Handle("/sse", authentication.protect(sseTransport.HandleSSE)).Methods("GET")
+		authentication.route(r)
+		routeMetrics(r)
 		r.HandleFunc("/", sseTransport.HandleRoot).Methods("GET")
+		r.HandleFunc("/livez", sseTransport.HandleLivez).Methods("GET")
+		r.HandleFunc("/readyz", sseTransport.HandleReadyz).Methods("GET")
 
 		port := os.Getenv("PORT")
 		if port == "" {
@@ -307,20 +931,29 @@ func main() {
 		}
 
 		slog.Info("MCP Server starting", "transport", "sse", "port", port)
-		log.Fatal(http.ListenAndServe(":"+port, r))
+		if err := listenAndServe(ctx, r, port, httpConfig, sseTransport.Shutdown); err != nil {
+			log.Fatal(err)
+		}
 
 	case "http":
 		// Run HTTP server with streamable HTTP transport
 		slog.Info("Starting MCP server", "transport", "http")
+		authentication.warnIfUnauthenticated()
 		r := mux.NewRouter()
 		httpTransport := transport.NewHTTPTransport(server)
+		httpTransport.SetMaxSessions(maxSessions)
+		httpTransport.SetMaxBodyBytes(httpConfig.MaxBodyBytes)
 
 		// MCP endpoints
-		r.HandleFunc("/mcp", httpTransport.HandleMCP).Methods("POST", "OPTIONS")
+		r.Handle("/mcp", authentication.protect(httpTransport.HandleMCP)).Methods("POST", "DELETE", "OPTIONS")
+		authentication.route(r)
 
 		// Info endpoints
 		r.HandleFunc("/", httpTransport.HandleRoot).Methods("GET")
 		r.HandleFunc("/health", httpTransport.HandleHealth).Methods("GET")
+		r.HandleFunc("/livez", httpTransport.HandleLivez).Methods("GET")
+		r.HandleFunc("/readyz", httpTransport.HandleReadyz).Methods("GET")
+		routeMetrics(r)
 
 		port := os.Getenv("PORT")
 		if port == "" {
@@ -331,11 +964,16 @@ func main() {
 			"transport", "http",
 			"port", port,
 			"endpoints", map[string]string{
-				"POST /mcp":   "MCP requests",
-				"GET /":       "Server info",
-				"GET /health": "Health check",
+				"POST /mcp":    "MCP requests",
+				"GET /":        "Server info",
+				"GET /health":  "Health check",
+				"GET /livez":   "Liveness probe",
+				"GET /readyz":  "Readiness probe",
+				"GET /metrics": "Prometheus metrics",
 			})
-		log.Fatal(http.ListenAndServe(":"+port, r))
+		if err := listenAndServe(ctx, r, port, httpConfig); err != nil {
+			log.Fatal(err)
+		}
 
 	default:
 		slog.Error("Unknown transport type", "type", *transportType, "valid", []string{"stdio", "sse", "http"})
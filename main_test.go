@@ -1,11 +1,19 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 
 	"loanpro-mcp-server/loanpro"
+	"loanpro-mcp-server/loanpro/fake"
+	"loanpro-mcp-server/requestid"
 	"loanpro-mcp-server/tools"
 	"loanpro-mcp-server/transport"
 )
@@ -51,8 +59,8 @@ func TestNewMCPServer(t *testing.T) {
 		t.Error("Expected server to be created")
 	}
 
-	if server.toolManager == nil {
-		t.Error("Expected tool manager to be initialized")
+	if tenant := server.tenants.Default(); tenant == nil || tenant.toolManager == nil {
+		t.Error("Expected default tenant with a tool manager")
 	}
 }
 
@@ -269,3 +277,194 @@ type MockPayment struct {
 func (m MockPayment) GetID() string     { return m.id }
 func (m MockPayment) GetAmount() string { return "100.00" }
 func (m MockPayment) GetDate() string   { return "2025-01-01" }
+
+func TestMCPServer_HealthDetails_Breaker(t *testing.T) {
+	client := loanpro.NewClient("http://127.0.0.1:1", "key", "tenant")
+	client.EnableCircuitBreaker(loanpro.BreakerConfig{FailureThreshold: 1})
+	server := NewMCPServer(client)
+
+	details := server.HealthDetails()
+	if _, ok := details["status"]; ok {
+		t.Errorf("Expected no status override while closed, got %v", details["status"])
+	}
+
+	// Nothing listens on port 1, so the request fails and opens the breaker
+	client.GetLoan("1")
+
+	details = server.HealthDetails()
+	if details["status"] != "degraded" {
+		t.Errorf("Expected degraded status while open, got %v", details["status"])
+	}
+	breaker, ok := details["circuit_breaker"].(loanpro.BreakerStats)
+	if !ok || breaker.State != loanpro.BreakerOpen {
+		t.Errorf("Expected open circuit breaker in health details, got %v", details["circuit_breaker"])
+	}
+	if breaker.LastError != "" {
+		t.Errorf("Expected the breaker's last error to stay out of /health, got %q", breaker.LastError)
+	}
+}
+
+func TestMCPServer_ToolsCall_FakeLoanPro(t *testing.T) {
+	apiURL, apiKey, tenantID := startFakeLoanPro()
+	server := NewMCPServer(loanpro.NewClient(apiURL, apiKey, tenantID))
+
+	tests := []struct {
+		tool      string
+		arguments map[string]any
+		expected  string
+	}{
+		{"get_loan", map[string]any{"loan_id": "102"}, "John Doe"},
+		{"search_loans", map[string]any{"search_term": "Smith"}, "LN00000105"},
+		{"get_customer", map[string]any{"customer_id": "3"}, "maria.garcia@example.com"},
+		{"search_customers", map[string]any{"email": "rjohnson@example.com"}, "Robert"},
+		{"get_loan_payments", map[string]any{"loan_id": "101"}, "Amount: $296.95"},
+		{"get_loan_transactions", map[string]any{"loan_id": "103"}, "charge.latefee"},
+		{"list_portfolios", map[string]any{}, "Dealer Direct"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			response := server.HandleMCPRequest(transport.MCPRequest{
+				JSONRPC: "2.0",
+				Method:  "tools/call",
+				Params:  map[string]any{"name": tt.tool, "arguments": tt.arguments},
+				ID:      1,
+			})
+			if response.Error != nil {
+				t.Fatalf("Expected no error, got %v", response.Error.Message)
+			}
+
+			text := response.Result.(map[string]any)["content"].([]map[string]any)[0]["text"].(string)
+			if !strings.Contains(text, tt.expected) {
+				t.Errorf("Expected result to contain %q, got:\n%s", tt.expected, text)
+			}
+		})
+	}
+}
+
+func TestMCPServer_ToolsCall_InvalidParams(t *testing.T) {
+	apiURL, apiKey, tenantID := startFakeLoanPro()
+	server := NewMCPServer(loanpro.NewClient(apiURL, apiKey, tenantID))
+
+	tests := []struct {
+		name   string
+		params map[string]any
+	}{
+		{"missing name", map[string]any{"arguments": map[string]any{}}},
+		{"non-string name", map[string]any{"name": 42, "arguments": map[string]any{}}},
+		{"non-object arguments", map[string]any{"name": "get_loan", "arguments": "102"}},
+		{"missing arguments", map[string]any{"name": "get_loan"}},
+		{"non-string loan_id", map[string]any{"name": "get_loan", "arguments": map[string]any{"loan_id": 102}}},
+		{"missing customer_id", map[string]any{"name": "get_customer", "arguments": map[string]any{}}},
+		{"invalid cursor", map[string]any{"name": "search_loans", "arguments": map[string]any{"cursor": "garbage"}}},
+		{"invalid ssn_last4", map[string]any{"name": "search_customers", "arguments": map[string]any{"ssn_last4": "12a4"}}},
+		{"invalid birth_date", map[string]any{"name": "search_customers", "arguments": map[string]any{"birth_date": "31/01/1980"}}},
+		{"no search criteria", map[string]any{"name": "search_customers", "arguments": map[string]any{"limit": 5}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := server.HandleMCPRequest(transport.MCPRequest{
+				JSONRPC: "2.0",
+				Method:  "tools/call",
+				Params:  tt.params,
+				ID:      1,
+			})
+			if response.Error == nil || response.Error.Code != -32602 {
+				t.Errorf("Expected error code -32602, got %+v", response.Error)
+			}
+		})
+	}
+
+	// Tools without required arguments still run when arguments are omitted
+	response := server.HandleMCPRequest(transport.MCPRequest{
+		JSONRPC: "2.0",
+		Method:  "tools/call",
+		Params:  map[string]any{"name": "list_portfolios"},
+		ID:      1,
+	})
+	if response.Error != nil {
+		t.Errorf("Expected no error, got %v", response.Error.Message)
+	}
+}
+
+func TestMCPServer_RequestID(t *testing.T) {
+	var mu sync.Mutex
+	var outbound []string
+	fakeHandler := fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID)
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		outbound = append(outbound, r.Header.Get(requestid.Header))
+		mu.Unlock()
+		fakeHandler.ServeHTTP(w, r)
+	}))
+	defer fakeServer.Close()
+
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(requestid.NewHandler(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	defer slog.SetDefault(previous)
+
+	server := NewMCPServer(loanpro.NewClient(fakeServer.URL, fake.DefaultAPIKey, fake.DefaultTenantID))
+	body, _ := json.Marshal(transport.MCPRequest{
+		JSONRPC: "2.0",
+		Method:  "tools/call",
+		Params:  map[string]any{"name": "get_loan", "arguments": map[string]any{"loan_id": "987654"}},
+		ID:      1,
+	})
+	w := httptest.NewRecorder()
+	transport.NewHTTPTransport(server).HandleMCP(w, httptest.NewRequest("POST", "/mcp", bytes.NewReader(body)))
+
+	id := w.Header().Get(requestid.Header)
+	if id == "" {
+		t.Fatal("Expected a request ID header")
+	}
+
+	// The error sent back quotes the ID
+	var response transport.MCPResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Error == nil {
+		t.Fatal("Expected an error for a missing loan")
+	}
+	if data, _ := response.Error.Data.(map[string]any); data["request_id"] != id {
+		t.Errorf("Expected error data to carry request ID %s, got %v", id, response.Error.Data)
+	}
+
+	// LoanPro received it
+	mu.Lock()
+	defer mu.Unlock()
+	if len(outbound) == 0 {
+		t.Fatal("Expected a LoanPro request")
+	}
+	for _, sent := range outbound {
+		if sent != id {
+			t.Errorf("Expected LoanPro request to carry request ID %s, got %q", id, sent)
+		}
+	}
+
+	// Every record logged while handling the request has it, including the
+	// LoanPro error
+	messages := map[string]bool{}
+	decoder := json.NewDecoder(&logs)
+	for decoder.More() {
+		var record map[string]any
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatalf("Failed to parse log record: %v", err)
+		}
+		messages[record["msg"].(string)] = true
+		if record["msg"] == "Fake LoanPro request" {
+			// Logged by the fake server, on the other side of the LoanPro call
+			continue
+		}
+		if record["request_id"] != id {
+			t.Errorf("Expected log record %q to carry request ID %s, got %v", record["msg"], id, record["request_id"])
+		}
+	}
+	for _, expected := range []string{"LoanPro API error", "Tool execution failed"} {
+		if !messages[expected] {
+			t.Errorf("Expected a %q log record", expected)
+		}
+	}
+}
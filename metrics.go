@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"loanpro-mcp-server/metrics"
+	"loanpro-mcp-server/tools"
+	"loanpro-mcp-server/transport"
+)
+
+var (
+	toolCallsTotal = metrics.Default.NewCounter("mcp_tool_calls_total",
+		"Tool calls by tool and outcome (ok, error or denied).", "tool", "outcome")
+	toolCallDuration = metrics.Default.NewHistogram("mcp_tool_call_duration_seconds",
+		"Tool call latency by tool.", metrics.DefaultBuckets, "tool")
+)
+
+// toolNames are the tools counted under their own name; calls to others are
+// counted as "unknown"
+var toolNames = func() []string {
+	var names []string
+	for _, tool := range tools.NewManager(nil).GetAllTools() {
+		names = append(names, tool.Name)
+	}
+	return names
+}()
+
+// observeToolCall records the outcome and latency of a tools/call request
+func observeToolCall(req transport.MCPRequest, response transport.MCPResponse, duration time.Duration) {
+	tool, _ := req.Params["name"].(string)
+	if !slices.Contains(toolNames, tool) {
+		tool = "unknown"
+	}
+	outcome := "ok"
+	switch {
+	case response.Error == nil:
+	case response.Error.Code == errorCodeForbidden:
+		outcome = "denied"
+	default:
+		outcome = "error"
+	}
+	toolCallsTotal.Inc(tool, outcome)
+	toolCallDuration.Observe(duration.Seconds(), tool)
+}
+
+// routeMetrics serves /metrics on the transport's router, unless MCP_METRICS_ADDR
+// moves it to a listener of its own
+func routeMetrics(r *mux.Router) {
+	if os.Getenv("MCP_METRICS_ADDR") == "" {
+		r.Handle("/metrics", metrics.Default).Methods("GET")
+	}
+}
+
+// serveMetrics serves /metrics on MCP_METRICS_ADDR when set, e.g. ":9090", which
+// keeps it off the public port and makes metrics available with the stdio transport.
+// The listener has the same timeouts as the main server and drains with it when ctx
+// is done. The returned function waits for the drain.
+func serveMetrics(ctx context.Context, config serverConfig) (wait func()) {
+	addr := os.Getenv("MCP_METRICS_ADDR")
+	if addr == "" {
+		return func() {}
+	}
+	handler := http.NewServeMux()
+	handler.Handle("GET /metrics", metrics.Default)
+	srv := config.newServer(handler)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			slog.Error("Metrics listener failed", "addr", addr, "error", err)
+			return
+		}
+		slog.Info("Serving metrics", "addr", addr)
+		if err := runServer(ctx, srv, ln, config.ShutdownTimeout); err != nil {
+			slog.Error("Metrics listener failed", "addr", addr, "error", err)
+		}
+	}()
+	return func() { <-done }
+}
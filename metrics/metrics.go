@@ -0,0 +1,248 @@
+// Package metrics collects counters, gauges and histograms and serves them in the
+// Prometheus text format. Label values must come from small, fixed sets, such as
+// tool names or HTTP status codes, never from IDs in requests.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are histogram bucket bounds in seconds, suited to request latencies
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Default is the registry the server's metrics are registered with
+var Default = NewRegistry()
+
+// Registry holds metrics and writes them out
+type Registry struct {
+	mu      sync.Mutex
+	metrics []*family
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// family is a metric and its series, one per combination of label values
+type family struct {
+	name    string
+	help    string
+	kind    string // "counter", "gauge" or "histogram"
+	labels  []string
+	buckets []float64 // Histogram bucket bounds
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series is one combination of label values
+type series struct {
+	labelValues []string
+	value       float64        // Counter and gauge value
+	fn          func() float64 // Gauge value computed when written, if set
+	counts      []uint64       // Histogram bucket counts, not cumulative
+	count       uint64
+	sum         float64
+}
+
+// register adds a metric, panicking if the name is taken since that's a programming
+// error
+func (r *Registry) register(f *family) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.metrics {
+		if existing.name == f.name {
+			panic("metrics: duplicate metric " + f.name)
+		}
+	}
+	f.series = make(map[string]*series)
+	r.metrics = append(r.metrics, f)
+	return f
+}
+
+// get returns the series for label values, creating it if needed. f.mu must be held.
+func (f *family) get(labelValues []string) *series {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", f.name, len(f.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: slices.Clone(labelValues)}
+		if f.kind == "histogram" {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Counter is a value that only goes up, e.g. a number of requests
+type Counter struct{ f *family }
+
+// NewCounter registers a counter with the given label names
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{r.register(&family{name: name, help: help, kind: "counter", labels: labels})}
+}
+
+// Inc adds one to the series with the given label values
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which must not be negative, to the series with the given label values
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	c.f.get(labelValues).value += v
+}
+
+// Gauge is a value that goes up and down, e.g. a number of open connections
+type Gauge struct{ f *family }
+
+// NewGauge registers a gauge with the given label names
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{r.register(&family{name: name, help: help, kind: "gauge", labels: labels})}
+}
+
+// Add adds v, which may be negative, to the series with the given label values
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+	g.f.get(labelValues).value += v
+}
+
+// Set sets the series with the given label values
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+	g.f.get(labelValues).value = v
+}
+
+// SetFunc makes the series with the given label values report fn's result whenever
+// metrics are written
+func (g *Gauge) SetFunc(fn func() float64, labelValues ...string) {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+	g.f.get(labelValues).fn = fn
+}
+
+// Histogram counts observations, e.g. latencies, in buckets
+type Histogram struct{ f *family }
+
+// NewHistogram registers a histogram with the given bucket upper bounds, in
+// increasing order, and label names
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return &Histogram{r.register(&family{name: name, help: help, kind: "histogram", labels: labels, buckets: buckets})}
+}
+
+// Observe records v in the series with the given label values
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.f.mu.Lock()
+	defer h.f.mu.Unlock()
+	s := h.f.get(labelValues)
+	if i, _ := slices.BinarySearch(h.f.buckets, v); i < len(h.f.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+// ServeHTTP writes the registry's metrics in the Prometheus text format
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	buf := bufio.NewWriter(w)
+	r.write(buf)
+	buf.Flush()
+}
+
+// write writes every metric, with series sorted by label values
+func (r *Registry) write(w *bufio.Writer) {
+	r.mu.Lock()
+	metrics := slices.Clone(r.metrics)
+	r.mu.Unlock()
+
+	for _, f := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, escapeHelp(f.help), f.name, f.kind)
+
+		f.mu.Lock()
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			s := f.series[key]
+			labels := formatLabels(f.labels, s.labelValues)
+			switch f.kind {
+			case "histogram":
+				var cumulative uint64
+				for i, bound := range f.buckets {
+					cumulative += s.counts[i]
+					fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, withLabel(labels, "le", formatFloat(bound)), cumulative)
+				}
+				fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, withLabel(labels, "le", "+Inf"), s.count)
+				fmt.Fprintf(w, "%s_sum%s %s\n", f.name, labels, formatFloat(s.sum))
+				fmt.Fprintf(w, "%s_count%s %d\n", f.name, labels, s.count)
+			default:
+				value := s.value
+				if s.fn != nil {
+					value = s.fn()
+				}
+				fmt.Fprintf(w, "%s%s %s\n", f.name, labels, formatFloat(value))
+			}
+		}
+		f.mu.Unlock()
+	}
+}
+
+// formatLabels renders label pairs as {name="value",...}, or nothing without labels
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + escapeLabel(values[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// withLabel adds a label pair to rendered labels
+func withLabel(labels, name, value string) string {
+	pair := name + `="` + value + `"`
+	if labels == "" {
+		return "{" + pair + "}"
+	}
+	return labels[:len(labels)-1] + "," + pair + "}"
+}
+
+// formatFloat renders a sample value
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+// escapeLabel escapes a label value
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+// escapeHelp escapes help text
+func escapeHelp(s string) string { return helpEscaper.Replace(s) }
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("requests_total", "Requests handled.", "method", "status")
+	requests.Inc("tools/call", "ok")
+	requests.Inc("tools/call", "ok")
+	requests.Add(3, "tools/list", `bad "quote"`)
+
+	sessions := r.NewGauge("active_sessions", "Open sessions.", "transport")
+	sessions.Add(2, "sse")
+	sessions.Add(-1, "sse")
+	sessions.SetFunc(func() float64 { return 4 }, "http")
+
+	latency := r.NewHistogram("duration_seconds", "Latency.", []float64{0.1, 1}, "tool")
+	latency.Observe(0.05, "get_loan")
+	latency.Observe(0.1, "get_loan")
+	latency.Observe(3, "get_loan")
+
+	r.NewCounter("unused_total", "Never incremented.")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	expected := `# HELP requests_total Requests handled.
+# TYPE requests_total counter
+requests_total{method="tools/call",status="ok"} 2
+requests_total{method="tools/list",status="bad \"quote\""} 3
+# HELP active_sessions Open sessions.
+# TYPE active_sessions gauge
+active_sessions{transport="http"} 4
+active_sessions{transport="sse"} 1
+# HELP duration_seconds Latency.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{tool="get_loan",le="0.1"} 2
+duration_seconds_bucket{tool="get_loan",le="1"} 2
+duration_seconds_bucket{tool="get_loan",le="+Inf"} 3
+duration_seconds_sum{tool="get_loan"} 3.15
+duration_seconds_count{tool="get_loan"} 3
+# HELP unused_total Never incremented.
+# TYPE unused_total counter
+`
+	if w.Body.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, w.Body.String())
+	}
+	if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("Expected the Prometheus text format, got %s", contentType)
+	}
+}
+
+func TestRegistry_Panics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(r *Registry)
+	}{
+		{"duplicate name", func(r *Registry) {
+			r.NewCounter("requests_total", "")
+			r.NewGauge("requests_total", "")
+		}},
+		{"wrong label count", func(r *Registry) {
+			r.NewCounter("requests_total", "", "method").Inc("tools/call", "ok")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic")
+				}
+			}()
+			tt.fn(NewRegistry())
+		})
+	}
+}
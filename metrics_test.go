@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"loanpro-mcp-server/auth"
+	"loanpro-mcp-server/loanpro"
+	"loanpro-mcp-server/loanpro/fake"
+	"loanpro-mcp-server/metrics"
+	"loanpro-mcp-server/transport"
+)
+
+func TestMCPServer_Metrics(t *testing.T) {
+	fakeServer := httptest.NewServer(fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID))
+	defer fakeServer.Close()
+
+	client := loanpro.NewClient(fakeServer.URL, fake.DefaultAPIKey, fake.DefaultTenantID)
+	client.EnableCache(loanpro.CacheConfig{})
+	server := NewMCPServer(client)
+	server.SetPolicy(&PolicyFile{Roles: map[string]*Role{"collections": {Tools: []string{"get_loan"}}}})
+
+	// Requests go through the HTTP transport so MCP requests are counted too
+	httpTransport := transport.NewHTTPTransport(server)
+	call := func(principal *auth.Principal, tool string, arguments map[string]any) {
+		ctx := context.Background()
+		if principal != nil {
+			ctx = auth.WithPrincipal(ctx, principal)
+		}
+		body, _ := json.Marshal(transport.MCPRequest{
+			JSONRPC: "2.0",
+			Method:  "tools/call",
+			Params:  map[string]any{"name": tool, "arguments": arguments},
+			ID:      1,
+		})
+		req := httptest.NewRequest("POST", "/mcp", bytes.NewReader(body)).WithContext(ctx)
+		httpTransport.HandleMCP(httptest.NewRecorder(), req)
+	}
+	call(nil, "get_loan", map[string]any{"loan_id": "101"})
+	call(nil, "get_loan", map[string]any{"loan_id": "101"})
+	call(nil, "get_loan", map[string]any{"loan_id": "987654"})
+	call(nil, "get_loan_transactions", map[string]any{"loan_id": "102", "limit": 5})
+	call(nil, "get_loan_by_id_4711", map[string]any{"loan_id": "101"})
+	call(&auth.Principal{Subject: "dialer", Role: "collections"}, "get_customer", map[string]any{"customer_id": "3"})
+
+	w := httptest.NewRecorder()
+	metrics.Default.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	output := w.Body.String()
+
+	for _, expected := range []string{
+		`mcp_requests_total{method="tools/call",status="ok"}`,
+		`mcp_requests_total{method="tools/call",status="error"}`,
+		`mcp_tool_calls_total{tool="get_loan",outcome="ok"}`,
+		`mcp_tool_calls_total{tool="get_loan",outcome="error"}`,
+		`mcp_tool_calls_total{tool="get_customer",outcome="denied"}`,
+		`mcp_tool_calls_total{tool="unknown",outcome="error"}`,
+		`mcp_tool_call_duration_seconds_bucket{tool="get_loan_transactions",le="+Inf"}`,
+		`loanpro_api_requests_total{endpoint="Loans",method="GET",status="200"}`,
+		`loanpro_api_requests_total{endpoint="Loans/Transactions",method="GET",status="200"}`,
+		`loanpro_api_request_duration_seconds_count{endpoint="Loans"}`,
+		`loanpro_cache_lookups_total{resource="Loans",result="hit"}`,
+		`loanpro_cache_lookups_total{resource="Loans",result="miss"}`,
+		`mcp_active_sessions{transport="http"}`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected metrics to contain %s", expected)
+		}
+	}
+
+	// Label values never carry IDs from requests
+	for _, line := range strings.Split(output, "\n") {
+		if regexp.MustCompile(`="[^"]*(101|102|987654|4711)[^"]*"`).MatchString(line) {
+			t.Errorf("Expected no IDs in labels, got %s", line)
+		}
+	}
+}
+
+func TestServeMetrics_Drains(t *testing.T) {
+	t.Setenv("MCP_METRICS_ADDR", "127.0.0.1:0")
+	ctx, shutdown := context.WithCancel(context.Background())
+	wait := serveMetrics(ctx, defaultServerConfig)
+
+	// The metrics listener stops with the rest of the server
+	shutdown()
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Metrics listener still running after shutdown")
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"loanpro-mcp-server/auth"
+	"loanpro-mcp-server/redact"
+	"loanpro-mcp-server/tools"
+)
+
+// ErrPermissionDenied is returned when the caller's role doesn't allow a request
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Role is what callers with a role may do. Omitted lists place no limit; an empty
+// list allows nothing.
+type Role struct {
+	Tools        []string `json:"tools,omitempty"`
+	Tenants      []string `json:"tenants,omitempty"`
+	Portfolios   []string `json:"portfolios,omitempty"`    // Portfolio IDs whose loans the role may see; such roles can't use the customer tools
+	HiddenFields []string `json:"hidden_fields,omitempty"` // e.g. "email", "phone" or a custom field name
+	Redact       []string `json:"redact,omitempty"`        // Redaction rules for the role's output, MCP_REDACT_OUTPUT when omitted
+
+	redactor *redact.Redactor // Built from Redact by SetRedactionRules
+}
+
+// PolicyFile is the format of the file named by MCP_POLICY_FILE
+type PolicyFile struct {
+	DefaultRole string           `json:"default_role,omitempty"` // Role of callers whose credential names none
+	Roles       map[string]*Role `json:"roles"`
+}
+
+// LoadPolicyFile reads and validates a policy file. Tool and tenant names are checked
+// so a typo can't silently lock a role out.
+func LoadPolicyFile(path string, tenantNames []string) (*PolicyFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var policy PolicyFile
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	if len(policy.Roles) == 0 {
+		return nil, fmt.Errorf("invalid policy file %s: no roles", path)
+	}
+	if policy.DefaultRole != "" && policy.Roles[policy.DefaultRole] == nil {
+		return nil, fmt.Errorf("invalid policy file %s: default role %q is not defined", path, policy.DefaultRole)
+	}
+
+	var toolNames []string
+	for _, tool := range tools.NewManager(nil).GetAllTools() {
+		toolNames = append(toolNames, tool.Name)
+	}
+	for name, role := range policy.Roles {
+		if role == nil {
+			return nil, fmt.Errorf("invalid policy file %s: role %q is empty", path, name)
+		}
+		for _, tool := range role.Tools {
+			if !slices.Contains(toolNames, tool) {
+				return nil, fmt.Errorf("invalid policy file %s: role %q lists unknown tool %q", path, name, tool)
+			}
+			if role.Portfolios != nil && (tool == "get_customer" || tool == "search_customers") {
+				return nil, fmt.Errorf("invalid policy file %s: role %q limits portfolios, so it can't use customer tool %q", path, name, tool)
+			}
+		}
+		for _, tenant := range role.Tenants {
+			if !slices.Contains(tenantNames, tenant) {
+				return nil, fmt.Errorf("invalid policy file %s: role %q lists unknown tenant %q", path, name, tenant)
+			}
+		}
+	}
+	return &policy, nil
+}
+
+// SetRedactionRules builds each role's output redactor from the rules it names
+func (f *PolicyFile) SetRedactionRules(rules []redact.Rule) error {
+	for name, role := range f.Roles {
+		if role.Redact == nil {
+			continue
+		}
+		selected, err := redact.Select(rules, role.Redact)
+		if err != nil {
+			return fmt.Errorf("policy role %q: %w", name, err)
+		}
+		role.redactor = redact.New(selected...)
+	}
+	return nil
+}
+
+// roleFor returns the name and definition of the caller's role: the one named by
+// its credential, otherwise the default role
+func (f *PolicyFile) roleFor(principal *auth.Principal) (string, *Role, error) {
+	name := principal.Role
+	if name == "" {
+		name = f.DefaultRole
+	}
+	if name == "" {
+		return "", nil, fmt.Errorf("%w: %s has no role", ErrPermissionDenied, principal.Subject)
+	}
+	role, ok := f.Roles[name]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: role %q is not defined", ErrPermissionDenied, name)
+	}
+	return name, role, nil
+}
+
+// allowsTenant reports whether the role may query a tenant
+func (r *Role) allowsTenant(name string) bool {
+	return r == nil || r.Tenants == nil || slices.Contains(r.Tenants, name)
+}
+
+// allowedTenants returns the tenants among names the role may query
+func (r *Role) allowedTenants(names []string) []string {
+	return slices.DeleteFunc(slices.Clone(names), func(name string) bool { return !r.allowsTenant(name) })
+}
+
+// applyPolicy attaches the tool policy of the caller's role to ctx and returns the
+// role. Unauthenticated callers, e.g. over stdio, and all callers when no policy
+// file is configured get a nil role and aren't restricted.
+func (s *MCPServer) applyPolicy(ctx context.Context) (context.Context, *Role, error) {
+	principal := auth.PrincipalFromContext(ctx)
+	if s.policy == nil || principal == nil {
+		return ctx, nil, nil
+	}
+
+	name, role, err := s.policy.roleFor(principal)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return tools.WithPolicy(ctx, &tools.Policy{
+		Role:         name,
+		Tools:        role.Tools,
+		Portfolios:   role.Portfolios,
+		HiddenFields: role.HiddenFields,
+		Redactor:     role.redactor,
+	}), role, nil
+}
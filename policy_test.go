@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"loanpro-mcp-server/auth"
+	"loanpro-mcp-server/loanpro"
+	"loanpro-mcp-server/loanpro/fake"
+	"loanpro-mcp-server/tools"
+	"loanpro-mcp-server/transport"
+)
+
+func TestLoadPolicyFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string // Error substring, empty for success
+	}{
+		{"valid", `{"default_role": "agent", "roles": {"agent": {"tools": ["get_loan"], "tenants": ["auto"]}, "auditor": {}}}`, ""},
+		{"invalid JSON", `{"roles": {`, "invalid policy file"},
+		{"no roles", `{"roles": {}}`, "no roles"},
+		{"unknown default", `{"default_role": "admin", "roles": {"agent": {}}}`, "default role"},
+		{"unknown tool", `{"roles": {"agent": {"tools": ["get_loans"]}}}`, `unknown tool "get_loans"`},
+		{"unknown tenant", `{"roles": {"agent": {"tenants": ["mortgage"]}}}`, `unknown tenant "mortgage"`},
+		{"empty role", `{"roles": {"agent": null}}`, "is empty"},
+		{"customer tool with portfolios", `{"roles": {"agent": {"tools": ["get_loan", "get_customer"], "portfolios": ["2"]}}}`, `customer tool "get_customer"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			policy, err := LoadPolicyFile(path, []string{"auto", "cards"})
+			if tt.expected != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expected) {
+					t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(policy.Roles) != 2 || policy.Roles["auditor"].Tools != nil {
+				t.Errorf("Expected 2 roles with the auditor unrestricted, got %+v", policy)
+			}
+		})
+	}
+
+	if _, err := LoadPolicyFile("policy.example.json", []string{"auto", "cards"}); err != nil {
+		t.Errorf("Expected the example policy to be valid, got %v", err)
+	}
+}
+
+func TestMCPServer_Policy(t *testing.T) {
+	autoServer := httptest.NewServer(fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID))
+	defer autoServer.Close()
+	cardsServer := httptest.NewServer(fake.NewServer("Bearer cards-key", "cards-tenant"))
+	defer cardsServer.Close()
+
+	server := NewMCPServerWithTenants(NewTenantRegistry("auto",
+		NewTenant("auto", loanpro.NewClient(autoServer.URL, fake.DefaultAPIKey, fake.DefaultTenantID)),
+		NewTenant("cards", loanpro.NewClient(cardsServer.URL, "Bearer cards-key", "cards-tenant")),
+	))
+	server.SetPolicy(&PolicyFile{
+		DefaultRole: "support",
+		Roles: map[string]*Role{
+			"support": {
+				Tools:        []string{"get_loan", "get_customer", "search_loans"},
+				Tenants:      []string{"auto"},
+				HiddenFields: []string{tools.FieldEmail, tools.FieldPhone},
+			},
+			"collections": {Tools: []string{"get_loan", "search_loans"}, Portfolios: []string{"2"}},
+			"auditor":     {},
+		},
+	})
+
+	call := func(principal *auth.Principal, tool string, arguments map[string]any) transport.MCPResponse {
+		ctx := context.Background()
+		if principal != nil {
+			ctx = auth.WithPrincipal(ctx, principal)
+		}
+		return server.HandleMCPRequestContext(ctx, transport.MCPRequest{
+			JSONRPC: "2.0",
+			Method:  "tools/call",
+			Params:  map[string]any{"name": tool, "arguments": arguments},
+			ID:      1,
+		})
+	}
+
+	support := &auth.Principal{Subject: "agent-7"}
+	collections := &auth.Principal{Subject: "dialer", Role: "collections"}
+	auditor := &auth.Principal{Subject: "audit", Role: "auditor"}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		tool      string
+		arguments map[string]any
+		allowed   bool
+		contains  string
+	}{
+		{"unauthenticated", nil, "get_customer", map[string]any{"customer_id": "3"}, true, "maria.garcia@example.com"},
+		{"default role hides email", support, "get_customer", map[string]any{"customer_id": "3"}, true, "Email: [hidden]"},
+		{"default role tool not allowed", support, "list_portfolios", map[string]any{}, false, "tool list_portfolios"},
+		{"tenant not allowed", support, "get_loan", map[string]any{"loan_id": "101", "tenant": "cards"}, false, "tenant cards"},
+		{"portfolio allowed", collections, "get_loan", map[string]any{"loan_id": "103"}, true, "LN00000103"},
+		{"portfolio not allowed", collections, "get_loan", map[string]any{"loan_id": "101"}, false, "outside the allowed portfolios"},
+		{"search within portfolio", collections, "search_loans", map[string]any{}, true, "Showing 2 of 2"},
+		{"unrestricted role", auditor, "get_customer", map[string]any{"customer_id": "3", "tenant": "cards"}, true, "maria.garcia@example.com"},
+		{"undefined role", &auth.Principal{Subject: "x", Role: "admin"}, "get_loan", map[string]any{"loan_id": "101"}, false, `role "admin"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := call(tt.principal, tt.tool, tt.arguments)
+			if !tt.allowed {
+				if response.Error == nil || response.Error.Code != errorCodeForbidden || !strings.Contains(response.Error.Message, tt.contains) {
+					t.Errorf("Expected permission denied mentioning %q, got %+v", tt.contains, response.Error)
+				}
+				return
+			}
+			if response.Error != nil {
+				t.Fatalf("Expected no error, got %v", response.Error.Message)
+			}
+			text := response.Result.(map[string]any)["content"].([]map[string]any)[0]["text"].(string)
+			if !strings.Contains(text, tt.contains) {
+				t.Errorf("Expected result to contain %q, got:\n%s", tt.contains, text)
+			}
+		})
+	}
+
+	// tools/list shows each role only its tools and tenants
+	list := func(principal *auth.Principal) []tools.Tool {
+		response := server.HandleMCPRequestContext(auth.WithPrincipal(context.Background(), principal),
+			transport.MCPRequest{JSONRPC: "2.0", Method: "tools/list", ID: 1})
+		return response.Result.(map[string]any)["tools"].([]tools.Tool)
+	}
+	supportTools := list(support)
+	if len(supportTools) != 3 {
+		t.Errorf("Expected 3 tools for support, got %d", len(supportTools))
+	}
+	tenant := supportTools[0].InputSchema["properties"].(map[string]any)["tenant"].(map[string]any)
+	if enum := tenant["enum"].([]string); len(enum) != 1 || enum[0] != "auto" {
+		t.Errorf("Expected only the auto tenant to be advertised, got %v", enum)
+	}
+	if len(list(auditor)) != 7 {
+		t.Errorf("Expected all tools for the auditor")
+	}
+
+	if _, _, err := (&PolicyFile{Roles: map[string]*Role{"auditor": {}}}).roleFor(support); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("Expected callers without a role to be denied when there is no default, got %v", err)
+	}
+}
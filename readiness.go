@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"loanpro-mcp-server/loanpro"
+	"loanpro-mcp-server/transport"
+)
+
+const (
+	// defaultProbeInterval is how long a LoanPro probe result is reused, so frequent
+	// readiness checks don't add load on LoanPro
+	defaultProbeInterval = 30 * time.Second
+
+	// probeTimeout bounds a LoanPro probe, below the usual readiness probe timeout
+	probeTimeout = 5 * time.Second
+)
+
+// loanProProbe checks that a tenant's LoanPro API is reachable with its
+// credentials, reusing the last result for the probe interval
+type loanProProbe struct {
+	mu       sync.Mutex
+	interval time.Duration
+	checked  time.Time
+	err      error
+	now      func() time.Time
+}
+
+// newLoanProProbe creates a probe that reuses results for interval
+func newLoanProProbe(interval time.Duration) *loanProProbe {
+	return &loanProProbe{interval: interval, now: time.Now}
+}
+
+// check returns the last probe result, probing again once it's older than the
+// interval. Concurrent checks wait for a single probe.
+func (p *loanProProbe) check(ctx context.Context, client *loanpro.Client) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.checked.IsZero() && p.now().Sub(p.checked) < p.interval {
+		return p.err
+	}
+
+	// The result is reused by later checks, so it mustn't be a cancellation of the
+	// probe request that triggered it, e.g. when the kubelet disconnects
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), probeTimeout)
+	defer cancel()
+	p.err = client.Ping(ctx)
+	p.checked = p.now()
+	if p.err != nil {
+		slog.WarnContext(ctx, "LoanPro readiness probe failed", "error", p.err)
+	}
+	return p.err
+}
+
+// SetProbeInterval sets how long each tenant's LoanPro probe result is reused
+func (s *MCPServer) SetProbeInterval(interval time.Duration) {
+	for _, name := range s.tenants.Names() {
+		tenant, _ := s.tenants.Get(name)
+		tenant.probe = newLoanProProbe(interval)
+	}
+}
+
+// CheckReadiness checks every tenant's configuration, LoanPro API and circuit
+// breaker. A half-open breaker passes, since traffic is needed to close it.
+//
+// /readyz isn't authenticated, so failed checks report a fixed message and the
+// number of failing tenants. The errors, which can hold LoanPro URLs, tenant names
+// and response bodies, are only logged.
+func (s *MCPServer) CheckReadiness(ctx context.Context) map[string]transport.Check {
+	return map[string]transport.Check{
+		"config": s.checkTenants(ctx, "configuration invalid", func(tenant *Tenant) error {
+			return tenant.client.CheckConfig()
+		}),
+		"loanpro": s.checkTenants(ctx, "loanpro probe failed", func(tenant *Tenant) error {
+			return tenant.probe.check(ctx, tenant.client)
+		}),
+		"circuit_breaker": s.checkTenants(ctx, "circuit breaker open", func(tenant *Tenant) error {
+			if stats := tenant.client.BreakerStats(); stats.State == loanpro.BreakerOpen {
+				return fmt.Errorf("circuit breaker is open until %s: %s", stats.OpenUntil.Format(time.RFC3339), stats.LastError)
+			}
+			return nil
+		}),
+	}
+}
+
+// checkTenants runs a check on every tenant at once, so slow tenants don't add up
+// past the readiness probe's timeout. It fails with message when any tenant fails,
+// logging each tenant's error, and counts the failures when there are several.
+func (s *MCPServer) checkTenants(ctx context.Context, message string, check func(*Tenant) error) transport.Check {
+	names := s.tenants.Names()
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		tenant, _ := s.tenants.Get(name)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = check(tenant)
+		}()
+	}
+	wg.Wait()
+
+	result := transport.Check{Status: transport.CheckOK}
+	failed := 0
+	for i, name := range names {
+		if err := errs[i]; err != nil {
+			slog.WarnContext(ctx, "Readiness check failed", "check", message, "tenant", name, "error", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		result.Status = transport.CheckFail
+		result.Message = message
+	}
+	if len(names) > 1 {
+		result.Details = map[string]any{"tenants": len(names), "failed": failed}
+	}
+	return result
+}
+
+// loadReadinessConfig reads the LoanPro probe interval and the session limit from
+// MCP_READY_PROBE_INTERVAL and MCP_MAX_SESSIONS
+func loadReadinessConfig() (time.Duration, int) {
+	interval := defaultProbeInterval
+	if value := os.Getenv("MCP_READY_PROBE_INTERVAL"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			slog.Error("Invalid MCP_READY_PROBE_INTERVAL, using default", "value", value)
+		} else {
+			interval = d
+		}
+	}
+
+	maxSessions := 0
+	if value := os.Getenv("MCP_MAX_SESSIONS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			slog.Error("Invalid MCP_MAX_SESSIONS, ignoring", "value", value)
+		} else {
+			maxSessions = n
+		}
+	}
+
+	return interval, maxSessions
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"loanpro-mcp-server/loanpro"
+	"loanpro-mcp-server/loanpro/fake"
+	"loanpro-mcp-server/transport"
+)
+
+func TestMCPServer_CheckReadiness(t *testing.T) {
+	var requests atomic.Int32
+	fakeHandler := fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID)
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fakeHandler.ServeHTTP(w, r)
+	}))
+	defer fakeServer.Close()
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	tripped := loanpro.NewClient(down.URL, fake.DefaultAPIKey, fake.DefaultTenantID)
+	tripped.EnableCircuitBreaker(loanpro.BreakerConfig{FailureThreshold: 1, Cooldown: time.Minute})
+	tripped.GetLoan("101")
+
+	tests := []struct {
+		name     string
+		client   *loanpro.Client
+		failures map[string]string // Failing checks and their message
+	}{
+		{"ready", loanpro.NewClient(fakeServer.URL, fake.DefaultAPIKey, fake.DefaultTenantID), nil},
+		{"bad credentials", loanpro.NewClient(fakeServer.URL, "Bearer revoked", fake.DefaultTenantID), map[string]string{"loanpro": "loanpro probe failed"}},
+		{"missing tenant ID", loanpro.NewClient(fakeServer.URL, fake.DefaultAPIKey, ""), map[string]string{"config": "configuration invalid", "loanpro": "loanpro probe failed"}},
+		{"breaker open", tripped, map[string]string{"loanpro": "loanpro probe failed", "circuit_breaker": "circuit breaker open"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checks := NewMCPServer(test.client).CheckReadiness(context.Background())
+			for _, name := range []string{"config", "loanpro", "circuit_breaker"} {
+				check := checks[name]
+				message, shouldFail := test.failures[name]
+				if shouldFail {
+					if check.Status != transport.CheckFail || check.Message != message {
+						t.Errorf("Expected %s to fail with %q, got %+v", name, message, check)
+					}
+				} else if check.Status != transport.CheckOK {
+					t.Errorf("Expected %s to pass, got %+v", name, check)
+				}
+			}
+		})
+	}
+
+	// Probe results are reused within the interval
+	server := NewMCPServer(loanpro.NewClient(fakeServer.URL, fake.DefaultAPIKey, fake.DefaultTenantID))
+	tenant, _ := server.tenants.Get(defaultTenantName)
+	now := time.Now()
+	tenant.probe.now = func() time.Time { return now }
+	requests.Store(0)
+	server.CheckReadiness(context.Background())
+	server.CheckReadiness(context.Background())
+	if requests.Load() != 1 {
+		t.Errorf("Expected one LoanPro probe within the interval, got %d", requests.Load())
+	}
+	now = now.Add(defaultProbeInterval)
+	server.CheckReadiness(context.Background())
+	if requests.Load() != 2 {
+		t.Errorf("Expected a new LoanPro probe after the interval, got %d", requests.Load())
+	}
+
+	// A probe request that's cancelled, e.g. by the kubelet disconnecting, doesn't
+	// leave a cancellation to be reused for the interval
+	now = now.Add(defaultProbeInterval)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if check := server.CheckReadiness(cancelled)["loanpro"]; check.Status != transport.CheckOK {
+		t.Errorf("Expected a cancelled request's probe to pass, got %+v", check)
+	}
+}
+
+func TestMCPServer_CheckReadiness_Concurrent(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID).ServeHTTP(w, r)
+	}))
+	defer slow.Close()
+
+	var tenants []*Tenant
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		tenants = append(tenants, NewTenant(name, loanpro.NewClient(slow.URL, fake.DefaultAPIKey, fake.DefaultTenantID)))
+	}
+	server := NewMCPServerWithTenants(NewTenantRegistry("a", tenants...))
+
+	start := time.Now()
+	check := server.CheckReadiness(context.Background())["loanpro"]
+	if check.Status != transport.CheckOK {
+		t.Errorf("Expected every tenant to pass, got %+v", check)
+	}
+	if elapsed := time.Since(start); elapsed > 600*time.Millisecond {
+		t.Errorf("Expected tenants to be probed at once, took %v", elapsed)
+	}
+}
+
+func TestMCPServer_CheckReadiness_Tenants(t *testing.T) {
+	fakeServer := httptest.NewServer(fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID))
+	defer fakeServer.Close()
+
+	server := NewMCPServerWithTenants(NewTenantRegistry("auto",
+		NewTenant("auto", loanpro.NewClient(fakeServer.URL, fake.DefaultAPIKey, fake.DefaultTenantID)),
+		NewTenant("cards", loanpro.NewClient(fakeServer.URL, "Bearer revoked", fake.DefaultTenantID)),
+	))
+
+	check := server.CheckReadiness(context.Background())["loanpro"]
+	if check.Status != transport.CheckFail || check.Message != "loanpro probe failed" {
+		t.Errorf("Expected the cards tenant to fail, got %+v", check)
+	}
+	if check.Details["tenants"] != 2 || check.Details["failed"] != 1 {
+		t.Errorf("Expected a count of failing tenants, got %v", check.Details)
+	}
+
+	// The unauthenticated response doesn't carry tenant names or LoanPro errors
+	data, _ := json.Marshal(check)
+	if strings.Contains(string(data), "cards") || strings.Contains(string(data), "401") {
+		t.Errorf("Expected no tenant names or errors in the check, got %s", data)
+	}
+}
+
+func TestLoadReadinessConfig(t *testing.T) {
+	tests := []struct {
+		interval         string
+		maxSessions      string
+		expectedInterval time.Duration
+		expectedMax      int
+	}{
+		{"", "", defaultProbeInterval, 0},
+		{"10s", "200", 10 * time.Second, 200},
+		{"0", "0", 0, 0},
+		{"soon", "-1", defaultProbeInterval, 0},
+	}
+
+	for _, test := range tests {
+		t.Setenv("MCP_READY_PROBE_INTERVAL", test.interval)
+		t.Setenv("MCP_MAX_SESSIONS", test.maxSessions)
+		interval, maxSessions := loadReadinessConfig()
+		if interval != test.expectedInterval || maxSessions != test.expectedMax {
+			t.Errorf("Expected %v and %d for %q and %q, got %v and %d",
+				test.expectedInterval, test.expectedMax, test.interval, test.maxSessions, interval, maxSessions)
+		}
+	}
+}
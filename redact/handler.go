@@ -0,0 +1,99 @@
+package redact
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"loanpro-mcp-server/requestid"
+)
+
+// Handler is a slog.Handler that masks personal data in the message and attributes
+// of every record before passing it on
+type Handler struct {
+	next     slog.Handler
+	redactor *Redactor // nil to use Default at the time of each record
+}
+
+// NewHandler wraps next so its records are redacted. With a nil redactor the
+// handler follows Default, so rules added after logging is set up still apply.
+func NewHandler(next slog.Handler, redactor *Redactor) *Handler {
+	return &Handler{next: next, redactor: redactor}
+}
+
+// Enabled reports whether the wrapped handler handles records at level
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle redacts a record and passes it to the wrapped handler
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	r := h.active()
+	redacted := slog.NewRecord(record.Time, record.Level, r.String(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(r, attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs returns a handler whose wrapped handler has the redacted attributes
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	r := h.active()
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = redactAttr(r, attr)
+	}
+	return &Handler{next: h.next.WithAttrs(redacted), redactor: h.redactor}
+}
+
+// WithGroup returns a handler whose wrapped handler starts the group
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), redactor: h.redactor}
+}
+
+// active returns the redactor to apply
+func (h *Handler) active() *Redactor {
+	if h.redactor != nil {
+		return h.redactor
+	}
+	return Default()
+}
+
+// redactAttr masks an attribute. Attributes named like a covered field are masked
+// whole; errors and Stringers are rendered as text first so their contents are
+// checked too. Request IDs are random hex generated by the server and kept as is,
+// since an all-digit one would otherwise look like an account number.
+func redactAttr(r *Redactor, attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if attr.Key == requestid.LogKey {
+		return attr
+	}
+	if replacement, ok := r.field(attr.Key); ok {
+		return slog.String(attr.Key, replacement)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		attr.Value = slog.StringValue(r.String(attr.Value.String()))
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, member := range group {
+			redacted[i] = redactAttr(r, member)
+		}
+		attr.Value = slog.GroupValue(redacted...)
+	case slog.KindAny:
+		switch v := attr.Value.Any().(type) {
+		case error:
+			attr.Value = slog.StringValue(r.String(v.Error()))
+		case fmt.Stringer:
+			attr.Value = slog.StringValue(r.String(v.String()))
+		case []byte:
+			attr.Value = slog.StringValue(r.String(string(v)))
+		default:
+			attr.Value = slog.AnyValue(r.Value(v))
+		}
+	}
+	return attr
+}
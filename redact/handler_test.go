@@ -0,0 +1,66 @@
+package redact
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"testing"
+
+	"loanpro-mcp-server/requestid"
+)
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), nil))
+
+	logger.Debug("Response body", "data", `{"email":"jane.smith@example.com","primaryPhone":"5551234567"}`)
+	logger.Error("LoanPro API error", "error", errors.New("customer 123-45-6789 not found"))
+	logger.With("ssn_last4", "6789").Info("Searching customers for jane.smith@example.com")
+	logger.WithGroup("request").Info("Received", "body", []byte(`{"ssn":"123456789"}`), "url", &url.URL{Path: "/search", RawQuery: "email=jane.smith@example.com"})
+	logger.Info("Args", slog.Group("arguments", "birth_date", "1985-04-12", "email", "jane.smith@example.com"))
+	logger.Info("Structured", "arguments", map[string]any{"phone": "(555) 123-4567", "loan_id": "102"})
+
+	output := buf.String()
+	for _, pii := range []string{"jane.smith", "5551234567", "123-45-6789", "123456789", "6789", "1985-04-12", "123-4567"} {
+		if strings.Contains(output, pii) {
+			t.Errorf("Expected %q not to reach the logs, got:\n%s", pii, output)
+		}
+	}
+	if !strings.Contains(output, `"loan_id":"102"`) || !strings.Contains(output, "[redacted:email]") {
+		t.Errorf("Expected other values kept and PII masked, got:\n%s", output)
+	}
+}
+
+func TestHandler_FollowsDefault(t *testing.T) {
+	previous := Default()
+	defer SetDefault(previous)
+
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil), nil))
+	SetDefault(New(Rule{Name: "member_id", Fields: []string{"member"}}))
+	logger.Info("Lookup", "member", "M1234567")
+
+	if strings.Contains(buf.String(), "M1234567") {
+		t.Errorf("Expected rules set after setup to apply, got %s", buf.String())
+	}
+}
+
+func TestHandler_KeepsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(requestid.NewHandler(NewHandler(slog.NewJSONHandler(&buf, nil), nil)))
+
+	// An all-digit ID matches the account number rule but must stay correlatable
+	ctx := requestid.WithID(context.Background(), "4111111111111111")
+	logger.InfoContext(ctx, "Tool call", "account", "4111111111111111")
+
+	output := buf.String()
+	if !strings.Contains(output, `"request_id":"4111111111111111"`) {
+		t.Errorf("Expected the request ID kept, got:\n%s", output)
+	}
+	if !strings.Contains(output, `"account":"[redacted:account_number]"`) {
+		t.Errorf("Expected other values still masked, got:\n%s", output)
+	}
+}
@@ -0,0 +1,327 @@
+// Package redact masks personal data in text, decoded JSON and log records: SSNs,
+// birth dates, email addresses, phone numbers, account numbers and street
+// addresses, plus any rules added from a rules file. Data is found two ways: by
+// pattern in free text, and by field name in JSON objects and log attributes, which
+// catches values such as undashed phone numbers that no pattern could tell apart
+// from an ID.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Names of the built-in rules
+const (
+	SSN           = "ssn"
+	DOB           = "dob"
+	Email         = "email"
+	Phone         = "phone"
+	AccountNumber = "account_number"
+	Address       = "address"
+)
+
+// Rule finds one kind of personal data
+type Rule struct {
+	Name string
+	// Patterns match the data in free text. When a pattern has a group named "pii",
+	// only that group is masked, so a label such as "SSN:" can be kept.
+	Patterns []*regexp.Regexp
+	// Fields are JSON keys and log attribute names whose values are always masked,
+	// compared case-insensitively
+	Fields []string
+}
+
+// Builtin returns the built-in rules
+func Builtin() []Rule {
+	return []Rule{
+		{
+			Name: SSN,
+			Patterns: []*regexp.Regexp{
+				regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+				regexp.MustCompile(`(?i)\b(?:ssn|social security(?: number)?)\b\W{0,3}(?P<pii>\d{9}|\d{4})\b`),
+			},
+			Fields: []string{"ssn", "ssnLast4", "ssn_last4", "socialSecurityNumber", "taxId"},
+		},
+		{
+			Name: DOB,
+			Patterns: []*regexp.Regexp{
+				regexp.MustCompile(`(?i)\b(?:dob|date of birth|birth ?date)\b\W{0,3}(?P<pii>\d{4}-\d{2}-\d{2}|\d{1,2}/\d{1,2}/\d{4})`),
+			},
+			Fields: []string{"birthDate", "birth_date", "dateOfBirth", "dob"},
+		},
+		{
+			Name: Email,
+			Patterns: []*regexp.Regexp{
+				regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+			},
+			Fields: []string{"email", "emailAddress"},
+		},
+		{
+			Name: Phone,
+			Patterns: []*regexp.Regexp{
+				regexp.MustCompile(`(?:\+1[-. ]?)?(?:\(\d{3}\)\s?|\b\d{3}[-. ])\d{3}[-. ]\d{4}\b`),
+				regexp.MustCompile(`(?i)\bphone\b\W{0,3}(?P<pii>\+?\d{10,11})\b`),
+			},
+			Fields: []string{"phone", "primaryPhone", "secondaryPhone", "phoneNumber", "mobilePhone"},
+		},
+		{
+			Name: AccountNumber,
+			Patterns: []*regexp.Regexp{
+				// 12 to 19 digits, optionally grouped, covers bank accounts and card numbers
+				regexp.MustCompile(`\b\d(?:[ -]?\d){11,18}\b`),
+			},
+			Fields: []string{"accountNumber", "routingNumber", "cardNumber", "bankAccount", "iban"},
+		},
+		{
+			Name: Address,
+			Patterns: []*regexp.Regexp{
+				regexp.MustCompile(`\b\d{1,6}(?:\s+[A-Z0-9][A-Za-z0-9.'-]*){1,4}\s+(?:Street|St|Avenue|Ave|Road|Rd|Boulevard|Blvd|Drive|Dr|Lane|Ln|Court|Ct|Way|Place|Pl|Terrace|Ter|Circle|Cir|Parkway|Pkwy|Highway|Hwy)\b\.?`),
+			},
+			Fields: []string{"address", "address1", "address2", "addressLine1", "addressLine2", "street", "streetAddress"},
+		},
+	}
+}
+
+// Select returns the rules with the given names, in the order named
+func Select(rules []Rule, names []string) ([]Rule, error) {
+	selected := make([]Rule, 0, len(names))
+	for _, name := range names {
+		i := slices.IndexFunc(rules, func(rule Rule) bool { return rule.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown redaction rule %q", name)
+		}
+		selected = append(selected, rules[i])
+	}
+	return selected, nil
+}
+
+// rulesFile is the format of a custom rules file
+type rulesFile struct {
+	Rules []struct {
+		Name     string   `json:"name"`
+		Patterns []string `json:"patterns"`
+		Fields   []string `json:"fields"`
+	} `json:"rules"`
+}
+
+// LoadRulesFile reads custom rules, e.g.
+// {"rules": [{"name": "member_id", "patterns": ["\\bM\\d{7}\\b"], "fields": ["memberId"]}]}
+func LoadRulesFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redaction rules: %w", err)
+	}
+	var file rulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid redaction rules file %s: %w", path, err)
+	}
+
+	var rules []Rule
+	for i, entry := range file.Rules {
+		if entry.Name == "" {
+			return nil, fmt.Errorf("invalid redaction rules file %s: rule %d has no name", path, i+1)
+		}
+		if len(entry.Patterns) == 0 && len(entry.Fields) == 0 {
+			return nil, fmt.Errorf("invalid redaction rules file %s: rule %q needs patterns or fields", path, entry.Name)
+		}
+		rule := Rule{Name: entry.Name, Fields: entry.Fields}
+		for _, pattern := range entry.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid redaction rules file %s: rule %q: %w", path, entry.Name, err)
+			}
+			rule.Patterns = append(rule.Patterns, re)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Redactor masks the data found by a set of rules. A nil *Redactor masks nothing.
+type Redactor struct {
+	rules  []Rule
+	fields map[string]string // Lower-cased field name to rule name
+}
+
+// New creates a redactor applying rules
+func New(rules ...Rule) *Redactor {
+	r := &Redactor{rules: rules, fields: make(map[string]string)}
+	for _, rule := range rules {
+		for _, field := range rule.Fields {
+			r.fields[strings.ToLower(field)] = rule.Name
+		}
+	}
+	return r
+}
+
+// Names returns the names of the redactor's rules
+func (r *Redactor) Names() []string {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, len(r.rules))
+	for i, rule := range r.rules {
+		names[i] = rule.Name
+	}
+	return names
+}
+
+// mask is the replacement for data found by a rule
+func mask(rule string) string {
+	return "[redacted:" + rule + "]"
+}
+
+// field returns the mask for the value of a field the rules cover
+func (r *Redactor) field(name string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	rule, ok := r.fields[strings.ToLower(name)]
+	return mask(rule), ok
+}
+
+// String masks personal data in text. Text holding a JSON object or array, such as
+// a logged request body, is also masked by field name.
+func (r *Redactor) String(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	if trimmed := strings.TrimSpace(s); len(trimmed) > 1 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		if masked, ok := r.json(trimmed); ok {
+			return masked
+		}
+	}
+	return r.text(s)
+}
+
+// json masks a JSON document, reporting false when s isn't valid JSON
+func (r *Redactor) json(s string) (string, bool) {
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.Value(value)); err != nil {
+		return "", false
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), true
+}
+
+// text masks the pattern matches of every rule in free text
+func (r *Redactor) text(s string) string {
+	for _, rule := range r.rules {
+		for _, pattern := range rule.Patterns {
+			s = replace(pattern, s, mask(rule.Name))
+		}
+	}
+	return s
+}
+
+// replace masks the matches of pattern in s, only the "pii" group where it matched
+func replace(pattern *regexp.Regexp, s, replacement string) string {
+	group := pattern.SubexpIndex("pii")
+	matches := pattern.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if group > 0 && m[2*group] >= 0 {
+			start, end = m[2*group], m[2*group+1]
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(replacement)
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// Value returns a copy of decoded JSON or tool output with personal data masked.
+// Values of covered fields are masked whole; other strings are masked by pattern.
+func (r *Redactor) Value(value any) any {
+	if r == nil {
+		return value
+	}
+	switch v := value.(type) {
+	case string:
+		return r.String(v)
+	case map[string]any:
+		masked := make(map[string]any, len(v))
+		for key, item := range v {
+			if replacement, ok := r.field(key); ok && !isEmpty(item) {
+				masked[key] = replacement
+			} else {
+				masked[key] = r.Value(item)
+			}
+		}
+		return masked
+	case map[string]string:
+		masked := make(map[string]string, len(v))
+		for key, item := range v {
+			if replacement, ok := r.field(key); ok && item != "" {
+				masked[key] = replacement
+			} else {
+				masked[key] = r.String(item)
+			}
+		}
+		return masked
+	case []any:
+		masked := make([]any, len(v))
+		for i, item := range v {
+			masked[i] = r.Value(item)
+		}
+		return masked
+	case []map[string]any:
+		masked := make([]map[string]any, len(v))
+		for i, item := range v {
+			masked[i] = r.Value(item).(map[string]any)
+		}
+		return masked
+	case []string:
+		masked := make([]string, len(v))
+		for i, item := range v {
+			masked[i] = r.String(item)
+		}
+		return masked
+	}
+	return value
+}
+
+// isEmpty reports whether a field value holds nothing worth masking
+func isEmpty(value any) bool {
+	return value == nil || value == ""
+}
+
+// defaultRedactor is the redactor used for logs
+var defaultRedactor atomic.Pointer[Redactor]
+
+func init() {
+	defaultRedactor.Store(New(Builtin()...))
+}
+
+// Default returns the redactor applied to logs, which uses the built-in rules until
+// SetDefault is called
+func Default() *Redactor {
+	return defaultRedactor.Load()
+}
+
+// SetDefault replaces the redactor applied to logs, e.g. to add custom rules
+func SetDefault(r *Redactor) {
+	defaultRedactor.Store(r)
+}
@@ -0,0 +1,117 @@
+package redact
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedactor_String(t *testing.T) {
+	r := New(Builtin()...)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"dashed SSN", "SSN 123-45-6789 on file", "SSN [redacted:ssn] on file"},
+		{"labelled SSN", "ssn: 123456789", "ssn: [redacted:ssn]"},
+		{"labelled DOB", "Date of Birth: 1985-04-12", "Date of Birth: [redacted:dob]"},
+		{"US DOB", "DOB 4/12/1985", "DOB [redacted:dob]"},
+		{"email", "Email: jane.smith@example.com", "Email: [redacted:email]"},
+		{"formatted phone", "call (555) 123-4567 or 555.987.6543", "call [redacted:phone] or [redacted:phone]"},
+		{"labelled phone", "Phone: 5551234567", "Phone: [redacted:phone]"},
+		{"account number", "card 4111 1111 1111 1111, acct 000123456789", "card [redacted:account_number], acct [redacted:account_number]"},
+		{"address", "lives at 742 Evergreen Terrace, Springfield", "lives at [redacted:address], Springfield"},
+		{"address with suffix", "1600 Pennsylvania Ave NW", "[redacted:address] NW"},
+		{"IDs and dates are kept", "Loan 102 (LN00000102) created 2023-02-01, /Date(1675209600)/", "Loan 102 (LN00000102) created 2023-02-01, /Date(1675209600)/"},
+		{"amounts are kept", "Balance: $22500.00, Payoff: $22,650.25", "Balance: $22500.00, Payoff: $22,650.25"},
+		{
+			"JSON body by field",
+			`{"d":{"results":[{"id":3,"firstName":"Maria","phone":"5555550123","birthDate":"1992-07-21","ssn":"555443333"}]}}`,
+			`{"d":{"results":[{"birthDate":"[redacted:dob]","firstName":"Maria","id":3,"phone":"[redacted:phone]","ssn":"[redacted:ssn]"}]}}`,
+		},
+		{"JSON search query", `{"query":{"wildcard":{"ssn":"*3333"}}}`, `{"query":{"wildcard":{"ssn":"[redacted:ssn]"}}}`},
+		{"invalid JSON falls back to patterns", `{"email": "a@b.co"`, `{"email": "[redacted:email]"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.String(tt.input); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRedactor_Value(t *testing.T) {
+	r := New(Builtin()...)
+	result := map[string]any{
+		"content": []map[string]any{{"type": "text", "text": "Email: maria.garcia@example.com"}},
+		"email":   "",
+	}
+
+	masked := r.Value(result).(map[string]any)
+	text := masked["content"].([]map[string]any)[0]["text"]
+	if text != "Email: [redacted:email]" {
+		t.Errorf("Expected email masked in tool output, got %q", text)
+	}
+	if masked["email"] != "" {
+		t.Errorf("Expected empty field left alone, got %q", masked["email"])
+	}
+	if result["content"].([]map[string]any)[0]["text"] != "Email: maria.garcia@example.com" {
+		t.Error("Expected the original value not to be modified")
+	}
+
+	var nilRedactor *Redactor
+	if nilRedactor.String("jane@example.com") != "jane@example.com" {
+		t.Error("Expected a nil redactor to mask nothing")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	rules, err := Select(Builtin(), []string{SSN, Email})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	r := New(rules...)
+	if got := r.String("jane@example.com 123-45-6789 (555) 123-4567"); got != "[redacted:email] [redacted:ssn] (555) 123-4567" {
+		t.Errorf("Expected only the selected rules to apply, got %q", got)
+	}
+
+	if _, err := Select(Builtin(), []string{"passport"}); err == nil {
+		t.Error("Expected error for an unknown rule")
+	}
+}
+
+func TestLoadRulesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	content := `{"rules": [{"name": "member_id", "patterns": ["\\bM\\d{7}\\b"], "fields": ["memberId"]}]}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadRulesFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	r := New(rules...)
+	if got := r.String(`member M1234567 {"memberId": "X9"}`); got != "member [redacted:member_id] {\"memberId\": \"X9\"}" {
+		t.Errorf("Expected custom pattern to apply, got %q", got)
+	}
+	if got := r.String(`{"memberId": "X9"}`); got != `{"memberId":"[redacted:member_id]"}` {
+		t.Errorf("Expected custom field to apply, got %q", got)
+	}
+
+	for content, expected := range map[string]string{
+		`{"rules": [{"patterns": ["x"]}]}`:                "has no name",
+		`{"rules": [{"name": "empty"}]}`:                  "needs patterns or fields",
+		`{"rules": [{"name": "bad", "patterns": ["("]}]}`: "missing closing",
+	} {
+		os.WriteFile(path, []byte(content), 0o600)
+		if _, err := LoadRulesFile(path); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing %q, got %v", expected, err)
+		}
+	}
+}
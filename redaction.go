@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"loanpro-mcp-server/redact"
+)
+
+// defaultOutputRedaction lists the rules applied to tool output when
+// MCP_REDACT_OUTPUT isn't set. Email and phone stay visible since agents use them
+// to contact borrowers; roles that shouldn't see them can hide or redact them.
+var defaultOutputRedaction = []string{redact.SSN, redact.DOB, redact.AccountNumber}
+
+// loadRedaction sets up personal data redaction from the environment. Logs are
+// always redacted with every rule, built-in and from MCP_REDACT_RULES_FILE. Tool
+// output is redacted with the rules named in MCP_REDACT_OUTPUT, or "none". It
+// returns all rules, so access policy roles can choose their own, and the output
+// redactor.
+func loadRedaction() ([]redact.Rule, *redact.Redactor, error) {
+	rules := redact.Builtin()
+	if path := os.Getenv("MCP_REDACT_RULES_FILE"); path != "" {
+		custom, err := redact.LoadRulesFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		rules = append(rules, custom...)
+	}
+	redact.SetDefault(redact.New(rules...))
+
+	names := defaultOutputRedaction
+	if value, ok := os.LookupEnv("MCP_REDACT_OUTPUT"); ok {
+		names = nil
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" && name != "none" {
+				names = append(names, name)
+			}
+		}
+	}
+	selected, err := redact.Select(rules, names)
+	if err != nil {
+		return nil, nil, fmt.Errorf("MCP_REDACT_OUTPUT: %w", err)
+	}
+	return rules, redact.New(selected...), nil
+}
+
+// SetRedactor configures the personal data masked in every tenant's tool output
+// for callers whose role doesn't choose its own
+func (s *MCPServer) SetRedactor(redactor *redact.Redactor) {
+	for _, name := range s.tenants.Names() {
+		tenant, _ := s.tenants.Get(name)
+		tenant.toolManager.SetRedactor(redactor)
+	}
+}
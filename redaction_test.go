@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"loanpro-mcp-server/auth"
+	"loanpro-mcp-server/loanpro"
+	"loanpro-mcp-server/loanpro/fake"
+	"loanpro-mcp-server/redact"
+	"loanpro-mcp-server/transport"
+)
+
+func TestLoadRedaction(t *testing.T) {
+	defer redact.SetDefault(redact.Default())
+
+	rulesFile := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(rulesFile, []byte(`{"rules": [{"name": "member_id", "patterns": ["\\bM\\d{7}\\b"]}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		logs     []string
+		output   []string
+		expected string // Error substring, empty for success
+	}{
+		{"defaults", nil, []string{"ssn", "dob", "email", "phone", "account_number", "address"}, []string{"ssn", "dob", "account_number"}, ""},
+		{"output none", map[string]string{"MCP_REDACT_OUTPUT": "none"}, nil, nil, ""},
+		{"custom rule", map[string]string{"MCP_REDACT_RULES_FILE": rulesFile, "MCP_REDACT_OUTPUT": "ssn, member_id"},
+			[]string{"ssn", "dob", "email", "phone", "account_number", "address", "member_id"}, []string{"ssn", "member_id"}, ""},
+		{"unknown output rule", map[string]string{"MCP_REDACT_OUTPUT": "ssn,passport"}, nil, nil, `unknown redaction rule "passport"`},
+		{"missing rules file", map[string]string{"MCP_REDACT_RULES_FILE": "missing.json"}, nil, nil, "failed to read redaction rules"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, output, err := loadRedaction()
+			if tt.expected != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expected) {
+					t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if tt.logs != nil && !slices.Equal(redact.Default().Names(), tt.logs) {
+				t.Errorf("Expected log rules %v, got %v", tt.logs, redact.Default().Names())
+			}
+			if !slices.Equal(output.Names(), tt.output) {
+				t.Errorf("Expected output rules %v, got %v", tt.output, output.Names())
+			}
+		})
+	}
+}
+
+func TestMCPServer_Redaction(t *testing.T) {
+	fakeServer := httptest.NewServer(fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID))
+	defer fakeServer.Close()
+
+	server := NewMCPServer(loanpro.NewClient(fakeServer.URL, fake.DefaultAPIKey, fake.DefaultTenantID))
+	rules := redact.Builtin()
+	output, _ := redact.Select(rules, defaultOutputRedaction)
+	server.SetRedactor(redact.New(output...))
+	policy := &PolicyFile{Roles: map[string]*Role{
+		"support": {Redact: []string{redact.Email, redact.Phone}},
+		"auditor": {Redact: []string{}},
+	}}
+	if err := policy.SetRedactionRules(rules); err != nil {
+		t.Fatal(err)
+	}
+	server.SetPolicy(policy)
+
+	// Everything the server logs, through slog at debug level and written directly
+	// to stderr, is captured
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(redact.NewHandler(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}), nil)))
+	defer slog.SetDefault(previous)
+	stderr, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(original *os.File) { os.Stderr = original }(os.Stderr)
+	os.Stderr = stderr
+
+	call := func(principal *auth.Principal, tool string, arguments map[string]any) string {
+		ctx := context.Background()
+		if principal != nil {
+			ctx = auth.WithPrincipal(ctx, principal)
+		}
+		response := server.HandleMCPRequestContext(ctx, transport.MCPRequest{
+			JSONRPC: "2.0",
+			Method:  "tools/call",
+			Params:  map[string]any{"name": tool, "arguments": arguments},
+			ID:      1,
+		})
+		if response.Error != nil {
+			return response.Error.Message
+		}
+		return response.Result.(map[string]any)["content"].([]map[string]any)[0]["text"].(string)
+	}
+
+	// Tool output is redacted with the server's rules, or those of the caller's role
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		contains  []string
+		excludes  []string
+	}{
+		{"server rules", nil, []string{"maria.garcia@example.com"}, nil},
+		{"role rules", &auth.Principal{Subject: "agent-7", Role: "support"}, []string{"[redacted:email]"}, []string{"maria.garcia@example.com"}},
+		{"role without redaction", &auth.Principal{Subject: "audit", Role: "auditor"}, []string{"maria.garcia@example.com"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := call(tt.principal, "get_customer", map[string]any{"customer_id": "3"})
+			for _, expected := range tt.contains {
+				if !strings.Contains(text, expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, text)
+				}
+			}
+			for _, excluded := range tt.excludes {
+				if strings.Contains(text, excluded) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", excluded, text)
+				}
+			}
+		})
+	}
+
+	// Searches by every identifier, including failing ones, never leak the
+	// customers' personal data into the logs
+	call(nil, "search_customers", map[string]any{"email": "maria.garcia@example.com"})
+	call(nil, "search_customers", map[string]any{"phone": "5555550123"})
+	call(nil, "search_customers", map[string]any{"phone": "(555) 555-0123"})
+	call(nil, "search_customers", map[string]any{"ssn_last4": "3333"})
+	call(nil, "search_customers", map[string]any{"birth_date": "1992-07-21"})
+	call(nil, "search_customers", map[string]any{"search_term": "Garcia"})
+	call(nil, "get_loan", map[string]any{"loan_id": "103"})
+	slog.Error("Lookup failed", "error", "no customer with SSN 555-44-3333 or email maria.garcia@example.com")
+
+	written, err := io.ReadAll(io.MultiReader(bytes.NewReader(logs.Bytes()), func() io.Reader {
+		data, _ := os.ReadFile(stderr.Name())
+		return bytes.NewReader(data)
+	}()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(written), "Response body") {
+		t.Fatalf("Expected response bodies to be logged at debug level, got:\n%s", written)
+	}
+	for _, pii := range []string{
+		"maria.garcia@example.com", "jane.smith@example.com", "rjohnson@example.com",
+		"5555550123", "555-0123", "5551234567",
+		"555443333", "555-44-3333", "123456789",
+		"1992-07-21", "1985-04-12", "1968-02-29",
+	} {
+		if strings.Contains(string(written), pii) {
+			t.Errorf("Expected %q never to be logged", pii)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Package requestid correlates the logs, LoanPro calls and error responses of an
+// MCP request through an ID carried in its context.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+// Header carries the request ID on HTTP responses and on requests to LoanPro
+const Header = "X-Request-ID"
+
+// LogKey is the attribute holding the request ID in log records and error data
+const LogKey = "request_id"
+
+// contextKey is the context key for the request ID
+type contextKey struct{}
+
+// New returns a random request ID
+func New() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithID attaches a request ID to ctx
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID in ctx, or an empty string outside a request
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Handler is a slog.Handler that adds the request ID of the context, if any, to
+// every record before passing it on
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler wraps next so its records carry the request ID
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+// Enabled reports whether the wrapped handler handles records at level
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle adds the request ID to a record and passes it to the wrapped handler
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if id := FromContext(ctx); id != "" {
+		record = record.Clone()
+		record.AddAttrs(slog.String(LogKey, id))
+	}
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs returns a handler whose wrapped handler has the attributes
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup returns a handler whose wrapped handler starts the group
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,53 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	if id := FromContext(context.Background()); id != "" {
+		t.Errorf("Expected no request ID, got %q", id)
+	}
+	if id := FromContext(WithID(context.Background(), "abc123")); id != "abc123" {
+		t.Errorf("Expected abc123, got %q", id)
+	}
+	if a, b := New(), New(); len(a) != 16 || a == b {
+		t.Errorf("Expected distinct 16 character IDs, got %q and %q", a, b)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected any
+	}{
+		{"in request", WithID(context.Background(), "abc123"), "abc123"},
+		{"outside request", context.Background(), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf.Reset()
+			logger.InfoContext(test.ctx, "Something happened")
+
+			var record map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("Failed to parse log record: %v", err)
+			}
+			if record[LogKey] != test.expected {
+				t.Errorf("Expected request_id %v, got %v", test.expected, record[LogKey])
+			}
+			if record["component"] != "test" {
+				t.Errorf("Expected attributes to be kept, got %v", record)
+			}
+		})
+	}
+}
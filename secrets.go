@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultSecretPollInterval is how often mounted secret files are checked for changes
+const defaultSecretPollInterval = 10 * time.Second
+
+// lookupSecret returns the value of an environment variable, or the contents of the
+// file named by its _FILE variant, e.g. LOANPRO_API_KEY_FILE for Docker and
+// Kubernetes secrets. It also returns the file path so callers can watch it.
+func lookupSecret(name string) (string, string, error) {
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return os.Getenv(name), "", nil
+	}
+	if os.Getenv(name) != "" {
+		slog.Warn("Both variable and _FILE variant set, using the file", "variable", name)
+	}
+
+	value, err := readSecretFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("%s_FILE: %w", name, err)
+	}
+	return value, path, nil
+}
+
+// readSecretFile reads a secret, ignoring surrounding whitespace such as a trailing newline
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", fmt.Errorf("secret file %s is empty", path)
+	}
+	return value, nil
+}
+
+// watchSecretFile polls a secret file until ctx is done and calls onChange with its
+// contents whenever they change. Polling rather than file events copes with the
+// symlink swaps Kubernetes uses to update mounted secrets. Unreadable or empty files
+// are logged and skipped, keeping the previous value, since they are usually seen
+// mid-update.
+func watchSecretFile(ctx context.Context, path string, interval time.Duration, onChange func(string)) {
+	last, _ := readSecretFile(path)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		value, err := readSecretFile(path)
+		if err != nil {
+			slog.Warn("Failed to reload secret file", "path", path, "error", err)
+			continue
+		}
+		if value != last {
+			last = value
+			onChange(value)
+		}
+	}
+}
+
+// loadSecretPollInterval reads how often secret files are checked from the
+// environment. Setting LOANPRO_SECRET_POLL_INTERVAL to 0 disables watching.
+func loadSecretPollInterval() time.Duration {
+	interval := os.Getenv("LOANPRO_SECRET_POLL_INTERVAL")
+	if interval == "" {
+		return defaultSecretPollInterval
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil || d < 0 {
+		slog.Error("Invalid LOANPRO_SECRET_POLL_INTERVAL, using default", "value", interval)
+		return defaultSecretPollInterval
+	}
+	return d
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLookupSecret(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "api-key")
+	if err := os.WriteFile(keyFile, []byte("Bearer from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		file     string
+		expected string
+		path     string
+		err      string
+	}{
+		{"env only", "Bearer from-env", "", "Bearer from-env", "", ""},
+		{"file", "", keyFile, "Bearer from-file", keyFile, ""},
+		{"file wins", "Bearer from-env", keyFile, "Bearer from-file", keyFile, ""},
+		{"missing file", "", filepath.Join(dir, "missing"), "", "", "TEST_SECRET_FILE"},
+		{"empty file", "", emptyFile, "", "", "is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_SECRET", tt.value)
+			t.Setenv("TEST_SECRET_FILE", tt.file)
+
+			value, path, err := lookupSecret("TEST_SECRET")
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if value != tt.expected || path != tt.path {
+				t.Errorf("Expected %q from %q, got %q from %q", tt.expected, tt.path, value, path)
+			}
+		})
+	}
+}
+
+func TestWatchSecretFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-key")
+	if err := os.WriteFile(path, []byte("Bearer one\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan string, 10)
+	go watchSecretFile(ctx, path, 5*time.Millisecond, func(value string) {
+		changes <- value
+	})
+
+	// An empty file seen mid-update keeps the previous value
+	time.Sleep(20 * time.Millisecond)
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("Bearer two\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case value := <-changes:
+		if value != "Bearer two" {
+			t.Errorf("Expected rotated key, got %q", value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a change notification")
+	}
+
+	select {
+	case value := <-changes:
+		t.Errorf("Expected a single change, got another: %q", value)
+	case <-time.After(30 * time.Millisecond):
+	}
+}
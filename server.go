@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// serverConfig holds the HTTP server's timeouts and size limits
+type serverConfig struct {
+	ReadHeaderTimeout time.Duration // Time to read request headers
+	ReadTimeout       time.Duration // Time to read a whole request, body included
+	WriteTimeout      time.Duration // Time to answer a request, so it must outlast slow tool calls
+	IdleTimeout       time.Duration // How long keep-alive connections wait for the next request
+	MaxHeaderBytes    int           // Size limit of request headers
+	MaxBodyBytes      int64         // Size limit of POST /mcp bodies and of each request on an SSE stream
+	ShutdownTimeout   time.Duration // How long in-flight requests get to finish on shutdown
+}
+
+// defaultServerConfig allows LoanPro's 30 second timeout, a retry and rate limit
+// queueing within the write timeout, and drains within Kubernetes' default 30
+// second termination grace period
+var defaultServerConfig = serverConfig{
+	ReadHeaderTimeout: 10 * time.Second,
+	ReadTimeout:       30 * time.Second,
+	WriteTimeout:      2 * time.Minute,
+	IdleTimeout:       2 * time.Minute,
+	MaxHeaderBytes:    64 << 10,
+	MaxBodyBytes:      1 << 20,
+	ShutdownTimeout:   25 * time.Second,
+}
+
+// loadServerConfig reads the server's timeouts and size limits from the environment
+func loadServerConfig() serverConfig {
+	config := defaultServerConfig
+	config.ReadHeaderTimeout = envDuration("MCP_HTTP_READ_HEADER_TIMEOUT", config.ReadHeaderTimeout)
+	config.ReadTimeout = envDuration("MCP_HTTP_READ_TIMEOUT", config.ReadTimeout)
+	config.WriteTimeout = envDuration("MCP_HTTP_WRITE_TIMEOUT", config.WriteTimeout)
+	config.IdleTimeout = envDuration("MCP_HTTP_IDLE_TIMEOUT", config.IdleTimeout)
+	config.MaxHeaderBytes = int(envBytes("MCP_HTTP_MAX_HEADER_BYTES", int64(config.MaxHeaderBytes)))
+	config.MaxBodyBytes = envBytes("MCP_HTTP_MAX_BODY_BYTES", config.MaxBodyBytes)
+	config.ShutdownTimeout = envDuration("MCP_SHUTDOWN_TIMEOUT", config.ShutdownTimeout)
+	return config
+}
+
+// envDuration reads a positive duration from the environment, using fallback when
+// it's unset or invalid
+func envDuration(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		slog.Error("Invalid "+name+", using default", "value", value, "default", fallback)
+		return fallback
+	}
+	return d
+}
+
+// envBytes reads a positive byte count from the environment, using fallback when
+// it's unset or invalid
+func envBytes(name string, fallback int64) int64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		slog.Error("Invalid "+name+", using default", "value", value, "default", fallback)
+		return fallback
+	}
+	return n
+}
+
+// newServer creates an HTTP server for handler with the configured timeouts and
+// header size limit
+func (c serverConfig) newServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: c.ReadHeaderTimeout,
+		ReadTimeout:       c.ReadTimeout,
+		WriteTimeout:      c.WriteTimeout,
+		IdleTimeout:       c.IdleTimeout,
+		MaxHeaderBytes:    c.MaxHeaderBytes,
+	}
+}
+
+// shutdownContext is done on SIGINT or SIGTERM, which starts draining the servers.
+// A second signal during the drain exits immediately.
+func shutdownContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	context.AfterFunc(ctx, stop)
+	return ctx, stop
+}
+
+// listenAndServe serves handler on port until ctx is done, then drains the server.
+// onShutdown hooks run when draining starts, e.g. to end long-lived streams.
+func listenAndServe(ctx context.Context, handler http.Handler, port string, config serverConfig, onShutdown ...func()) error {
+	ln, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+	srv := config.newServer(handler)
+	for _, hook := range onShutdown {
+		srv.RegisterOnShutdown(hook)
+	}
+	return runServer(ctx, srv, ln, config.ShutdownTimeout)
+}
+
+// runServer serves on ln until ctx is done, e.g. on SIGTERM, then drains: it stops
+// accepting connections, runs the server's shutdown hooks, and waits up to
+// shutdownTimeout for in-flight requests before closing what's left. It returns an
+// error only when serving fails.
+func runServer(ctx context.Context, srv *http.Server, ln net.Listener, shutdownTimeout time.Duration) error {
+	errs := make(chan error, 1)
+	go func() { errs <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down, draining in-flight requests", "timeout", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Requests still running at the shutdown deadline, closing their connections", "error", err)
+		srv.Close()
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("Server stopped")
+	return nil
+}
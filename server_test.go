@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"loanpro-mcp-server/transport"
+)
+
+// startServer runs srv with runServer on a local port and returns its URL, a
+// function that starts the drain, and the channel runServer's result arrives on
+func startServer(t *testing.T, srv *http.Server, shutdownTimeout time.Duration) (string, context.CancelFunc, <-chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	done := make(chan error, 1)
+	go func() { done <- runServer(ctx, srv, ln, shutdownTimeout) }()
+	return "http://" + ln.Addr().String(), cancel, done
+}
+
+// waitServer waits for runServer to return, failing after timeout
+func waitServer(t *testing.T, done <-chan error, timeout time.Duration) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		t.Fatalf("Server still running after %v", timeout)
+		return nil
+	}
+}
+
+func TestRunServer_DrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv := defaultServerConfig.newServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	}))
+	url, shutdown, done := startServer(t, srv, 5*time.Second)
+
+	responses := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			responses <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		responses <- string(body)
+	}()
+	<-started
+
+	// The drain waits for the request and then stops the server
+	shutdown()
+	time.Sleep(50 * time.Millisecond)
+	if _, err := http.Get(url); err == nil {
+		t.Error("Expected new connections to be refused while draining")
+	}
+	close(release)
+
+	if body := <-responses; body != "done" {
+		t.Errorf("Expected the in-flight request to complete, got %q", body)
+	}
+	if err := waitServer(t, done, time.Second); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestRunServer_ShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	srv := defaultServerConfig.newServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	}))
+	url, shutdown, done := startServer(t, srv, 100*time.Millisecond)
+
+	requestErr := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+		}
+		requestErr <- err
+	}()
+	<-started
+
+	// A request that outlasts the drain has its connection closed
+	shutdown()
+	if err := waitServer(t, done, 2*time.Second); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := <-requestErr; err == nil {
+		t.Error("Expected the stuck request to fail once its connection closed")
+	}
+}
+
+func TestRunServer_EndsSSEStreams(t *testing.T) {
+	sseTransport := transport.NewSSETransport(nil)
+	srv := defaultServerConfig.newServer(http.HandlerFunc(sseTransport.HandleSSE))
+	srv.RegisterOnShutdown(sseTransport.Shutdown)
+	url, shutdown, done := startServer(t, srv, 5*time.Second)
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	stream := bufio.NewReader(resp.Body)
+	if line, _ := stream.ReadString('\n'); line != "event: ready\n" {
+		t.Fatalf("Expected ready event, got %q", line)
+	}
+
+	// The stream is told about the shutdown and ended, so the drain needn't wait
+	// for the timeout
+	shutdown()
+	rest, _ := io.ReadAll(stream)
+	if !strings.Contains(string(rest), "event: shutdown") {
+		t.Errorf("Expected shutdown event, got %q", rest)
+	}
+	if err := waitServer(t, done, time.Second); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestLoadServerConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected serverConfig
+	}{
+		{"defaults", nil, defaultServerConfig},
+		{
+			"overrides",
+			map[string]string{
+				"MCP_HTTP_READ_HEADER_TIMEOUT": "5s",
+				"MCP_HTTP_READ_TIMEOUT":        "1m",
+				"MCP_HTTP_WRITE_TIMEOUT":       "5m",
+				"MCP_HTTP_IDLE_TIMEOUT":        "30s",
+				"MCP_HTTP_MAX_HEADER_BYTES":    "8192",
+				"MCP_HTTP_MAX_BODY_BYTES":      "4096",
+				"MCP_SHUTDOWN_TIMEOUT":         "10s",
+			},
+			serverConfig{
+				ReadHeaderTimeout: 5 * time.Second,
+				ReadTimeout:       time.Minute,
+				WriteTimeout:      5 * time.Minute,
+				IdleTimeout:       30 * time.Second,
+				MaxHeaderBytes:    8192,
+				MaxBodyBytes:      4096,
+				ShutdownTimeout:   10 * time.Second,
+			},
+		},
+		{
+			"invalid values use defaults",
+			map[string]string{
+				"MCP_HTTP_WRITE_TIMEOUT":    "forever",
+				"MCP_HTTP_MAX_BODY_BYTES":   "1MB",
+				"MCP_HTTP_MAX_HEADER_BYTES": "-1",
+				"MCP_SHUTDOWN_TIMEOUT":      "0s",
+			},
+			defaultServerConfig,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, name := range []string{
+				"MCP_HTTP_READ_HEADER_TIMEOUT", "MCP_HTTP_READ_TIMEOUT", "MCP_HTTP_WRITE_TIMEOUT",
+				"MCP_HTTP_IDLE_TIMEOUT", "MCP_HTTP_MAX_HEADER_BYTES", "MCP_HTTP_MAX_BODY_BYTES",
+				"MCP_SHUTDOWN_TIMEOUT",
+			} {
+				t.Setenv(name, test.env[name])
+			}
+			if config := loadServerConfig(); config != test.expected {
+				t.Errorf("Expected %+v, got %+v", test.expected, config)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"loanpro-mcp-server/auth"
+	"loanpro-mcp-server/loanpro"
+	"loanpro-mcp-server/tools"
+)
+
+// TenantHeader selects the LoanPro tenant for all requests on an HTTP connection
+const TenantHeader = "X-LoanPro-Tenant"
+
+// defaultTenantName names the single tenant configured from LOANPRO_* variables
+const defaultTenantName = "default"
+
+var (
+	// ErrUnknownTenant is returned when a request names a tenant that isn't configured
+	ErrUnknownTenant = errors.New("unknown tenant")
+	// ErrTenantRequired is returned when several tenants are configured without a
+	// default and the request doesn't select one
+	ErrTenantRequired = errors.New("tenant is required")
+	// ErrTenantMismatch is returned when a tool argument selects a different tenant
+	// than the one the session is pinned to
+	ErrTenantMismatch = errors.New("tenant does not match the session's tenant")
+)
+
+// TenantConfig describes one LoanPro instance in the tenants file
+type TenantConfig struct {
+	Name         string   `json:"name"`
+	APIURL       string   `json:"api_url"`
+	APIKey       string   `json:"api_key,omitempty"`
+	APIKeyFile   string   `json:"api_key_file,omitempty"` // Read instead of api_key and watched for rotation
+	TenantID     string   `json:"tenant_id"`
+	CustomFields []string `json:"custom_fields,omitempty"` // Overrides LOANPRO_CUSTOM_FIELDS
+	Timezone     string   `json:"timezone,omitempty"`      // IANA name timestamps render in, overrides LOANPRO_TIMEZONE
+}
+
+// TenantsFile is the format of the file named by LOANPRO_TENANTS_FILE
+type TenantsFile struct {
+	Default string         `json:"default,omitempty"`
+	Tenants []TenantConfig `json:"tenants"`
+}
+
+// LoadTenantsFile reads and validates a tenants file. ${VAR} references in values
+// are expanded from the environment so API keys needn't be stored in the file.
+func LoadTenantsFile(path string) (*TenantsFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %w", err)
+	}
+
+	var file TenantsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid tenants file %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range file.Tenants {
+		tenant := &file.Tenants[i]
+		tenant.APIURL = os.ExpandEnv(tenant.APIURL)
+		tenant.APIKey = os.ExpandEnv(tenant.APIKey)
+		tenant.APIKeyFile = os.ExpandEnv(tenant.APIKeyFile)
+		tenant.TenantID = os.ExpandEnv(tenant.TenantID)
+		if tenant.APIKeyFile != "" {
+			if tenant.APIKey, err = readSecretFile(tenant.APIKeyFile); err != nil {
+				return nil, fmt.Errorf("invalid tenants file %s: tenant %q api_key_file: %w", path, tenant.Name, err)
+			}
+		}
+
+		switch {
+		case tenant.Name == "":
+			return nil, fmt.Errorf("invalid tenants file %s: tenant %d has no name", path, i+1)
+		case seen[tenant.Name]:
+			return nil, fmt.Errorf("invalid tenants file %s: duplicate tenant %q", path, tenant.Name)
+		case tenant.APIURL == "" || tenant.APIKey == "" || tenant.TenantID == "":
+			return nil, fmt.Errorf("invalid tenants file %s: tenant %q needs api_url, api_key or api_key_file, and tenant_id", path, tenant.Name)
+		}
+		if tenant.Timezone != "" {
+			if _, err := time.LoadLocation(tenant.Timezone); err != nil {
+				return nil, fmt.Errorf("invalid tenants file %s: tenant %q timezone: %w", path, tenant.Name, err)
+			}
+		}
+		seen[tenant.Name] = true
+	}
+
+	if len(file.Tenants) == 0 {
+		return nil, fmt.Errorf("invalid tenants file %s: no tenants", path)
+	}
+	if file.Default != "" && !seen[file.Default] {
+		return nil, fmt.Errorf("invalid tenants file %s: default tenant %q is not defined", path, file.Default)
+	}
+	return &file, nil
+}
+
+// Tenant is one LoanPro instance with its own client, tools and counters. Tenants
+// share nothing, so one tenant's cache, rate limit and circuit breaker never affect
+// another's.
+type Tenant struct {
+	Name        string
+	client      *loanpro.Client
+	toolManager *tools.Manager
+
+	toolCalls  atomic.Int64
+	toolErrors atomic.Int64
+
+	// probe checks LoanPro for the readiness endpoint
+	probe *loanProProbe
+}
+
+// NewTenant creates a tenant served by client
+func NewTenant(name string, client *loanpro.Client) *Tenant {
+	return &Tenant{
+		Name:        name,
+		client:      client,
+		toolManager: tools.NewManager(&ClientAdapter{client: client}),
+		probe:       newLoanProProbe(defaultProbeInterval),
+	}
+}
+
+// HealthDetails reports the tenant's client state and tool call counters
+func (t *Tenant) HealthDetails() map[string]any {
+	// /health isn't authenticated, so the breaker's last error, which can hold
+	// LoanPro URLs and response bodies, is left out
+	breaker := t.client.BreakerStats()
+	breaker.LastError = ""
+	return map[string]any{
+		"cache":           t.client.CacheStats(),
+		"rate_limit":      t.client.RateLimitStats(),
+		"circuit_breaker": breaker,
+		"tool_calls":      t.toolCalls.Load(),
+		"tool_errors":     t.toolErrors.Load(),
+	}
+}
+
+// TenantRegistry holds the configured tenants
+type TenantRegistry struct {
+	tenants     map[string]*Tenant
+	names       []string
+	defaultName string
+}
+
+// NewTenantRegistry creates a registry. defaultName may be empty, in which case
+// requests must select a tenant unless only one is configured.
+func NewTenantRegistry(defaultName string, tenants ...*Tenant) *TenantRegistry {
+	r := &TenantRegistry{tenants: make(map[string]*Tenant), defaultName: defaultName}
+	for _, tenant := range tenants {
+		r.tenants[tenant.Name] = tenant
+		r.names = append(r.names, tenant.Name)
+	}
+	slices.Sort(r.names)
+	if r.defaultName == "" && len(r.names) == 1 {
+		r.defaultName = r.names[0]
+	}
+	return r
+}
+
+// Names returns the tenant names in sorted order
+func (r *TenantRegistry) Names() []string {
+	return r.names
+}
+
+// Get returns the named tenant
+func (r *TenantRegistry) Get(name string) (*Tenant, bool) {
+	tenant, ok := r.tenants[name]
+	return tenant, ok
+}
+
+// Default returns the default tenant, or nil when there is none
+func (r *TenantRegistry) Default() *Tenant {
+	return r.tenants[r.defaultName]
+}
+
+// Resolve selects the tenant for a tool call. A tenant pinned to the session wins
+// and a conflicting tool argument is rejected, so a caller can't reach a tenant other
+// than its own. Otherwise the tool argument is used, then the default tenant.
+func (r *TenantRegistry) Resolve(ctx context.Context, argument string) (*Tenant, error) {
+	name := argument
+	if pinned := tenantFromContext(ctx); pinned != "" {
+		if argument != "" && argument != pinned {
+			return nil, fmt.Errorf("%w: requested %q", ErrTenantMismatch, argument)
+		}
+		name = pinned
+	}
+
+	if name == "" {
+		if tenant := r.Default(); tenant != nil {
+			return tenant, nil
+		}
+		return nil, fmt.Errorf("%w: pass the tenant argument or the %s header (one of %s)",
+			ErrTenantRequired, TenantHeader, strings.Join(r.names, ", "))
+	}
+
+	tenant, ok := r.tenants[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownTenant, name)
+	}
+	return tenant, nil
+}
+
+// tenantContextKey is the context key for the tenant pinned to a session
+type tenantContextKey struct{}
+
+// withTenant pins the tenant for requests using ctx
+func withTenant(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, name)
+}
+
+// tenantFromContext returns the tenant pinned to ctx, or an empty string
+func tenantFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(tenantContextKey{}).(string)
+	return name
+}
+
+// tenantMiddleware pins a tenant to the request: the one the authenticated principal
+// is restricted to, otherwise the one named by the X-LoanPro-Tenant header. A header
+// naming a different tenant than the principal's is rejected.
+func tenantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSpace(r.Header.Get(TenantHeader))
+		if principal := auth.PrincipalFromContext(r.Context()); principal != nil && principal.Tenant != "" {
+			if name != "" && name != principal.Tenant {
+				http.Error(w, fmt.Sprintf("%s: %s", ErrTenantMismatch, name), http.StatusForbidden)
+				return
+			}
+			name = principal.Tenant
+		}
+
+		if name != "" {
+			r = r.WithContext(withTenant(r.Context(), name))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// addTenantProperty adds an optional tenant argument to a tool's input schema
+func addTenantProperty(tool tools.Tool, names []string) {
+	properties, ok := tool.InputSchema["properties"].(map[string]any)
+	if !ok {
+		return
+	}
+	properties["tenant"] = map[string]any{
+		"type":        "string",
+		"description": "LoanPro tenant to query. Omit to use the default tenant.",
+		"enum":        names,
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"loanpro-mcp-server/loanpro"
+	"loanpro-mcp-server/loanpro/fake"
+	"loanpro-mcp-server/tools"
+	"loanpro-mcp-server/transport"
+)
+
+func TestLoadTenantsFile(t *testing.T) {
+	t.Setenv("TEST_AUTO_KEY", "Bearer auto-key")
+	keyFile := filepath.Join(t.TempDir(), "cards-key")
+	if err := os.WriteFile(keyFile, []byte("Bearer cards-key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_CARDS_KEY_FILE", keyFile)
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string // Error substring, empty for success
+	}{
+		{
+			name: "valid",
+			content: `{"default": "auto", "tenants": [
+				{"name": "auto", "api_url": "https://auto.example", "api_key": "${TEST_AUTO_KEY}", "tenant_id": "1"},
+				{"name": "cards", "api_url": "https://cards.example", "api_key_file": "${TEST_CARDS_KEY_FILE}", "tenant_id": "2"}]}`,
+		},
+		{"invalid JSON", `{"tenants": [`, "invalid tenants file"},
+		{"missing key file", `{"tenants": [{"name": "a", "api_url": "u", "api_key_file": "/nonexistent", "tenant_id": "1"}]}`, "api_key_file"},
+		{"no tenants", `{"tenants": []}`, "no tenants"},
+		{"missing name", `{"tenants": [{"api_url": "u", "api_key": "k", "tenant_id": "1"}]}`, "has no name"},
+		{"missing key", `{"tenants": [{"name": "a", "api_url": "u", "api_key": "${TEST_UNSET_KEY}", "tenant_id": "1"}]}`, "needs api_url"},
+		{
+			"duplicate",
+			`{"tenants": [{"name": "a", "api_url": "u", "api_key": "k", "tenant_id": "1"}, {"name": "a", "api_url": "u", "api_key": "k", "tenant_id": "2"}]}`,
+			"duplicate tenant",
+		},
+		{"unknown default", `{"default": "b", "tenants": [{"name": "a", "api_url": "u", "api_key": "k", "tenant_id": "1"}]}`, "default tenant"},
+		{"unknown timezone", `{"tenants": [{"name": "a", "api_url": "u", "api_key": "k", "tenant_id": "1", "timezone": "Mars/Olympus"}]}`, "timezone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tenants.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := LoadTenantsFile(path)
+			if tt.expected != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expected) {
+					t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(file.Tenants) != 2 || file.Default != "auto" {
+				t.Errorf("Expected 2 tenants with default auto, got %+v", file)
+			}
+			if file.Tenants[0].APIKey != "Bearer auto-key" {
+				t.Errorf("Expected API key expanded from the environment, got %q", file.Tenants[0].APIKey)
+			}
+			if file.Tenants[1].APIKey != "Bearer cards-key" || file.Tenants[1].APIKeyFile != keyFile {
+				t.Errorf("Expected API key read from file, got %+v", file.Tenants[1])
+			}
+		})
+	}
+}
+
+func TestTenantRegistry_Resolve(t *testing.T) {
+	auto := NewTenant("auto", &loanpro.Client{})
+	cards := NewTenant("cards", &loanpro.Client{})
+	withDefault := NewTenantRegistry("auto", auto, cards)
+	withoutDefault := NewTenantRegistry("", auto, cards)
+
+	tests := []struct {
+		name     string
+		registry *TenantRegistry
+		pinned   string
+		argument string
+		expected *Tenant
+		err      error
+	}{
+		{"default tenant", withDefault, "", "", auto, nil},
+		{"argument", withDefault, "", "cards", cards, nil},
+		{"pinned", withDefault, "cards", "", cards, nil},
+		{"pinned matching argument", withDefault, "cards", "cards", cards, nil},
+		{"pinned conflicting argument", withDefault, "cards", "auto", nil, ErrTenantMismatch},
+		{"unknown argument", withDefault, "", "mortgage", nil, ErrUnknownTenant},
+		{"unknown pinned", withDefault, "mortgage", "", nil, ErrUnknownTenant},
+		{"no default", withoutDefault, "", "", nil, ErrTenantRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.pinned != "" {
+				ctx = withTenant(ctx, tt.pinned)
+			}
+
+			tenant, err := tt.registry.Resolve(ctx, tt.argument)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if tenant != tt.expected {
+				t.Errorf("Expected tenant %v, got %v", tt.expected, tenant)
+			}
+		})
+	}
+}
+
+func TestMCPServer_Tenants(t *testing.T) {
+	autoFake := fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID)
+	autoServer := httptest.NewServer(autoFake)
+	defer autoServer.Close()
+	cardsFake := fake.NewServer("Bearer cards-key", "cards-tenant")
+	cardsServer := httptest.NewServer(cardsFake)
+	defer cardsServer.Close()
+
+	server := NewMCPServerWithTenants(NewTenantRegistry("",
+		NewTenant("auto", loanpro.NewClient(autoServer.URL, fake.DefaultAPIKey, fake.DefaultTenantID)),
+		NewTenant("cards", loanpro.NewClient(cardsServer.URL, "Bearer cards-key", "cards-tenant")),
+	))
+
+	call := func(ctx context.Context, arguments map[string]any) transport.MCPResponse {
+		return server.HandleMCPRequestContext(ctx, transport.MCPRequest{
+			JSONRPC: "2.0",
+			Method:  "tools/call",
+			Params:  map[string]any{"name": "get_loan", "arguments": arguments},
+			ID:      1,
+		})
+	}
+
+	// Without a default the caller has to choose
+	if response := call(context.Background(), map[string]any{"loan_id": "101"}); response.Error == nil || response.Error.Code != -32602 {
+		t.Errorf("Expected invalid params error without a tenant, got %+v", response)
+	}
+
+	if response := call(context.Background(), map[string]any{"loan_id": "101", "tenant": "cards"}); response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error.Message)
+	}
+	if response := call(withTenant(context.Background(), "auto"), map[string]any{"loan_id": "101"}); response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error.Message)
+	}
+	if response := call(withTenant(context.Background(), "auto"), map[string]any{"loan_id": "101", "tenant": "cards"}); response.Error == nil {
+		t.Error("Expected a pinned session not to reach another tenant")
+	}
+
+	// Each tenant's API saw only the call made to that tenant
+	for name, tenant := range map[string]*fake.Server{"auto": autoFake, "cards": cardsFake} {
+		loans := 0
+		for _, req := range tenant.Requests() {
+			if strings.HasSuffix(req.Path, "Loans(101)") {
+				loans++
+			}
+		}
+		if loans != 1 {
+			t.Errorf("Expected 1 loan request to %s, got %d", name, loans)
+		}
+	}
+
+	details := server.HealthDetails()
+	tenants, ok := details["tenants"].(map[string]any)
+	if !ok || len(tenants) != 2 {
+		t.Fatalf("Expected per-tenant health details, got %v", details)
+	}
+	for name, calls := range map[string]int64{"auto": 1, "cards": 1} {
+		if got := tenants[name].(map[string]any)["tool_calls"]; got != calls {
+			t.Errorf("Expected %d tool calls for %s, got %v", calls, name, got)
+		}
+	}
+}
+
+func TestMCPServer_ToolsList_Tenants(t *testing.T) {
+	server := NewMCPServerWithTenants(NewTenantRegistry("auto",
+		NewTenant("auto", &loanpro.Client{}),
+		NewTenant("cards", &loanpro.Client{}),
+	))
+	list := func(ctx context.Context) map[string]any {
+		response := server.HandleMCPRequestContext(ctx, transport.MCPRequest{JSONRPC: "2.0", Method: "tools/list", ID: 1})
+		toolsList := response.Result.(map[string]any)["tools"].([]tools.Tool)
+		return toolsList[0].InputSchema["properties"].(map[string]any)
+	}
+
+	if _, ok := list(context.Background())["tenant"]; !ok {
+		t.Error("Expected tools to advertise the tenant argument")
+	}
+	if _, ok := list(withTenant(context.Background(), "auto"))["tenant"]; ok {
+		t.Error("Expected no tenant argument when the session is pinned")
+	}
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	var pinned string
+	handler := tenantMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pinned = tenantFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest("POST", "/mcp", nil)
+	req.Header.Set(TenantHeader, " cards ")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if pinned != "cards" {
+		t.Errorf("Expected tenant cards from header, got %q", pinned)
+	}
+}
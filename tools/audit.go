@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"time"
+)
+
+// ErrorCodeAuditFailed is the JSON-RPC error code for tool calls whose audit entry
+// couldn't be written
+const ErrorCodeAuditFailed = -32603
+
+// Invocation is a finished tool call, as reported to an Auditor
+type Invocation struct {
+	Tool        string
+	Arguments   map[string]any // As run, e.g. with a policy's portfolio filled in
+	LoanIDs     []string       // Loans whose data the call returned
+	CustomerIDs []string       // Customers whose data the call returned
+	Error       *MCPError      // nil when the call succeeded
+	Duration    time.Duration
+}
+
+// Auditor records tool calls. When an entry can't be recorded, the call's result is
+// withheld from the caller.
+type Auditor interface {
+	AuditToolCall(ctx context.Context, call Invocation) error
+}
+
+// accessed collects the IDs of the records a tool call returns
+type accessed struct {
+	loans     []string
+	customers []string
+}
+
+// touchLoan notes that a tool call returned a loan's data
+func (m *Manager) touchLoan(id string) {
+	if m.accessed != nil {
+		m.accessed.loans = append(m.accessed.loans, id)
+	}
+}
+
+// touchCustomer notes that a tool call returned a customer's data
+func (m *Manager) touchCustomer(id string) {
+	if m.accessed != nil {
+		m.accessed.customers = append(m.accessed.customers, id)
+	}
+}
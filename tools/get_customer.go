@@ -1,6 +1,9 @@
 package tools
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+)
 
 // GetCustomerTool returns the get_customer tool definition
 func GetCustomerTool() Tool {
@@ -14,6 +17,7 @@ func GetCustomerTool() Tool {
 					"type":        "string",
 					"description": "The customer ID to retrieve",
 				},
+				"fresh": freshProperty(),
 			},
 			"required": []string{"customer_id"},
 		},
@@ -22,15 +26,21 @@ func GetCustomerTool() Tool {
 
 // executeGetCustomer handles the get_customer tool execution
 func (m *Manager) executeGetCustomer(arguments map[string]any) MCPResponse {
-	customerID := arguments["customer_id"].(string)
-	customer, err := m.client.GetCustomer(customerID)
+	customerID, invalid := requiredString(arguments, "customer_id")
+	if invalid != nil {
+		return *invalid
+	}
+	customer, err := m.clientFor(arguments).GetCustomer(customerID)
 	if err != nil {
-		LogError("get_customer", err, fmt.Sprintf("for ID %s", customerID))
+		LogError(m.context(), "get_customer", err, fmt.Sprintf("for ID %s", customerID))
 		return CreateErrorResponse(-1, err.Error(), nil)
 	}
 
-	text := fmt.Sprintf("Customer Details:\nID: %d\nName: %s %s\nEmail: %s\nPhone: %s\nCreated: %s",
-		customer.GetID(), customer.GetFirstName(), customer.GetLastName(), customer.GetEmail(), customer.GetPhone(), customer.GetCreatedDate())
+	m.touchCustomer(strconv.Itoa(customer.GetID()))
+	text := fmt.Sprintf("Customer Details:\nID: %d\nName: %s\nEmail: %s\nPhone: %s\nCreated: %s",
+		customer.GetID(), m.show(FieldCustomerName, customer.GetFirstName()+" "+customer.GetLastName()),
+		m.show(FieldEmail, customer.GetEmail()), m.show(FieldPhone, customer.GetPhone()), customer.GetCreatedDate())
+	text += FormatCustomFields(customer.GetCustomFields(), m.visibleCustomFields())
 
 	return CreateSuccessResponse(text, nil)
 }
@@ -14,6 +14,7 @@ func GetLoanTool() Tool {
 					"type":        "string",
 					"description": "The loan ID to retrieve",
 				},
+				"fresh": freshProperty(),
 			},
 			"required": []string{"loan_id"},
 		},
@@ -22,15 +23,24 @@ func GetLoanTool() Tool {
 
 // executeGetLoan handles the get_loan tool execution
 func (m *Manager) executeGetLoan(arguments map[string]any) MCPResponse {
-	loanID := arguments["loan_id"].(string)
-	loan, err := m.client.GetLoan(loanID)
+	loanID, invalid := requiredString(arguments, "loan_id")
+	if invalid != nil {
+		return *invalid
+	}
+	loan, err := m.clientFor(arguments).GetLoan(loanID)
 	if err != nil {
-		LogError("get_loan", err, fmt.Sprintf("for ID %s", loanID))
+		LogError(m.context(), "get_loan", err, fmt.Sprintf("for ID %s", loanID))
 		return CreateErrorResponse(-1, err.Error(), nil)
 	}
+	// Checked on the loan returned, so the check can't be skipped or see another loan
+	if denied := m.checkLoanPortfolios(loanID, loan); denied != nil {
+		return *denied
+	}
 
+	m.touchLoan(loan.GetID())
 	text := fmt.Sprintf("Loan Details:\nID: %s\nDisplay ID: %s\nStatus: %s\nCustomer: %s\nBalance: $%s\nPayoff: $%s",
-		loan.GetID(), loan.GetDisplayID(), loan.GetLoanStatus(), loan.GetPrimaryCustomerName(), loan.GetPrincipalBalance(), loan.GetPayoffAmount())
+		loan.GetID(), loan.GetDisplayID(), loan.GetLoanStatus(), m.show(FieldCustomerName, loan.GetPrimaryCustomerName()), loan.GetPrincipalBalance(), loan.GetPayoffAmount())
+	text += FormatCustomFields(loan.GetCustomFields(), m.visibleCustomFields())
 
 	return CreateSuccessResponse(text, nil)
 }
@@ -14,6 +14,7 @@ func GetLoanPaymentsTool() Tool {
 					"type":        "string",
 					"description": "The loan ID to get payment history for",
 				},
+				"fresh": freshProperty(),
 			},
 			"required": []string{"loan_id"},
 		},
@@ -22,13 +23,20 @@ func GetLoanPaymentsTool() Tool {
 
 // executeGetLoanPayments handles the get_loan_payments tool execution
 func (m *Manager) executeGetLoanPayments(arguments map[string]any) MCPResponse {
-	loanID := arguments["loan_id"].(string)
-	payments, err := m.client.GetLoanPayments(loanID)
+	loanID, invalid := requiredString(arguments, "loan_id")
+	if invalid != nil {
+		return *invalid
+	}
+	if denied := m.authorizeLoan("get_loan_payments", loanID, arguments); denied != nil {
+		return *denied
+	}
+	payments, err := m.clientFor(arguments).GetLoanPayments(loanID)
 	if err != nil {
-		LogError("get_loan_payments", err, fmt.Sprintf("for loan ID %s", loanID))
+		LogError(m.context(), "get_loan_payments", err, fmt.Sprintf("for loan ID %s", loanID))
 		return CreateErrorResponse(-1, err.Error(), nil)
 	}
 
+	m.touchLoan(loanID)
 	text := fmt.Sprintf("Payment History for Loan %s:\n", loanID)
 	if len(payments) == 0 {
 		text += "No payments found.\n"
@@ -22,6 +22,7 @@ func GetLoanTransactionsTool() Tool {
 					"type":        "number",
 					"description": "Number of transactions to skip (pagination). Use with 'limit' for pagination. For example: offset=0 gets first page, offset=50 gets second page (with limit=50).",
 				},
+				"fresh": freshProperty(),
 			},
 			"required": []string{"loan_id"},
 		},
@@ -30,7 +31,13 @@ func GetLoanTransactionsTool() Tool {
 
 // executeGetLoanTransactions handles the get_loan_transactions tool execution
 func (m *Manager) executeGetLoanTransactions(arguments map[string]any) MCPResponse {
-	loanID := arguments["loan_id"].(string)
+	loanID, invalid := requiredString(arguments, "loan_id")
+	if invalid != nil {
+		return *invalid
+	}
+	if denied := m.authorizeLoan("get_loan_transactions", loanID, arguments); denied != nil {
+		return *denied
+	}
 
 	// Get pagination parameters if provided
 	var limit, offset int
@@ -55,18 +62,19 @@ func (m *Manager) executeGetLoanTransactions(arguments map[string]any) MCPRespon
 			Limit:  limit,
 			Offset: offset,
 		}
-		transactions, err = m.client.GetLoanTransactionsWithOptions(loanID, opts)
+		transactions, err = m.clientFor(arguments).GetLoanTransactionsWithOptions(loanID, opts)
 	} else {
 		// No pagination
-		transactions, err = m.client.GetLoanTransactions(loanID)
+		transactions, err = m.clientFor(arguments).GetLoanTransactions(loanID)
 	}
 
 	if err != nil {
-		LogError("get_loan_transactions", err, fmt.Sprintf("for loan ID %s", loanID))
+		LogError(m.context(), "get_loan_transactions", err, fmt.Sprintf("for loan ID %s", loanID))
 		return CreateErrorResponse(-1, err.Error(), nil)
 	}
 
 	// Build response text with pagination info
+	m.touchLoan(loanID)
 	text := fmt.Sprintf("Transaction History for Loan %s:\n", loanID)
 	if limit > 0 {
 		text += fmt.Sprintf("(Showing up to %d transactions, starting at offset %d)\n\n", limit, offset)
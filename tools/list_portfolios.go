@@ -0,0 +1,57 @@
+package tools
+
+import "fmt"
+
+// ListPortfoliosTool returns the list_portfolios tool definition
+func ListPortfoliosTool() Tool {
+	return Tool{
+		Name:        "list_portfolios",
+		Description: "List loan portfolios and their sub-portfolios. Use the returned IDs as portfolio_id or sub_portfolio_id filters in search_loans.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"include_inactive": map[string]any{
+					"type":        "boolean",
+					"description": "Include inactive portfolios and sub-portfolios",
+					"default":     false,
+				},
+				"fresh": freshProperty(),
+			},
+		},
+	}
+}
+
+// executeListPortfolios handles the list_portfolios tool execution
+func (m *Manager) executeListPortfolios(arguments map[string]any) MCPResponse {
+	includeInactive := false
+	if v, ok := arguments["include_inactive"].(bool); ok {
+		includeInactive = v
+	}
+
+	portfolios, err := m.clientFor(arguments).GetPortfolios()
+	if err != nil {
+		LogError(m.context(), "list_portfolios", err, "")
+		return CreateErrorResponse(-1, err.Error(), nil)
+	}
+
+	text := "Portfolios:\n"
+	count := 0
+	for _, portfolio := range portfolios {
+		if (!includeInactive && !portfolio.IsActive()) || !m.policy.AllowsPortfolio(portfolio.GetID()) {
+			continue
+		}
+		count++
+		text += fmt.Sprintf("- ID: %s, Title: %s\n", portfolio.GetID(), portfolio.GetTitle())
+		for _, sub := range portfolio.GetSubPortfolios() {
+			if !includeInactive && !sub.IsActive() {
+				continue
+			}
+			text += fmt.Sprintf("  - Sub-portfolio ID: %s, Title: %s\n", sub.GetID(), sub.GetTitle())
+		}
+	}
+	if count == 0 {
+		text += "No portfolios found.\n"
+	}
+
+	return CreateSuccessResponse(text, nil)
+}
@@ -1,8 +1,30 @@
 package tools
 
+import (
+	"context"
+	"maps"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"loanpro-mcp-server/redact"
+)
+
+// tracer creates the spans of tool calls
+var tracer = otel.Tracer("loanpro-mcp-server/tools")
+
 // Manager handles MCP tool operations
 type Manager struct {
-	client LoanProClient
+	client       LoanProClient
+	customFields []string         // custom field names included in tool output
+	redactor     *redact.Redactor // masks personal data in output unless the caller's policy says otherwise
+	auditor      Auditor          // records every tool call, nil when auditing is off
+	policy       *Policy          // caller's policy during ExecuteToolContext, nil when unrestricted
+	accessed     *accessed        // records returned during ExecuteToolContext, nil when auditing is off
+	ctx          context.Context  // context of the call during ExecuteToolContext
 }
 
 // NewManager creates a new tool manager
@@ -12,6 +34,46 @@ func NewManager(client LoanProClient) *Manager {
 	}
 }
 
+// SetRedactor configures the personal data masked in the output of callers whose
+// policy doesn't choose its own redaction
+func (m *Manager) SetRedactor(redactor *redact.Redactor) {
+	m.redactor = redactor
+}
+
+// SetAuditor configures where tool calls are recorded
+func (m *Manager) SetAuditor(auditor Auditor) {
+	m.auditor = auditor
+}
+
+// SetCustomFields configures which custom fields are included in loan and customer output
+func (m *Manager) SetCustomFields(names []string) {
+	m.customFields = names
+}
+
+// clientFor returns the client for a tool call, bypassing the response cache when
+// the call passes fresh: true
+func (m *Manager) clientFor(arguments map[string]any) LoanProClient {
+	client := m.client
+	if fresh, _ := arguments["fresh"].(bool); fresh {
+		if freshClient, ok := client.(FreshClient); ok {
+			client = freshClient.Fresh()
+		}
+	}
+	if contextClient, ok := client.(ContextClient); ok && m.ctx != nil {
+		client = contextClient.WithContext(m.ctx)
+	}
+	return client
+}
+
+// context returns the context of the call during ExecuteToolContext, or the
+// background context
+func (m *Manager) context() context.Context {
+	if m.ctx != nil {
+		return m.ctx
+	}
+	return context.Background()
+}
+
 // GetAllTools returns all available MCP tools
 func (m *Manager) GetAllTools() []Tool {
 	return []Tool{
@@ -21,11 +83,87 @@ func (m *Manager) GetAllTools() []Tool {
 		SearchCustomersTool(),
 		GetLoanPaymentsTool(),
 		GetLoanTransactionsTool(),
+		ListPortfoliosTool(),
+	}
+}
+
+// ListTools returns the tools the caller in ctx may run
+func (m *Manager) ListTools(ctx context.Context) []Tool {
+	policy := PolicyFromContext(ctx)
+	var allowed []Tool
+	for _, tool := range m.GetAllTools() {
+		if policy.AllowsTool(tool.Name) {
+			allowed = append(allowed, tool)
+		}
 	}
+	return allowed
 }
 
 // ExecuteTool executes the specified tool with given arguments
 func (m *Manager) ExecuteTool(toolName string, arguments map[string]any) MCPResponse {
+	return m.ExecuteToolContext(context.Background(), toolName, arguments)
+}
+
+// ExecuteToolContext executes a tool for the caller in ctx. Calls the caller's
+// policy doesn't allow get a permission denied error, fields it hides are withheld
+// from the output, and personal data is masked in the output and error messages.
+// Every call, allowed or not, is recorded by the auditor.
+func (m *Manager) ExecuteToolContext(ctx context.Context, toolName string, arguments map[string]any) MCPResponse {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "execute_tool "+toolName, trace.WithAttributes(attribute.String("gen_ai.tool.name", toolName)))
+	defer span.End()
+
+	// Work on a copy so the policy, context and records accessed stay with this call
+	scoped := *m
+	m = &scoped
+	m.ctx = ctx
+	if m.auditor != nil {
+		m.accessed = &accessed{}
+	}
+
+	redactor := m.redactor
+	if policy := PolicyFromContext(ctx); policy != nil {
+		m.policy = policy
+		arguments = maps.Clone(arguments)
+		if policy.Redactor != nil {
+			redactor = policy.Redactor
+		}
+	}
+
+	var response MCPResponse
+	if denied := m.authorize(toolName, arguments); denied != nil {
+		response = *denied
+	} else {
+		response = m.execute(toolName, arguments)
+		response.Result = redactor.Value(response.Result)
+		if response.Error != nil {
+			response.Error.Message = redactor.String(response.Error.Message)
+		}
+	}
+
+	if response.Error != nil {
+		span.SetStatus(codes.Error, redact.Default().String(response.Error.Message))
+	}
+
+	if m.auditor != nil {
+		call := Invocation{
+			Tool:        toolName,
+			Arguments:   arguments,
+			LoanIDs:     m.accessed.loans,
+			CustomerIDs: m.accessed.customers,
+			Error:       response.Error,
+			Duration:    time.Since(start),
+		}
+		if err := m.auditor.AuditToolCall(ctx, call); err != nil {
+			LogError(ctx, toolName, err, "recording audit entry")
+			return CreateErrorResponse(ErrorCodeAuditFailed, "Tool call could not be recorded in the audit log", nil)
+		}
+	}
+	return response
+}
+
+// execute runs a tool
+func (m *Manager) execute(toolName string, arguments map[string]any) MCPResponse {
 	switch toolName {
 	case "get_loan":
 		return m.executeGetLoan(arguments)
@@ -39,6 +177,8 @@ func (m *Manager) ExecuteTool(toolName string, arguments map[string]any) MCPResp
 		return m.executeGetLoanPayments(arguments)
 	case "get_loan_transactions":
 		return m.executeGetLoanTransactions(arguments)
+	case "list_portfolios":
+		return m.executeListPortfolios(arguments)
 	default:
 		return MCPResponse{
 			JSONRPC: "2.0",
@@ -1,8 +1,15 @@
 package tools
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
+
+	"loanpro-mcp-server/redact"
 )
 
 // MockLoanProClient implements the LoanProClient interface for testing
@@ -11,6 +18,11 @@ type MockLoanProClient struct {
 	customers    map[string]MockCustomer
 	payments     map[string][]MockPayment
 	transactions map[string][]MockTransaction
+	portfolios   []MockPortfolio
+
+	lastLoanSearch     *LoanSearchOptions
+	lastCustomerSearch *CustomerSearchOptions
+	customerSearchErr  error
 }
 
 // MockLoan implements the Loan interface
@@ -21,30 +33,55 @@ type MockLoan struct {
 	loanStatus          string
 	principalBalance    string
 	payoffAmount        string
+	portfolioID         string
+	customFields        map[string]any
 }
 
-func (m MockLoan) GetID() string                  { return m.id }
-func (m MockLoan) GetDisplayID() string           { return m.displayID }
-func (m MockLoan) GetPrimaryCustomerName() string { return m.primaryCustomerName }
-func (m MockLoan) GetLoanStatus() string          { return m.loanStatus }
-func (m MockLoan) GetPrincipalBalance() string    { return m.principalBalance }
-func (m MockLoan) GetPayoffAmount() string        { return m.payoffAmount }
+func (m MockLoan) GetID() string                   { return m.id }
+func (m MockLoan) GetDisplayID() string            { return m.displayID }
+func (m MockLoan) GetPrimaryCustomerName() string  { return m.primaryCustomerName }
+func (m MockLoan) GetLoanStatus() string           { return m.loanStatus }
+func (m MockLoan) GetPrincipalBalance() string     { return m.principalBalance }
+func (m MockLoan) GetPayoffAmount() string         { return m.payoffAmount }
+func (m MockLoan) GetCustomFields() map[string]any { return m.customFields }
+func (m MockLoan) GetPortfolioIDs() []string       { return []string{m.portfolioID} }
 
 // MockCustomer implements the Customer interface
 type MockCustomer struct {
-	id        int
-	firstName string
-	lastName  string
-	email     string
-	phone     string
+	id           int
+	firstName    string
+	lastName     string
+	email        string
+	phone        string
+	customFields map[string]any
 }
 
-func (m MockCustomer) GetID() int             { return m.id }
-func (m MockCustomer) GetFirstName() string   { return m.firstName }
-func (m MockCustomer) GetLastName() string    { return m.lastName }
-func (m MockCustomer) GetEmail() string       { return m.email }
-func (m MockCustomer) GetPhone() string       { return m.phone }
-func (m MockCustomer) GetCreatedDate() string { return "2025-01-01 00:00:00 UTC" }
+func (m MockCustomer) GetID() int                      { return m.id }
+func (m MockCustomer) GetFirstName() string            { return m.firstName }
+func (m MockCustomer) GetLastName() string             { return m.lastName }
+func (m MockCustomer) GetEmail() string                { return m.email }
+func (m MockCustomer) GetPhone() string                { return m.phone }
+func (m MockCustomer) GetCreatedDate() string          { return "2025-01-01 00:00:00 UTC" }
+func (m MockCustomer) GetCustomFields() map[string]any { return m.customFields }
+
+// MockPortfolio implements the Portfolio interface
+type MockPortfolio struct {
+	id            string
+	title         string
+	active        bool
+	subPortfolios []MockPortfolio
+}
+
+func (m MockPortfolio) GetID() string    { return m.id }
+func (m MockPortfolio) GetTitle() string { return m.title }
+func (m MockPortfolio) IsActive() bool   { return m.active }
+func (m MockPortfolio) GetSubPortfolios() []SubPortfolio {
+	var result []SubPortfolio
+	for _, sub := range m.subPortfolios {
+		result = append(result, sub)
+	}
+	return result
+}
 
 // MockPayment implements the Payment interface
 type MockPayment struct {
@@ -112,20 +149,51 @@ func (m *MockLoanProClient) GetLoan(id string) (Loan, error) {
 }
 
 func (m *MockLoanProClient) SearchLoans(searchTerm, status string, limit int) ([]Loan, error) {
-	var results []Loan
-	count := 0
-	for _, loan := range m.loans {
-		if count >= limit {
-			break
-		}
-		if searchTerm == "" || loan.displayID == searchTerm || loan.primaryCustomerName == searchTerm {
-			if status == "" || loan.loanStatus == status {
-				results = append(results, loan)
-				count++
+	result, err := m.SearchLoansWithMetadata(&LoanSearchOptions{SearchTerm: searchTerm, Status: status, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return result.Loans, nil
+}
+
+func (m *MockLoanProClient) SearchLoansWithMetadata(opts *LoanSearchOptions) (*LoanSearchResult, error) {
+	m.lastLoanSearch = opts
+
+	// Sort IDs so pagination is deterministic
+	ids := make([]string, 0, len(m.loans))
+	for id := range m.loans {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var matches []Loan
+	for _, id := range ids {
+		loan := m.loans[id]
+		if opts.SearchTerm == "" || loan.displayID == opts.SearchTerm || loan.primaryCustomerName == opts.SearchTerm {
+			if (opts.Status == "" || loan.loanStatus == opts.Status) && (opts.PortfolioID == "" || loan.portfolioID == opts.PortfolioID) {
+				matches = append(matches, loan)
 			}
 		}
 	}
-	return results, nil
+
+	// The mock cursor is simply the offset of the next page
+	offset, err := strconv.Atoi(opts.Cursor)
+	if opts.Cursor != "" && err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if offset > len(matches) {
+		offset = len(matches)
+	}
+	end := len(matches)
+	if opts.Limit > 0 && offset+opts.Limit < end {
+		end = offset + opts.Limit
+	}
+
+	result := &LoanSearchResult{Loans: matches[offset:end], TotalHits: len(matches)}
+	if end < len(matches) {
+		result.NextCursor = strconv.Itoa(end)
+	}
+	return result, nil
 }
 
 func (m *MockLoanProClient) GetCustomer(id string) (Customer, error) {
@@ -136,18 +204,39 @@ func (m *MockLoanProClient) GetCustomer(id string) (Customer, error) {
 }
 
 func (m *MockLoanProClient) SearchCustomers(searchTerm string, limit int) ([]Customer, error) {
-	var results []Customer
-	count := 0
+	result, err := m.SearchCustomersWithMetadata(&CustomerSearchOptions{Name: searchTerm, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return result.Customers, nil
+}
+
+func (m *MockLoanProClient) SearchCustomersWithMetadata(opts *CustomerSearchOptions) (*CustomerSearchResult, error) {
+	m.lastCustomerSearch = opts
+	if m.customerSearchErr != nil {
+		return nil, m.customerSearchErr
+	}
+
+	var matches []Customer
 	for _, customer := range m.customers {
-		if count >= limit {
-			break
+		if opts.Name != "" && customer.firstName != opts.Name && customer.lastName != opts.Name {
+			continue
 		}
-		if searchTerm == "" || customer.firstName == searchTerm || customer.lastName == searchTerm {
-			results = append(results, customer)
-			count++
+		if opts.Email != "" && customer.email != opts.Email {
+			continue
 		}
+		matches = append(matches, customer)
+	}
+
+	offset := opts.Offset
+	if offset > len(matches) {
+		offset = len(matches)
+	}
+	end := len(matches)
+	if opts.Limit > 0 && offset+opts.Limit < end {
+		end = offset + opts.Limit
 	}
-	return results, nil
+	return &CustomerSearchResult{Customers: matches[offset:end], TotalHits: len(matches)}, nil
 }
 
 func (m *MockLoanProClient) GetLoanPayments(loanID string) ([]Payment, error) {
@@ -192,6 +281,14 @@ func (m *MockLoanProClient) GetLoanTransactionsWithOptions(loanID string, opts *
 	return []Transaction{}, nil
 }
 
+func (m *MockLoanProClient) GetPortfolios() ([]Portfolio, error) {
+	var result []Portfolio
+	for _, portfolio := range m.portfolios {
+		result = append(result, portfolio)
+	}
+	return result, nil
+}
+
 // Helper function to create a mock client with test data
 func createMockClient() *MockLoanProClient {
 	return &MockLoanProClient{
@@ -203,6 +300,11 @@ func createMockClient() *MockLoanProClient {
 				loanStatus:          "Active",
 				principalBalance:    "25000.00",
 				payoffAmount:        "25250.00",
+				portfolioID:         "1",
+				customFields: map[string]any{
+					"FICO at Origination": float64(712),
+					"Channel":             "Partner",
+				},
 			},
 			"456": {
 				id:                  "456",
@@ -211,6 +313,7 @@ func createMockClient() *MockLoanProClient {
 				loanStatus:          "Current",
 				principalBalance:    "18500.00",
 				payoffAmount:        "18650.00",
+				portfolioID:         "2",
 			},
 		},
 		customers: map[string]MockCustomer{
@@ -228,6 +331,18 @@ func createMockClient() *MockLoanProClient {
 				{id: "p2", amount: "500.00", date: "2025-02-15", status: "Active"},
 			},
 		},
+		portfolios: []MockPortfolio{
+			{
+				id:     "1",
+				title:  "Partner Bank",
+				active: true,
+				subPortfolios: []MockPortfolio{
+					{id: "10", title: "2025-A Securitization", active: true},
+					{id: "11", title: "2019-A Securitization", active: false},
+				},
+			},
+			{id: "2", title: "Legacy Program", active: false},
+		},
 		transactions: map[string][]MockTransaction{
 			"123": {
 				{
@@ -259,7 +374,7 @@ func TestManager_GetAllTools(t *testing.T) {
 
 	tools := manager.GetAllTools()
 
-	expectedTools := []string{"get_loan", "search_loans", "get_customer", "search_customers", "get_loan_payments", "get_loan_transactions"}
+	expectedTools := []string{"get_loan", "search_loans", "get_customer", "search_customers", "get_loan_payments", "get_loan_transactions", "list_portfolios"}
 
 	if len(tools) != len(expectedTools) {
 		t.Errorf("Expected %d tools, got %d", len(expectedTools), len(tools))
@@ -300,6 +415,84 @@ func TestManager_ExecuteTool_GetLoan(t *testing.T) {
 	}
 }
 
+func TestManager_ExecuteTool_GetLoan_CustomFields(t *testing.T) {
+	mockClient := createMockClient()
+	manager := NewManager(mockClient)
+	manager.SetCustomFields([]string{"FICO at Origination", "Partner ID", "Channel"})
+
+	response := manager.ExecuteTool("get_loan", map[string]any{"loan_id": "123"})
+
+	if response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+
+	result := response.Result.(map[string]any)
+	text := result["content"].([]map[string]any)[0]["text"].(string)
+
+	if !strings.Contains(text, "Custom Fields:\n  FICO at Origination: 712\n  Channel: Partner") {
+		t.Errorf("Expected configured custom fields in order, got: %s", text)
+	}
+
+	// Unset fields are omitted rather than printed empty
+	if strings.Contains(text, "Partner ID") {
+		t.Errorf("Expected unset custom field to be omitted, got: %s", text)
+	}
+
+	// Without configuration no custom field section is rendered
+	response = NewManager(mockClient).ExecuteTool("get_loan", map[string]any{"loan_id": "123"})
+	text = response.Result.(map[string]any)["content"].([]map[string]any)[0]["text"].(string)
+	if strings.Contains(text, "Custom Fields") {
+		t.Errorf("Expected no custom fields without configuration, got: %s", text)
+	}
+}
+
+// cachingMockClient serves possibly stale data and returns fresh from Fresh
+type cachingMockClient struct {
+	*MockLoanProClient
+	fresh *MockLoanProClient
+}
+
+func (c *cachingMockClient) Fresh() LoanProClient { return c.fresh }
+
+func TestManager_ExecuteTool_Fresh(t *testing.T) {
+	stale := createMockClient()
+	current := createMockClient()
+	loan := current.loans["123"]
+	loan.loanStatus = "Paid Off"
+	current.loans["123"] = loan
+
+	manager := NewManager(&cachingMockClient{MockLoanProClient: stale, fresh: current})
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		expected  string
+	}{
+		{"Cached by default", map[string]any{"loan_id": "123"}, "Status: Active"},
+		{"Fresh false", map[string]any{"loan_id": "123", "fresh": false}, "Status: Active"},
+		{"Fresh true", map[string]any{"loan_id": "123", "fresh": true}, "Status: Paid Off"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := manager.ExecuteTool("get_loan", tt.arguments)
+			if response.Error != nil {
+				t.Fatalf("Expected no error, got %v", response.Error)
+			}
+			text := response.Result.(map[string]any)["content"].([]map[string]any)[0]["text"].(string)
+			if !strings.Contains(text, tt.expected) {
+				t.Errorf("Expected %q in output, got %s", tt.expected, text)
+			}
+		})
+	}
+
+	// Clients without a cache ignore the argument
+	response := NewManager(stale).ExecuteTool("get_loan", map[string]any{"loan_id": "123", "fresh": true})
+	if response.Error != nil {
+		t.Errorf("Expected no error, got %v", response.Error)
+	}
+}
+
 func TestManager_ExecuteTool_SearchLoans(t *testing.T) {
 	mockClient := createMockClient()
 	manager := NewManager(mockClient)
@@ -320,6 +513,128 @@ func TestManager_ExecuteTool_SearchLoans(t *testing.T) {
 	}
 }
 
+func TestManager_ExecuteTool_SearchLoans_PortfolioFilter(t *testing.T) {
+	mockClient := createMockClient()
+	manager := NewManager(mockClient)
+
+	arguments := map[string]any{
+		"portfolio_id": "2",
+		"limit":        float64(10),
+	}
+
+	response := manager.ExecuteTool("search_loans", arguments)
+
+	if response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+
+	text := response.Result.(map[string]any)["content"].([]map[string]any)[0]["text"].(string)
+	if !strings.Contains(text, "LN00000456") {
+		t.Errorf("Expected loan in portfolio 2, got: %s", text)
+	}
+	if strings.Contains(text, "LN00000123") {
+		t.Errorf("Expected loan outside portfolio 2 to be filtered, got: %s", text)
+	}
+}
+
+func TestManager_ExecuteTool_SearchLoans_FiltersAndPagination(t *testing.T) {
+	mockClient := createMockClient()
+	manager := NewManager(mockClient)
+
+	arguments := map[string]any{
+		"sub_status":        "Collections",
+		"min_balance":       float64(1000),
+		"max_days_past_due": float64(90),
+		"created_after":     "2025-01-01",
+		"sort_by":           "balance",
+		"sort_order":        "desc",
+		"limit":             float64(1),
+	}
+
+	response := manager.ExecuteTool("search_loans", arguments)
+	if response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+
+	opts := mockClient.lastLoanSearch
+	if opts.SubStatus != "Collections" || opts.CreatedAfter != "2025-01-01" {
+		t.Errorf("Expected text filters to be passed through, got %+v", opts)
+	}
+	if opts.MinBalance == nil || *opts.MinBalance != 1000 || opts.MaxBalance != nil {
+		t.Errorf("Expected only min balance to be set, got %+v", opts)
+	}
+	if opts.MaxDaysPastDue == nil || *opts.MaxDaysPastDue != 90 {
+		t.Errorf("Expected max days past due 90, got %+v", opts)
+	}
+	if opts.SortBy != "principalBalance" || !opts.SortDescending {
+		t.Errorf("Expected sort by principalBalance desc, got %q desc=%v", opts.SortBy, opts.SortDescending)
+	}
+
+	text := response.Result.(map[string]any)["content"].([]map[string]any)[0]["text"].(string)
+	if !strings.Contains(text, "Showing 1 of 2 matching loans") {
+		t.Errorf("Expected total hits in output, got: %s", text)
+	}
+	if !strings.Contains(text, "Next cursor: 1") {
+		t.Errorf("Expected next cursor in output, got: %s", text)
+	}
+
+	// Fetch the second page with the returned cursor
+	arguments["cursor"] = "1"
+	response = manager.ExecuteTool("search_loans", arguments)
+	text = response.Result.(map[string]any)["content"].([]map[string]any)[0]["text"].(string)
+	if !strings.Contains(text, "LN00000456") || strings.Contains(text, "Next cursor") {
+		t.Errorf("Expected last page without cursor, got: %s", text)
+	}
+}
+
+func TestManager_ExecuteTool_SearchLoans_InvalidArguments(t *testing.T) {
+	mockClient := createMockClient()
+	manager := NewManager(mockClient)
+
+	tests := []map[string]any{
+		{"created_after": "01/01/2025"},
+		{"created_before": "yesterday"},
+		{"sort_by": "ssn"},
+		{"sort_order": "sideways"},
+		{"cursor": "not-a-cursor"},
+	}
+
+	for _, arguments := range tests {
+		response := manager.ExecuteTool("search_loans", arguments)
+		if response.Error == nil || response.Error.Code != -32602 {
+			t.Errorf("Expected invalid params error for %v, got %+v", arguments, response.Error)
+		}
+	}
+}
+
+func TestManager_ExecuteTool_ListPortfolios(t *testing.T) {
+	mockClient := createMockClient()
+	manager := NewManager(mockClient)
+
+	response := manager.ExecuteTool("list_portfolios", map[string]any{})
+
+	if response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+
+	text := response.Result.(map[string]any)["content"].([]map[string]any)[0]["text"].(string)
+	if !strings.Contains(text, "- ID: 1, Title: Partner Bank") {
+		t.Errorf("Expected active portfolio, got: %s", text)
+	}
+	if !strings.Contains(text, "  - Sub-portfolio ID: 10, Title: 2025-A Securitization") {
+		t.Errorf("Expected active sub-portfolio, got: %s", text)
+	}
+	if strings.Contains(text, "Legacy Program") || strings.Contains(text, "2019-A") {
+		t.Errorf("Expected inactive entries to be hidden by default, got: %s", text)
+	}
+
+	response = manager.ExecuteTool("list_portfolios", map[string]any{"include_inactive": true})
+	text = response.Result.(map[string]any)["content"].([]map[string]any)[0]["text"].(string)
+	if !strings.Contains(text, "Legacy Program") || !strings.Contains(text, "2019-A") {
+		t.Errorf("Expected inactive entries with include_inactive, got: %s", text)
+	}
+}
+
 func TestManager_ExecuteTool_GetCustomer(t *testing.T) {
 	mockClient := createMockClient()
 	manager := NewManager(mockClient)
@@ -360,6 +675,208 @@ func TestManager_ExecuteTool_InvalidTool(t *testing.T) {
 	}
 }
 
+func TestManager_ListTools_Policy(t *testing.T) {
+	manager := NewManager(createMockClient())
+
+	if tools := manager.ListTools(context.Background()); len(tools) != 7 {
+		t.Errorf("Expected all 7 tools without a policy, got %d", len(tools))
+	}
+
+	ctx := WithPolicy(context.Background(), &Policy{Role: "collections", Tools: []string{"get_loan", "get_loan_payments"}})
+	var names []string
+	for _, tool := range manager.ListTools(ctx) {
+		names = append(names, tool.Name)
+	}
+	if strings.Join(names, ",") != "get_loan,get_loan_payments" {
+		t.Errorf("Expected only the role's tools, got %v", names)
+	}
+
+	// Customers don't belong to portfolios, so roles limited to portfolios can't read them
+	ctx = WithPolicy(context.Background(), &Policy{Role: "collections", Portfolios: []string{"1"}})
+	for _, tool := range manager.ListTools(ctx) {
+		if tool.Name == "get_customer" || tool.Name == "search_customers" {
+			t.Errorf("Expected no customer tools for a role limited to portfolios, got %s", tool.Name)
+		}
+	}
+}
+
+// flakyLoanClient fails the first loan lookup, as a rate limited or timed out call would
+type flakyLoanClient struct {
+	*MockLoanProClient
+	lookups int
+}
+
+func (c *flakyLoanClient) GetLoan(id string) (Loan, error) {
+	c.lookups++
+	if c.lookups == 1 {
+		return nil, errors.New("API returned status 503")
+	}
+	return c.MockLoanProClient.GetLoan(id)
+}
+
+func TestManager_ExecuteToolContext_PortfolioLookupFails(t *testing.T) {
+	ctx := WithPolicy(context.Background(), &Policy{Role: "collections", Portfolios: []string{"1"}})
+
+	for _, tool := range []string{"get_loan", "get_loan_payments", "get_loan_transactions"} {
+		t.Run(tool, func(t *testing.T) {
+			manager := NewManager(&flakyLoanClient{MockLoanProClient: createMockClient()})
+
+			// A failed portfolio check fails the call instead of skipping the check
+			response := manager.ExecuteToolContext(ctx, tool, map[string]any{"loan_id": "456"})
+			if response.Error == nil || response.Result != nil {
+				t.Fatalf("Expected the failed lookup to fail the call, got %+v", response)
+			}
+
+			response = manager.ExecuteToolContext(ctx, tool, map[string]any{"loan_id": "456"})
+			if response.Error == nil || response.Error.Code != ErrorCodePermissionDenied {
+				t.Errorf("Expected permission denied once the lookup succeeds, got %+v", response)
+			}
+		})
+	}
+}
+
+func TestManager_ExecuteToolContext_Policy(t *testing.T) {
+	support := &Policy{
+		Role:         "support",
+		Tools:        []string{"get_loan", "get_customer", "search_customers", "search_loans", "get_loan_payments", "list_portfolios"},
+		HiddenFields: []string{FieldEmail, FieldPhone, "FICO at Origination"},
+	}
+	collections := &Policy{
+		Role:         "collections",
+		Portfolios:   []string{"1"},
+		HiddenFields: []string{"FICO at Origination"},
+	}
+
+	tests := []struct {
+		name      string
+		policy    *Policy
+		tool      string
+		arguments map[string]any
+		denied    bool
+		contains  []string
+		excludes  []string
+	}{
+		{"no policy", nil, "get_customer", map[string]any{"customer_id": "789"}, false, []string{"john.doe@example.com"}, nil},
+		{"tool not allowed", support, "get_loan_transactions", map[string]any{"loan_id": "123"}, true, nil, nil},
+		{"hidden contact fields", support, "get_customer", map[string]any{"customer_id": "789"}, false,
+			[]string{"Name: John Doe", "Email: [hidden]", "Phone: [hidden]"}, []string{"john.doe@example.com", "555"}},
+		{"hidden custom field", collections, "get_loan", map[string]any{"loan_id": "123"}, false,
+			[]string{"Channel: Partner"}, []string{"FICO"}},
+		{"loan in other portfolio", collections, "get_loan", map[string]any{"loan_id": "456"}, true, nil, nil},
+		{"payments of loan in other portfolio", collections, "get_loan_payments", map[string]any{"loan_id": "456"}, true, nil, nil},
+		{"search limited to the only portfolio", collections, "search_loans", map[string]any{}, false, []string{"LN00000123"}, []string{"LN00000456"}},
+		{"search in other portfolio", collections, "search_loans", map[string]any{"portfolio_id": "2"}, true, nil, nil},
+		{"search by hidden field", support, "search_customers", map[string]any{"email": "john.doe@example.com"}, true, nil, nil},
+		{"portfolios outside the policy", collections, "list_portfolios", map[string]any{"include_inactive": true}, false,
+			[]string{"Partner Bank"}, []string{"Legacy Program"}},
+		{"transactions of loan in other portfolio", collections, "get_loan_transactions", map[string]any{"loan_id": "456"}, true, nil, nil},
+		{"customer with portfolios", collections, "get_customer", map[string]any{"customer_id": "789"}, true, nil, nil},
+		{"customer search with portfolios", collections, "search_customers", map[string]any{"search_term": "John"}, true, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewManager(createMockClient())
+			manager.SetCustomFields([]string{"FICO at Origination", "Channel"})
+			ctx := context.Background()
+			if tt.policy != nil {
+				ctx = WithPolicy(ctx, tt.policy)
+			}
+
+			response := manager.ExecuteToolContext(ctx, tt.tool, tt.arguments)
+			if tt.denied {
+				if response.Error == nil || response.Error.Code != ErrorCodePermissionDenied {
+					t.Fatalf("Expected permission denied, got %+v", response)
+				}
+				if !strings.Contains(response.Error.Message, "role "+tt.policy.Role) {
+					t.Errorf("Expected error to name the role, got %s", response.Error.Message)
+				}
+				return
+			}
+			if response.Error != nil {
+				t.Fatalf("Expected no error, got %v", response.Error.Message)
+			}
+
+			text := response.Result.(map[string]any)["content"].([]map[string]any)[0]["text"].(string)
+			for _, expected := range tt.contains {
+				if !strings.Contains(text, expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, text)
+				}
+			}
+			for _, excluded := range tt.excludes {
+				if strings.Contains(text, excluded) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", excluded, text)
+				}
+			}
+		})
+	}
+
+	// The policy applies to the call only, not to the shared manager or arguments
+	manager := NewManager(createMockClient())
+	arguments := map[string]any{}
+	manager.ExecuteToolContext(WithPolicy(context.Background(), collections), "search_loans", arguments)
+	if manager.policy != nil || len(arguments) != 0 {
+		t.Errorf("Expected the call not to modify the manager or arguments, got %v", arguments)
+	}
+}
+
+func TestManager_ExecuteTool_SearchCustomers_ByIdentifier(t *testing.T) {
+	mockClient := createMockClient()
+	manager := NewManager(mockClient)
+
+	arguments := map[string]any{
+		"email":      "john.doe@example.com",
+		"phone":      "(555) 123-4567",
+		"ssn_last4":  "6789",
+		"birth_date": "1980-01-31",
+		"offset":     float64(0),
+		"limit":      float64(5),
+	}
+
+	response := manager.ExecuteTool("search_customers", arguments)
+	if response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+
+	opts := mockClient.lastCustomerSearch
+	if opts.Email != "john.doe@example.com" || opts.Phone != "(555) 123-4567" || opts.SSNLast4 != "6789" || opts.BirthDate != "1980-01-31" {
+		t.Errorf("Expected identifiers to be passed as separate filters, got %+v", opts)
+	}
+	if opts.Name != "" {
+		t.Errorf("Expected no free-text name filter, got %q", opts.Name)
+	}
+	if opts.Limit != 5 {
+		t.Errorf("Expected limit 5, got %d", opts.Limit)
+	}
+
+	text := response.Result.(map[string]any)["content"].([]map[string]any)[0]["text"].(string)
+	if !strings.Contains(text, "Showing 1 of 1 matching customers (offset 0)") {
+		t.Errorf("Expected total hits in output, got: %s", text)
+	}
+}
+
+func TestManager_ExecuteTool_SearchCustomers_InvalidArguments(t *testing.T) {
+	mockClient := createMockClient()
+	manager := NewManager(mockClient)
+
+	// The client validates the criteria; its validation errors are invalid params
+	mockClient.customerSearchErr = fmt.Errorf("%w: SSN last four must be exactly 4 digits", ErrInvalidSearch)
+	response := manager.ExecuteTool("search_customers", map[string]any{"ssn_last4": "12"})
+	if response.Error == nil || response.Error.Code != -32602 {
+		t.Fatalf("Expected invalid params error, got %+v", response.Error)
+	}
+	if !strings.Contains(response.Error.Message, "SSN last four") {
+		t.Errorf("Expected the validation message, got %s", response.Error.Message)
+	}
+
+	// Other failures are not
+	mockClient.customerSearchErr = errors.New("LoanPro unavailable")
+	response = manager.ExecuteTool("search_customers", map[string]any{"ssn_last4": "1234"})
+	if response.Error == nil || response.Error.Code != -1 {
+		t.Errorf("Expected error code -1, got %+v", response.Error)
+	}
+}
+
 func TestCreateSuccessResponse(t *testing.T) {
 	text := "Test response"
 	id := 123
@@ -749,3 +1266,121 @@ func TestManager_ExecuteTool_GetLoanTransactions_WithPagination(t *testing.T) {
 		}
 	})
 }
+
+func TestManager_ExecuteToolContext_Redaction(t *testing.T) {
+	contact, _ := redact.Select(redact.Builtin(), []string{redact.Email, redact.Phone})
+
+	tests := []struct {
+		name     string
+		manager  *redact.Redactor
+		policy   *Policy
+		contains []string
+		excludes []string
+	}{
+		{"no redactor", nil, nil, []string{"john.doe@example.com"}, nil},
+		{"manager redactor", redact.New(contact...), nil, []string{"Email: [redacted:email]"}, []string{"john.doe@example.com"}},
+		{"policy redactor", nil, &Policy{Role: "support", Redactor: redact.New(contact...)}, []string{"Email: [redacted:email]"}, []string{"john.doe@example.com"}},
+		{"policy overrides manager", redact.New(contact...), &Policy{Role: "auditor", Redactor: redact.New()}, []string{"john.doe@example.com"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewManager(createMockClient())
+			manager.SetRedactor(tt.manager)
+			ctx := context.Background()
+			if tt.policy != nil {
+				ctx = WithPolicy(ctx, tt.policy)
+			}
+
+			response := manager.ExecuteToolContext(ctx, "get_customer", map[string]any{"customer_id": "789"})
+			if response.Error != nil {
+				t.Fatalf("Expected no error, got %v", response.Error.Message)
+			}
+			text := response.Result.(map[string]any)["content"].([]map[string]any)[0]["text"].(string)
+			for _, expected := range tt.contains {
+				if !strings.Contains(text, expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, text)
+				}
+			}
+			for _, excluded := range tt.excludes {
+				if strings.Contains(text, excluded) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", excluded, text)
+				}
+			}
+		})
+	}
+}
+
+// recordingAuditor collects audited tool calls, failing when err is set
+type recordingAuditor struct {
+	calls []Invocation
+	err   error
+}
+
+func (a *recordingAuditor) AuditToolCall(ctx context.Context, call Invocation) error {
+	a.calls = append(a.calls, call)
+	return a.err
+}
+
+func TestManager_ExecuteToolContext_Audit(t *testing.T) {
+	support := &Policy{Role: "support", Portfolios: []string{"1"}}
+
+	tests := []struct {
+		name      string
+		policy    *Policy
+		tool      string
+		arguments map[string]any
+		loans     []string
+		customers []string
+		errorCode int
+	}{
+		{"loan", nil, "get_loan", map[string]any{"loan_id": "123"}, []string{"123"}, nil, 0},
+		{"customer", nil, "get_customer", map[string]any{"customer_id": "789"}, nil, []string{"789"}, 0},
+		{"loan search", nil, "search_loans", map[string]any{"portfolio_id": "1"}, []string{"123"}, nil, 0},
+		{"customer search", nil, "search_customers", map[string]any{"search_term": "John"}, nil, []string{"789"}, 0},
+		{"payments", nil, "get_loan_payments", map[string]any{"loan_id": "123"}, []string{"123"}, nil, 0},
+		{"failed call", nil, "get_loans", map[string]any{"loan_id": "123"}, nil, nil, -32601},
+		{"denied call", support, "get_loan", map[string]any{"loan_id": "456"}, nil, nil, ErrorCodePermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditor := &recordingAuditor{}
+			manager := NewManager(createMockClient())
+			manager.SetAuditor(auditor)
+			ctx := context.Background()
+			if tt.policy != nil {
+				ctx = WithPolicy(ctx, tt.policy)
+			}
+
+			manager.ExecuteToolContext(ctx, tt.tool, tt.arguments)
+			if len(auditor.calls) != 1 {
+				t.Fatalf("Expected 1 audited call, got %d", len(auditor.calls))
+			}
+			call := auditor.calls[0]
+			if call.Tool != tt.tool || call.Duration <= 0 {
+				t.Errorf("Expected %s with a duration, got %+v", tt.tool, call)
+			}
+			if strings.Join(call.LoanIDs, ",") != strings.Join(tt.loans, ",") {
+				t.Errorf("Expected loans %v, got %v", tt.loans, call.LoanIDs)
+			}
+			if strings.Join(call.CustomerIDs, ",") != strings.Join(tt.customers, ",") {
+				t.Errorf("Expected customers %v, got %v", tt.customers, call.CustomerIDs)
+			}
+			if tt.errorCode == 0 && call.Error != nil {
+				t.Errorf("Expected no error, got %+v", call.Error)
+			}
+			if tt.errorCode != 0 && (call.Error == nil || call.Error.Code != tt.errorCode) {
+				t.Errorf("Expected error code %d, got %+v", tt.errorCode, call.Error)
+			}
+		})
+	}
+
+	// Results that can't be audited are withheld
+	manager := NewManager(createMockClient())
+	manager.SetAuditor(&recordingAuditor{err: errors.New("disk full")})
+	response := manager.ExecuteTool("get_customer", map[string]any{"customer_id": "789"})
+	if response.Error == nil || response.Error.Code != ErrorCodeAuditFailed || response.Result != nil {
+		t.Errorf("Expected an audit failure error, got %+v", response)
+	}
+}
@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"loanpro-mcp-server/redact"
+)
+
+// ErrorCodePermissionDenied is the JSON-RPC error code for tool calls the caller's
+// policy doesn't allow
+const ErrorCodePermissionDenied = -32003
+
+// Output fields a policy can hide. Custom fields are hidden by their name.
+const (
+	FieldCustomerName = "customer_name"
+	FieldEmail        = "email"
+	FieldPhone        = "phone"
+)
+
+// hiddenValue replaces the value of a hidden field in tool output
+const hiddenValue = "[hidden]"
+
+// Policy limits what a caller may do with the tools. A nil list places no limit,
+// and a nil *Policy allows everything.
+type Policy struct {
+	Role         string   // Role name, used in permission errors
+	Tools        []string // Tools the caller may run
+	Portfolios   []string // Portfolio IDs whose loans the caller may see
+	HiddenFields []string // Output fields withheld from the caller, e.g. "email"
+	// Redactor masks personal data in the caller's output, nil for the manager's default
+	Redactor *redact.Redactor
+}
+
+// customerTools read customers, which don't belong to portfolios. Roles limited to
+// portfolios can't run them, since they would reach every customer of the tenant.
+var customerTools = []string{"get_customer", "search_customers"}
+
+// AllowsTool reports whether the policy allows running a tool
+func (p *Policy) AllowsTool(name string) bool {
+	if p == nil {
+		return true
+	}
+	if p.Portfolios != nil && slices.Contains(customerTools, name) {
+		return false
+	}
+	return p.Tools == nil || slices.Contains(p.Tools, name)
+}
+
+// AllowsPortfolio reports whether the policy allows seeing loans in a portfolio
+func (p *Policy) AllowsPortfolio(id string) bool {
+	return p == nil || p.Portfolios == nil || slices.Contains(p.Portfolios, id)
+}
+
+// Hides reports whether the policy withholds an output field
+func (p *Policy) Hides(field string) bool {
+	return p != nil && slices.Contains(p.HiddenFields, field)
+}
+
+// denied returns a permission denied response naming the caller's role
+func (p *Policy) denied(format string, args ...any) MCPResponse {
+	return CreateErrorResponse(ErrorCodePermissionDenied,
+		fmt.Sprintf("Permission denied for role %s: %s", p.Role, fmt.Sprintf(format, args...)), nil)
+}
+
+// policyContextKey is the context key for the caller's policy
+type policyContextKey struct{}
+
+// WithPolicy attaches the caller's policy to ctx
+func WithPolicy(ctx context.Context, policy *Policy) context.Context {
+	return context.WithValue(ctx, policyContextKey{}, policy)
+}
+
+// PolicyFromContext returns the caller's policy, or nil when the caller isn't restricted
+func PolicyFromContext(ctx context.Context) *Policy {
+	policy, _ := ctx.Value(policyContextKey{}).(*Policy)
+	return policy
+}
+
+// authorize checks a tool call against the manager's policy before it runs.
+// Searches must stay within the allowed portfolios and can't filter on hidden
+// fields. Loan tools check the loan's portfolios themselves, see authorizeLoan.
+func (m *Manager) authorize(toolName string, arguments map[string]any) *MCPResponse {
+	p := m.policy
+	if p == nil {
+		return nil
+	}
+	if p.Portfolios != nil && slices.Contains(customerTools, toolName) {
+		response := p.denied("customer tools are not available to roles limited to portfolios")
+		return &response
+	}
+
+	if !p.AllowsTool(toolName) {
+		response := p.denied("tool %s is not allowed", toolName)
+		return &response
+	}
+
+	switch toolName {
+	case "search_loans":
+		if p.Portfolios == nil {
+			return nil
+		}
+		portfolioID, _ := arguments["portfolio_id"].(string)
+		switch {
+		case portfolioID != "" && !p.AllowsPortfolio(portfolioID):
+			response := p.denied("portfolio %s is not allowed", portfolioID)
+			return &response
+		case portfolioID == "" && len(p.Portfolios) == 1:
+			arguments["portfolio_id"] = p.Portfolios[0]
+		case portfolioID == "":
+			response := p.denied("portfolio_id is required (one of %s)", strings.Join(p.Portfolios, ", "))
+			return &response
+		}
+
+	case "search_customers":
+		for _, field := range []string{FieldEmail, FieldPhone} {
+			if value, _ := arguments[field].(string); value != "" && p.Hides(field) {
+				response := p.denied("searching by %s is not allowed", field)
+				return &response
+			}
+		}
+	}
+	return nil
+}
+
+// authorizeLoan looks up a loan and checks it against the allowed portfolios before
+// a tool reads its records. A failed lookup fails the call, so the tool never runs
+// on a loan whose portfolios are unknown.
+func (m *Manager) authorizeLoan(toolName, loanID string, arguments map[string]any) *MCPResponse {
+	if m.policy == nil || m.policy.Portfolios == nil {
+		return nil
+	}
+	loan, err := m.clientFor(arguments).GetLoan(loanID)
+	if err != nil {
+		LogError(m.context(), toolName, err, fmt.Sprintf("checking portfolios of loan ID %s", loanID))
+		response := CreateErrorResponse(-1, err.Error(), nil)
+		return &response
+	}
+	return m.checkLoanPortfolios(loanID, loan)
+}
+
+// checkLoanPortfolios denies access to a loan outside the allowed portfolios
+func (m *Manager) checkLoanPortfolios(loanID string, loan Loan) *MCPResponse {
+	p := m.policy
+	if p == nil || p.Portfolios == nil {
+		return nil
+	}
+	if loan == nil || !slices.ContainsFunc(loan.GetPortfolioIDs(), p.AllowsPortfolio) {
+		response := p.denied("loan %s is outside the allowed portfolios", loanID)
+		return &response
+	}
+	return nil
+}
+
+// show returns a field's value, or a placeholder when the caller's policy hides it
+func (m *Manager) show(field, value string) string {
+	if m.policy.Hides(field) {
+		return hiddenValue
+	}
+	return value
+}
+
+// visibleCustomFields returns the configured custom fields the caller may see
+func (m *Manager) visibleCustomFields() []string {
+	if m.policy == nil {
+		return m.customFields
+	}
+	return slices.DeleteFunc(slices.Clone(m.customFields), m.policy.Hides)
+}
@@ -1,24 +1,50 @@
 package tools
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // SearchCustomersTool returns the search_customers tool definition
 func SearchCustomersTool() Tool {
 	return Tool{
 		Name:        "search_customers",
-		Description: "Search customers with a search term",
+		Description: "Search customers by name or by a specific identifier (exact email, phone, SSN last four, or date of birth). Identifiers are matched exactly rather than as free text. At least one criterion is required. Supports offset pagination and returns the total number of matches.",
 		InputSchema: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
 				"search_term": map[string]any{
 					"type":        "string",
-					"description": "Search term to match against customer names, email, or SSN",
+					"description": "Name to match against customer first, last, or company name",
+				},
+				"email": map[string]any{
+					"type":        "string",
+					"description": "Exact email address",
+				},
+				"phone": map[string]any{
+					"type":        "string",
+					"description": "Phone number in any format, e.g. (555) 123-4567",
+				},
+				"ssn_last4": map[string]any{
+					"type":        "string",
+					"description": "Last four digits of the customer's SSN",
+				},
+				"birth_date": map[string]any{
+					"type":        "string",
+					"description": "Date of birth (YYYY-MM-DD)",
 				},
 				"limit": map[string]any{
 					"type":        "number",
 					"description": "Maximum number of results",
 					"default":     10,
 				},
+				"offset": map[string]any{
+					"type":        "number",
+					"description": "Number of results to skip (pagination)",
+				},
+				"fresh": freshProperty(),
 			},
 		},
 	}
@@ -26,25 +52,46 @@ func SearchCustomersTool() Tool {
 
 // executeSearchCustomers handles the search_customers tool execution
 func (m *Manager) executeSearchCustomers(arguments map[string]any) MCPResponse {
-	searchTerm := ""
+	opts := &CustomerSearchOptions{Limit: 10}
 	if term, ok := arguments["search_term"].(string); ok {
-		searchTerm = term
+		opts.Name = term
+	}
+	if email, ok := arguments["email"].(string); ok {
+		opts.Email = email
+	}
+	if phone, ok := arguments["phone"].(string); ok {
+		opts.Phone = phone
+	}
+	if ssn, ok := arguments["ssn_last4"].(string); ok {
+		opts.SSNLast4 = strings.TrimSpace(ssn)
+	}
+	if dob, ok := arguments["birth_date"].(string); ok {
+		opts.BirthDate = dob
 	}
-	limit := 10
 	if l, ok := arguments["limit"].(float64); ok {
-		limit = int(l)
+		opts.Limit = int(l)
+	}
+	if o, ok := arguments["offset"].(float64); ok {
+		opts.Offset = int(o)
 	}
 
-	customers, err := m.client.SearchCustomers(searchTerm, limit)
+	result, err := m.clientFor(arguments).SearchCustomersWithMetadata(opts)
+	if errors.Is(err, ErrInvalidSearch) {
+		return CreateErrorResponse(-32602, err.Error(), nil)
+	}
 	if err != nil {
-		LogError("search_customers", err, fmt.Sprintf("with term='%s', limit=%d", searchTerm, limit))
+		// Log which filters were used, never their values
+		LogError(m.context(), "search_customers", err, fmt.Sprintf("with offset=%d, limit=%d", opts.Offset, opts.Limit))
 		return CreateErrorResponse(-1, err.Error(), nil)
 	}
 
 	text := "Customers:\n"
-	for _, customer := range customers {
-		text += fmt.Sprintf("- ID: %d, Name: %s %s, Email: %s\n", customer.GetID(), customer.GetFirstName(), customer.GetLastName(), customer.GetEmail())
+	for _, customer := range result.Customers {
+		m.touchCustomer(strconv.Itoa(customer.GetID()))
+		text += fmt.Sprintf("- ID: %d, Name: %s, Email: %s\n", customer.GetID(),
+			m.show(FieldCustomerName, customer.GetFirstName()+" "+customer.GetLastName()), m.show(FieldEmail, customer.GetEmail()))
 	}
+	text += fmt.Sprintf("\nShowing %d of %d matching customers (offset %d)\n", len(result.Customers), result.TotalHits, opts.Offset)
 
 	return CreateSuccessResponse(text, nil)
 }
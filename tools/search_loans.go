@@ -1,12 +1,25 @@
 package tools
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// loanSortFields maps search_loans sort_by values to loan search index fields
+var loanSortFields = map[string]string{
+	"display_id":        "displayId",
+	"balance":           "principalBalance",
+	"days_past_due":     "daysPastDue",
+	"created":           "created",
+	"next_payment_date": "nextPaymentDate",
+}
 
 // SearchLoansTool returns the search_loans tool definition
 func SearchLoansTool() Tool {
 	return Tool{
 		Name:        "search_loans",
-		Description: "Search loans with filters and search terms",
+		Description: "Search loans with filters and search terms. Supports balance, days-past-due and creation date ranges, sorting, and cursor pagination. Returns the total number of matching loans and a next_cursor when more results are available.",
 		InputSchema: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
@@ -18,11 +31,63 @@ func SearchLoansTool() Tool {
 					"type":        "string",
 					"description": "Loan status filter",
 				},
+				"sub_status": map[string]any{
+					"type":        "string",
+					"description": "Loan sub-status filter (e.g. Collections)",
+				},
+				"portfolio_id": map[string]any{
+					"type":        "string",
+					"description": "Only return loans in this portfolio (see list_portfolios)",
+				},
+				"sub_portfolio_id": map[string]any{
+					"type":        "string",
+					"description": "Only return loans in this sub-portfolio (see list_portfolios)",
+				},
+				"min_balance": map[string]any{
+					"type":        "number",
+					"description": "Minimum principal balance (inclusive)",
+				},
+				"max_balance": map[string]any{
+					"type":        "number",
+					"description": "Maximum principal balance (inclusive)",
+				},
+				"min_days_past_due": map[string]any{
+					"type":        "number",
+					"description": "Minimum days past due (inclusive)",
+				},
+				"max_days_past_due": map[string]any{
+					"type":        "number",
+					"description": "Maximum days past due (inclusive)",
+				},
+				"created_after": map[string]any{
+					"type":        "string",
+					"description": "Only loans created on or after this date (YYYY-MM-DD)",
+				},
+				"created_before": map[string]any{
+					"type":        "string",
+					"description": "Only loans created on or before this date (YYYY-MM-DD)",
+				},
+				"sort_by": map[string]any{
+					"type":        "string",
+					"description": "Field to sort results by",
+					"enum":        []string{"display_id", "balance", "days_past_due", "created", "next_payment_date"},
+				},
+				"sort_order": map[string]any{
+					"type":        "string",
+					"description": "Sort order",
+					"enum":        []string{"asc", "desc"},
+					"default":     "asc",
+				},
 				"limit": map[string]any{
 					"type":        "number",
 					"description": "Maximum number of results",
 					"default":     10,
 				},
+				"cursor": map[string]any{
+					"type":        "string",
+					"description": "Cursor returned by a previous search_loans call to fetch the next page. Other arguments must be unchanged.",
+				},
+				"fresh": freshProperty(),
 			},
 		},
 	}
@@ -30,29 +95,89 @@ func SearchLoansTool() Tool {
 
 // executeSearchLoans handles the search_loans tool execution
 func (m *Manager) executeSearchLoans(arguments map[string]any) MCPResponse {
-	searchTerm := ""
+	opts := &LoanSearchOptions{Limit: 10}
 	if term, ok := arguments["search_term"].(string); ok {
-		searchTerm = term
+		opts.SearchTerm = term
 	}
-	status := ""
 	if s, ok := arguments["status"].(string); ok {
-		status = s
+		opts.Status = s
+	}
+	if s, ok := arguments["sub_status"].(string); ok {
+		opts.SubStatus = s
+	}
+	if p, ok := arguments["portfolio_id"].(string); ok {
+		opts.PortfolioID = p
+	}
+	if p, ok := arguments["sub_portfolio_id"].(string); ok {
+		opts.SubPortfolioID = p
+	}
+	if b, ok := arguments["min_balance"].(float64); ok {
+		opts.MinBalance = &b
+	}
+	if b, ok := arguments["max_balance"].(float64); ok {
+		opts.MaxBalance = &b
+	}
+	if d, ok := arguments["min_days_past_due"].(float64); ok {
+		days := int(d)
+		opts.MinDaysPastDue = &days
+	}
+	if d, ok := arguments["max_days_past_due"].(float64); ok {
+		days := int(d)
+		opts.MaxDaysPastDue = &days
 	}
-	limit := 10
 	if l, ok := arguments["limit"].(float64); ok {
-		limit = int(l)
+		opts.Limit = int(l)
+	}
+	if c, ok := arguments["cursor"].(string); ok {
+		opts.Cursor = c
+	}
+
+	for name, target := range map[string]*string{"created_after": &opts.CreatedAfter, "created_before": &opts.CreatedBefore} {
+		value, ok := arguments[name].(string)
+		if !ok || value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return CreateErrorResponse(-32602, fmt.Sprintf("Invalid %s: expected YYYY-MM-DD, got %q", name, value), nil)
+		}
+		*target = value
 	}
 
-	loans, err := m.client.SearchLoans(searchTerm, status, limit)
+	if sortBy, ok := arguments["sort_by"].(string); ok && sortBy != "" {
+		field, known := loanSortFields[sortBy]
+		if !known {
+			return CreateErrorResponse(-32602, fmt.Sprintf("Invalid sort_by: %q", sortBy), nil)
+		}
+		opts.SortBy = field
+	}
+	if order, ok := arguments["sort_order"].(string); ok {
+		switch order {
+		case "", "asc":
+		case "desc":
+			opts.SortDescending = true
+		default:
+			return CreateErrorResponse(-32602, fmt.Sprintf("Invalid sort_order: %q", order), nil)
+		}
+	}
+
+	result, err := m.clientFor(arguments).SearchLoansWithMetadata(opts)
 	if err != nil {
-		LogError("search_loans", err, fmt.Sprintf("with term='%s', status='%s', limit=%d", searchTerm, status, limit))
+		if errors.Is(err, ErrInvalidCursor) {
+			return CreateErrorResponse(-32602, err.Error(), nil)
+		}
+		LogError(m.context(), "search_loans", err, fmt.Sprintf("with term='%s', status='%s', portfolio='%s', sub_portfolio='%s', limit=%d", opts.SearchTerm, opts.Status, opts.PortfolioID, opts.SubPortfolioID, opts.Limit))
 		return CreateErrorResponse(-1, err.Error(), nil)
 	}
 
 	text := "Loans:\n"
-	for _, loan := range loans {
+	for _, loan := range result.Loans {
+		m.touchLoan(loan.GetID())
 		text += fmt.Sprintf("- ID: %s, Display ID: %s, Customer: %s, Status: %s, Balance: $%s\n",
-			loan.GetID(), loan.GetDisplayID(), loan.GetPrimaryCustomerName(), loan.GetLoanStatus(), loan.GetPrincipalBalance())
+			loan.GetID(), loan.GetDisplayID(), m.show(FieldCustomerName, loan.GetPrimaryCustomerName()), loan.GetLoanStatus(), loan.GetPrincipalBalance())
+	}
+	text += fmt.Sprintf("\nShowing %d of %d matching loans\n", len(result.Loans), result.TotalHits)
+	if result.NextCursor != "" {
+		text += fmt.Sprintf("Next cursor: %s\n", result.NextCursor)
 	}
 
 	return CreateSuccessResponse(text, nil)
@@ -1,9 +1,11 @@
 package tools
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"os"
+	"strconv"
 )
 
 // Tool represents an MCP tool definition
@@ -33,15 +35,81 @@ type TransactionOptions struct {
 	Offset int // Number of records to skip
 }
 
+// LoanSearchOptions contains search term, filter, sort and pagination options for loan searches
+type LoanSearchOptions struct {
+	SearchTerm     string   // Free-text search term
+	Status         string   // Loan status filter
+	SubStatus      string   // Loan sub-status filter
+	PortfolioID    string   // Portfolio ID filter
+	SubPortfolioID string   // Sub-portfolio ID filter
+	MinBalance     *float64 // Minimum principal balance
+	MaxBalance     *float64 // Maximum principal balance
+	MinDaysPastDue *int     // Minimum days past due
+	MaxDaysPastDue *int     // Maximum days past due
+	CreatedAfter   string   // Created on or after (YYYY-MM-DD)
+	CreatedBefore  string   // Created on or before (YYYY-MM-DD)
+	SortBy         string   // Field to sort by
+	SortDescending bool     // Sort in descending order
+	Limit          int      // Maximum number of results per page
+	Cursor         string   // Cursor from a previous page
+}
+
+// ErrInvalidCursor is returned by SearchLoansWithMetadata when the cursor is
+// malformed or was issued for a different query
+var ErrInvalidCursor = errors.New("invalid search cursor")
+
+// LoanSearchResult contains loans and pagination metadata from a search
+type LoanSearchResult struct {
+	Loans      []Loan
+	TotalHits  int    // Total number of matching loans
+	NextCursor string // Cursor for the next page, empty when there are no more results
+}
+
+// ErrInvalidSearch is returned by SearchCustomersWithMetadata when the search
+// criteria are malformed, e.g. an SSN last four that isn't 4 digits
+var ErrInvalidSearch = errors.New("invalid search criteria")
+
+// CustomerSearchOptions contains field-specific filters and pagination for customer searches
+type CustomerSearchOptions struct {
+	Name      string // First, last or company name
+	Email     string // Exact email address
+	Phone     string // Phone number in any format
+	SSNLast4  string // Last four digits of the SSN
+	BirthDate string // Date of birth (YYYY-MM-DD)
+	Offset    int    // Number of results to skip
+	Limit     int    // Maximum number of results
+}
+
+// CustomerSearchResult contains customers and pagination metadata from a search
+type CustomerSearchResult struct {
+	Customers []Customer
+	TotalHits int // Total number of matching customers
+}
+
 // LoanProClient interface for dependency injection
 type LoanProClient interface {
 	GetLoan(id string) (Loan, error)
 	SearchLoans(searchTerm, status string, limit int) ([]Loan, error)
+	SearchLoansWithMetadata(opts *LoanSearchOptions) (*LoanSearchResult, error)
 	GetCustomer(id string) (Customer, error)
 	SearchCustomers(searchTerm string, limit int) ([]Customer, error)
+	SearchCustomersWithMetadata(opts *CustomerSearchOptions) (*CustomerSearchResult, error)
 	GetLoanPayments(loanID string) ([]Payment, error)
 	GetLoanTransactions(loanID string) ([]Transaction, error)
 	GetLoanTransactionsWithOptions(loanID string, opts *TransactionOptions) ([]Transaction, error)
+	GetPortfolios() ([]Portfolio, error)
+}
+
+// FreshClient is implemented by clients that cache responses. Fresh returns a
+// client that reads current data from LoanPro instead of the cache.
+type FreshClient interface {
+	Fresh() LoanProClient
+}
+
+// ContextClient is implemented by clients that can make their requests with the
+// context of a tool call, so they are cancelled along with it and carry its trace
+type ContextClient interface {
+	WithContext(ctx context.Context) LoanProClient
 }
 
 // Loan represents loan data - simplified interface for tools
@@ -52,6 +120,8 @@ type Loan interface {
 	GetLoanStatus() string
 	GetPrincipalBalance() string
 	GetPayoffAmount() string
+	GetPortfolioIDs() []string
+	GetCustomFields() map[string]any
 }
 
 // Customer represents customer data - simplified interface for tools
@@ -62,6 +132,7 @@ type Customer interface {
 	GetEmail() string
 	GetPhone() string
 	GetCreatedDate() string
+	GetCustomFields() map[string]any
 }
 
 // Payment represents payment data - simplified interface for tools
@@ -88,6 +159,41 @@ type Transaction interface {
 	HasPaymentBreakdown() bool
 }
 
+// Portfolio represents portfolio data - simplified interface for tools
+type Portfolio interface {
+	GetID() string
+	GetTitle() string
+	IsActive() bool
+	GetSubPortfolios() []SubPortfolio
+}
+
+// SubPortfolio represents sub-portfolio data - simplified interface for tools
+type SubPortfolio interface {
+	GetID() string
+	GetTitle() string
+	IsActive() bool
+}
+
+// Helper function to build the schema for the fresh argument accepted by read tools
+func freshProperty() map[string]any {
+	return map[string]any{
+		"type":        "boolean",
+		"description": "Bypass the response cache and fetch current data from LoanPro",
+		"default":     false,
+	}
+}
+
+// requiredString returns a required string argument, or an invalid params response
+// when it's missing or isn't a string
+func requiredString(arguments map[string]any, name string) (string, *MCPResponse) {
+	value, ok := arguments[name].(string)
+	if !ok || value == "" {
+		response := CreateErrorResponse(-32602, fmt.Sprintf("Invalid params: %s is required and must be a string", name), nil)
+		return "", &response
+	}
+	return value, nil
+}
+
 // Helper function to create error responses
 func CreateErrorResponse(code int, message string, id any) MCPResponse {
 	return MCPResponse{
@@ -113,8 +219,27 @@ func CreateSuccessResponse(text string, id any) MCPResponse {
 	}
 }
 
-// Helper function to log errors to stderr
-func LogError(toolName string, err error, details string) {
-	slog.Error("Tool execution failed", "tool", toolName, "error", err, "details", details)
-	fmt.Fprintf(os.Stderr, "[ERROR] %s failed %s: %v\n", toolName, details, err)
+// Helper function to format selected custom fields as indented "Name: value" lines.
+// Fields are rendered in the configured order and unset fields are omitted.
+func FormatCustomFields(values map[string]any, names []string) string {
+	text := ""
+	for _, name := range names {
+		value, ok := values[name]
+		if !ok || value == nil {
+			continue
+		}
+		if f, ok := value.(float64); ok {
+			value = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		text += fmt.Sprintf("\n  %s: %v", name, value)
+	}
+	if text == "" {
+		return ""
+	}
+	return "\nCustom Fields:" + text
+}
+
+// Helper function to log tool errors with the request's context
+func LogError(ctx context.Context, toolName string, err error, details string) {
+	slog.ErrorContext(ctx, "Tool execution failed", "tool", toolName, "error", err, "details", details)
 }
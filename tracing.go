@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// setupTracing installs the OpenTelemetry tracer provider for the exporter named by
+// OTEL_TRACES_EXPORTER: "otlp", "console" or "none", the default. W3C trace context
+// is read from callers either way. The returned function flushes spans and stops the
+// exporter.
+func setupTracing(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch name := strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")); name {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		// Endpoint, headers and TLS come from the OTEL_EXPORTER_OTLP_* variables
+		exporter, err = otlptracehttp.New(ctx)
+	case "console", "stdout":
+		// Stdout carries the stdio transport's messages, so spans go to stderr
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	default:
+		return nil, fmt.Errorf("invalid OTEL_TRACES_EXPORTER %q: use otlp, console or none", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override these
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName("loanpro-mcp-server"), semconv.ServiceVersion("1.0.0")),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// loadTracePropagation reports whether LOANPRO_PROPAGATE_TRACE asks for the trace
+// context to be sent on to LoanPro. It's off by default since the headers leave
+// the network for a third party.
+func loadTracePropagation() bool {
+	value := os.Getenv("LOANPRO_PROPAGATE_TRACE")
+	if value == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		slog.Error("Invalid LOANPRO_PROPAGATE_TRACE, using default", "value", value, "default", false)
+		return false
+	}
+	return enabled
+}
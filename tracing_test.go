@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"loanpro-mcp-server/loanpro"
+	"loanpro-mcp-server/loanpro/fake"
+	"loanpro-mcp-server/transport"
+)
+
+func TestSetupTracing(t *testing.T) {
+	tests := []struct {
+		exporter  string
+		expectErr bool
+	}{
+		{"", false},
+		{"none", false},
+		{"jaeger", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.exporter, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_EXPORTER", test.exporter)
+			shutdown, err := setupTracing(context.Background())
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("Expected error for exporter %q", test.exporter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := shutdown(context.Background()); err != nil {
+				t.Errorf("Expected no shutdown error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadTracePropagation(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"", false},
+		{"false", false},
+		{"true", true},
+		{"1", true},
+		{"sometimes", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			t.Setenv("LOANPRO_PROPAGATE_TRACE", test.value)
+			if enabled := loadTracePropagation(); enabled != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, enabled)
+			}
+		})
+	}
+}
+
+func TestMCPServer_Tracing(t *testing.T) {
+	// Tracers created at init delegate to the first provider set, so the test
+	// installs a single in-memory provider for the whole run
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer provider.Shutdown(context.Background())
+
+	var mu sync.Mutex
+	var outbound []string
+	fakeHandler := fake.NewServer(fake.DefaultAPIKey, fake.DefaultTenantID)
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		outbound = append(outbound, r.Header.Get("traceparent"))
+		mu.Unlock()
+		fakeHandler.ServeHTTP(w, r)
+	}))
+	defer fakeServer.Close()
+
+	tests := []struct {
+		name      string
+		traceID   string
+		parent    string
+		header    bool
+		propagate bool
+	}{
+		{"http header", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true, true},
+		{"_meta", "0af7651916cd43dd8448eb211c80319c", "b7ad6b7169203331", false, true},
+		{"not propagated by default", "5b8efff798038103d269b633813fc60c", "eee19b7ec3c1b174", true, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := loanpro.NewClient(fakeServer.URL, fake.DefaultAPIKey, fake.DefaultTenantID)
+			if test.propagate {
+				client.EnableTracePropagation()
+			}
+			httpTransport := transport.NewHTTPTransport(NewMCPServer(client))
+
+			exporter.Reset()
+			mu.Lock()
+			outbound = nil
+			mu.Unlock()
+
+			traceparent := "00-" + test.traceID + "-" + test.parent + "-01"
+			params := map[string]any{
+				"name":      "get_loan_transactions",
+				"arguments": map[string]any{"loan_id": "102", "limit": 5},
+			}
+			if !test.header {
+				params["_meta"] = map[string]any{"traceparent": traceparent}
+			}
+			body, _ := json.Marshal(transport.MCPRequest{JSONRPC: "2.0", Method: "tools/call", Params: params, ID: 1})
+			req := httptest.NewRequest("POST", "/mcp", bytes.NewReader(body))
+			if test.header {
+				req.Header.Set("traceparent", traceparent)
+			}
+			w := httptest.NewRecorder()
+			httpTransport.HandleMCP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			spans := map[string]tracetest.SpanStub{}
+			for _, span := range exporter.GetSpans() {
+				spans[span.Name] = span
+			}
+
+			// Each span is the child of the one before it, starting from the caller's
+			parentID := test.parent
+			for _, name := range []string{
+				"http tools/call",
+				"tools/call",
+				"execute_tool get_loan_transactions",
+				"LoanPro GET Loans/Transactions",
+			} {
+				span, ok := spans[name]
+				if !ok {
+					t.Fatalf("Expected span %s, got %d spans", name, len(spans))
+				}
+				if span.SpanContext.TraceID().String() != test.traceID {
+					t.Errorf("Expected span %s in trace %s, got %s", name, test.traceID, span.SpanContext.TraceID())
+				}
+				if span.Parent.SpanID().String() != parentID {
+					t.Errorf("Expected span %s to have parent %s, got %s", name, parentID, span.Parent.SpanID())
+				}
+				parentID = span.SpanContext.SpanID().String()
+			}
+
+			// The LoanPro request carries the trace context on to the API only when
+			// propagation is enabled
+			mu.Lock()
+			defer mu.Unlock()
+			if len(outbound) == 0 {
+				t.Fatal("Expected a LoanPro request")
+			}
+			expected := ""
+			if test.propagate {
+				expected = "00-" + test.traceID + "-" + parentID + "-01"
+			}
+			if outbound[0] != expected {
+				t.Errorf("Expected outbound traceparent %s, got %s", expected, outbound[0])
+			}
+		})
+	}
+}
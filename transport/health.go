@@ -0,0 +1,75 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Readiness check results
+const (
+	CheckOK   = "ok"
+	CheckFail = "fail"
+)
+
+// Check is the result of one readiness check
+type Check struct {
+	Status  string         `json:"status"`            // CheckOK or CheckFail
+	Message string         `json:"message,omitempty"` // Why the check failed, or a note on a passing one
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// ReadinessChecker is implemented by handlers that check their configuration and
+// dependencies before the server is sent traffic
+type ReadinessChecker interface {
+	CheckReadiness(ctx context.Context) map[string]Check
+}
+
+// sessionCheck reports whether a transport has room for more sessions. A max of 0
+// means no limit.
+func sessionCheck(active, max int) Check {
+	check := Check{Status: CheckOK, Details: map[string]any{"active": active}}
+	if max > 0 {
+		check.Details["max"] = max
+		if active >= max {
+			check.Status = CheckFail
+			check.Message = fmt.Sprintf("%d of %d sessions in use", active, max)
+		}
+	}
+	return check
+}
+
+// serveLivez reports that the process is up and serving requests
+func serveLivez(w http.ResponseWriter, transport string) {
+	writeHealth(w, http.StatusOK, map[string]any{"status": "ok", "transport": transport})
+}
+
+// serveReadyz runs the handler's readiness checks, if any, alongside the transport's
+// session check. It responds 503 when any check fails, so load balancers and
+// Kubernetes stop sending the server traffic.
+func serveReadyz(w http.ResponseWriter, r *http.Request, handler MCPHandler, transport string, sessions Check) {
+	checks := map[string]Check{"sessions": sessions}
+	if checker, ok := handler.(ReadinessChecker); ok {
+		for name, check := range checker.CheckReadiness(r.Context()) {
+			checks[name] = check
+		}
+	}
+
+	status, code := "ready", http.StatusOK
+	for _, check := range checks {
+		if check.Status != CheckOK {
+			status, code = "not_ready", http.StatusServiceUnavailable
+		}
+	}
+	writeHealth(w, code, map[string]any{"status": status, "transport": transport, "checks": checks})
+}
+
+// writeHealth writes a probe response, which must never be cached
+func writeHealth(w http.ResponseWriter, code int, body map[string]any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
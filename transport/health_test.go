@@ -0,0 +1,107 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// readinessMCPHandler reports fixed readiness checks
+type readinessMCPHandler struct {
+	MockMCPHandler
+	checks map[string]Check
+}
+
+func (h *readinessMCPHandler) CheckReadiness(ctx context.Context) map[string]Check {
+	return h.checks
+}
+
+func TestHandleLivez(t *testing.T) {
+	handlers := map[string]http.HandlerFunc{
+		"http": NewHTTPTransport(createMockHandler()).HandleLivez,
+		"sse":  NewSSETransport(createMockHandler()).HandleLivez,
+	}
+	for name, handle := range handlers {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handle(w, httptest.NewRequest("GET", "/livez", nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status 200, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestHTTPTransport_HandleReadyz(t *testing.T) {
+	ok := Check{Status: CheckOK}
+	failed := Check{Status: CheckFail, Message: "API returned status 401"}
+
+	tests := []struct {
+		name           string
+		checks         map[string]Check
+		maxSessions    int
+		expectedCode   int
+		expectedStatus string
+	}{
+		{"all checks pass", map[string]Check{"loanpro": ok}, 0, http.StatusOK, "ready"},
+		{"handler check fails", map[string]Check{"loanpro": failed}, 0, http.StatusServiceUnavailable, "not_ready"},
+		{"room for sessions", map[string]Check{"loanpro": ok}, 2, http.StatusOK, "ready"},
+		{"sessions full", map[string]Check{"loanpro": ok}, 1, http.StatusServiceUnavailable, "not_ready"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := NewHTTPTransport(&readinessMCPHandler{checks: test.checks})
+			transport.SetMaxSessions(test.maxSessions)
+
+			// Start one session
+			requestBody, _ := json.Marshal(MCPRequest{JSONRPC: "2.0", Method: "initialize", ID: 1})
+			transport.HandleMCP(httptest.NewRecorder(), httptest.NewRequest("POST", "/mcp", bytes.NewReader(requestBody)))
+
+			w := httptest.NewRecorder()
+			transport.HandleReadyz(w, httptest.NewRequest("GET", "/readyz", nil))
+			if w.Code != test.expectedCode {
+				t.Errorf("Expected status %d, got %d", test.expectedCode, w.Code)
+			}
+
+			var body struct {
+				Status string           `json:"status"`
+				Checks map[string]Check `json:"checks"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if body.Status != test.expectedStatus {
+				t.Errorf("Expected status %s, got %s", test.expectedStatus, body.Status)
+			}
+			if body.Checks["loanpro"].Status != test.checks["loanpro"].Status || body.Checks["loanpro"].Message != test.checks["loanpro"].Message {
+				t.Errorf("Expected the handler's checks in the response, got %+v", body.Checks)
+			}
+			if body.Checks["sessions"].Details["active"] != float64(1) {
+				t.Errorf("Expected 1 active session, got %+v", body.Checks["sessions"])
+			}
+		})
+	}
+}
+
+func TestSSETransport_HandleReadyz(t *testing.T) {
+	// Handlers without readiness checks are ready while there's room for sessions
+	w := httptest.NewRecorder()
+	NewSSETransport(createMockHandler()).HandleReadyz(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+
+	// Each open connection is a session
+	full := NewSSETransport(createMockHandler())
+	full.SetMaxSessions(1)
+	full.connections.Store(1)
+	w = httptest.NewRecorder()
+	full.HandleReadyz(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 with every session in use, got %d", w.Code)
+	}
+}
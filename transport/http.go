@@ -1,32 +1,53 @@
 package transport
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
-	"os"
+
+	"loanpro-mcp-server/requestid"
 )
 
 // HTTPTransport handles MCP communication over streamable HTTP
 type HTTPTransport struct {
-	handler MCPHandler
+	handler      MCPHandler
+	sessions     *sessionTracker
+	maxSessions  int
+	maxBodyBytes int64
 }
 
 // NewHTTPTransport creates a new HTTP transport
 func NewHTTPTransport(handler MCPHandler) *HTTPTransport {
-	return &HTTPTransport{
-		handler: handler,
+	t := &HTTPTransport{
+		handler:  handler,
+		sessions: newSessionTracker(),
 	}
+	activeSessions.SetFunc(func() float64 { return float64(t.sessions.active()) }, "http")
+	return t
+}
+
+// SetMaxSessions sets the number of sessions at which /readyz reports the server
+// full. 0, the default, means no limit.
+func (t *HTTPTransport) SetMaxSessions(max int) {
+	t.maxSessions = max
+}
+
+// SetMaxBodyBytes limits the size of request bodies. Larger requests get a 413
+// response. 0, the default, means no limit.
+func (t *HTTPTransport) SetMaxBodyBytes(max int64) {
+	t.maxBodyBytes = max
 }
 
 // HandleMCP handles HTTP POST requests with MCP messages
 func (t *HTTPTransport) HandleMCP(w http.ResponseWriter, r *http.Request) {
 	// Set CORS headers
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-LoanPro-Tenant, "+SessionHeader)
+	w.Header().Set("Access-Control-Expose-Headers", SessionHeader+", "+requestid.Header)
 
 	// Handle preflight requests
 	if r.Method == "OPTIONS" {
@@ -34,40 +55,80 @@ func (t *HTTPTransport) HandleMCP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Clients end their session with DELETE
+	if r.Method == "DELETE" {
+		if session := r.Header.Get(SessionHeader); session != "" && !t.sessions.end(session) {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
 	if r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	// Every message gets an ID tying its logs, LoanPro calls and errors together
+	id := requestid.New()
+	ctx := requestid.WithID(r.Context(), id)
+	w.Header().Set(requestid.Header, id)
+
 	// Read request body
+	if t.maxBodyBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, t.maxBodyBytes)
+	}
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		slog.Error("Error reading HTTP request body", "error", err)
-		fmt.Fprintf(os.Stderr, "[ERROR] Failed to read HTTP request body: %v\n", err)
+		slog.ErrorContext(ctx, "Error reading HTTP request body", "error", err)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
 		http.Error(w, "Failed to read request body", http.StatusBadRequest)
 		return
 	}
 	defer r.Body.Close()
 
-	slog.Debug("Received HTTP request", "data", string(body))
+	slog.DebugContext(ctx, "Received HTTP request", "data", string(body))
 
 	// Parse MCP request
 	var req MCPRequest
 	if err := json.Unmarshal(body, &req); err != nil {
-		slog.Error("JSON parse error", "error", err, "input", string(body))
-		fmt.Fprintf(os.Stderr, "[ERROR] JSON parse error: %v\nInput: %s\n", err, string(body))
-		t.sendError(w, -32700, "Parse error", nil)
+		slog.ErrorContext(ctx, "JSON parse error", "error", err, "input", string(body))
+		observeParseError()
+		t.sendError(ctx, w, -32700, "Parse error", nil)
 		return
 	}
 
-	slog.Debug("Processing HTTP request", "method", req.Method, "id", req.ID)
+	slog.DebugContext(ctx, "Processing HTTP request", "method", req.Method, "id", req.ID)
+
+	// Start a session on initialize, otherwise continue the client's. Only sessions
+	// this server issued are accepted; clients start a new one on a 404.
+	session := r.Header.Get(SessionHeader)
+	switch {
+	case session == "" && req.Method == "initialize":
+		session = t.sessions.start()
+		w.Header().Set(SessionHeader, session)
+	case session != "" && !t.sessions.touch(session):
+		slog.WarnContext(ctx, "Unknown MCP session", "method", req.Method)
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	if session != "" {
+		ctx = WithSession(ctx, session)
+	}
+	ctx, span := startRequestSpan(ctx, "http", r.Header, req)
+	defer span.End()
 
 	// Handle the MCP request
-	response := t.handler.HandleMCPRequest(req)
+	response := handle(ctx, t.handler, req)
 
 	// Don't send response for notifications (empty JSONRPC means no response)
 	if response.JSONRPC == "" {
-		slog.Debug("Notification processed, no response sent")
+		slog.DebugContext(ctx, "Notification processed, no response sent")
 		w.WriteHeader(http.StatusOK)
 		return
 	}
@@ -76,13 +137,12 @@ func (t *HTTPTransport) HandleMCP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	responseData, err := json.Marshal(response)
 	if err != nil {
-		slog.Error("Marshal error", "error", err)
-		fmt.Fprintf(os.Stderr, "[ERROR] Failed to marshal response: %v\n", err)
-		t.sendError(w, -32603, "Internal error", req.ID)
+		slog.ErrorContext(ctx, "Marshal error", "error", err)
+		t.sendError(ctx, w, -32603, "Internal error", req.ID)
 		return
 	}
 
-	slog.Debug("Sending HTTP response", "data", string(responseData))
+	slog.DebugContext(ctx, "Sending HTTP response", "data", string(responseData))
 	w.WriteHeader(http.StatusOK)
 	w.Write(responseData)
 }
@@ -104,28 +164,46 @@ func (t *HTTPTransport) HandleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	json.NewEncoder(w).Encode(map[string]string{
+	health := map[string]any{
 		"status":    "healthy",
 		"transport": "http",
-	})
+	}
+	if reporter, ok := t.handler.(HealthReporter); ok {
+		for key, value := range reporter.HealthDetails() {
+			health[key] = value
+		}
+	}
+
+	json.NewEncoder(w).Encode(health)
+}
+
+// HandleLivez handles the liveness probe, which passes while the process serves requests
+func (t *HTTPTransport) HandleLivez(w http.ResponseWriter, r *http.Request) {
+	serveLivez(w, "http")
+}
+
+// HandleReadyz handles the readiness probe, which fails with 503 while the server
+// can't serve tool calls or has no room for more sessions
+func (t *HTTPTransport) HandleReadyz(w http.ResponseWriter, r *http.Request) {
+	serveReadyz(w, r, t.handler, "http", sessionCheck(t.sessions.active(), t.maxSessions))
 }
 
 // sendError sends an error response
-func (t *HTTPTransport) sendError(w http.ResponseWriter, code int, message string, id any) {
-	slog.Error("Sending HTTP error response", "code", code, "message", message, "id", id)
+func (t *HTTPTransport) sendError(ctx context.Context, w http.ResponseWriter, code int, message string, id any) {
+	slog.ErrorContext(ctx, "Sending HTTP error response", "code", code, "message", message, "id", id)
 
-	errorResponse := MCPResponse{
+	errorResponse := withRequestID(ctx, MCPResponse{
 		JSONRPC: "2.0",
 		Error: &MCPError{
 			Code:    code,
 			Message: message,
 		},
 		ID: id,
-	}
+	})
 
 	w.Header().Set("Content-Type", "application/json")
 	data, _ := json.Marshal(errorResponse)
-	slog.Debug("HTTP error response", "data", string(data))
+	slog.DebugContext(ctx, "HTTP error response", "data", string(data))
 	w.WriteHeader(http.StatusOK) // Still return 200 for JSON-RPC errors
 	w.Write(data)
 }
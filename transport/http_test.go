@@ -2,10 +2,15 @@ package transport
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"loanpro-mcp-server/requestid"
 )
 
 // MockMCPHandler implements the MCPHandler interface for testing
@@ -234,3 +239,253 @@ func TestHTTPTransport_HandleMCP_Notification(t *testing.T) {
 		t.Errorf("Expected empty response body for notification, got %s", w.Body.String())
 	}
 }
+
+type contextKey struct{}
+
+// contextMCPHandler echoes a request context value to check it reaches the handler
+type contextMCPHandler struct {
+	MockMCPHandler
+}
+
+func (h *contextMCPHandler) HandleMCPRequestContext(ctx context.Context, req MCPRequest) MCPResponse {
+	value, _ := ctx.Value(contextKey{}).(string)
+	return MCPResponse{JSONRPC: "2.0", Result: map[string]any{"value": value}, ID: req.ID}
+}
+
+func TestHTTPTransport_HandleMCP_Context(t *testing.T) {
+	transport := NewHTTPTransport(&contextMCPHandler{})
+
+	requestBody, _ := json.Marshal(MCPRequest{JSONRPC: "2.0", Method: "tools/list", ID: 1})
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewReader(requestBody))
+	req = req.WithContext(context.WithValue(req.Context(), contextKey{}, "from-middleware"))
+
+	w := httptest.NewRecorder()
+	transport.HandleMCP(w, req)
+
+	var response MCPResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if value := response.Result.(map[string]any)["value"]; value != "from-middleware" {
+		t.Errorf("Expected request context to reach the handler, got %v", value)
+	}
+}
+
+// sessionMCPHandler echoes the session ID of a request
+type sessionMCPHandler struct {
+	MockMCPHandler
+}
+
+func (h *sessionMCPHandler) HandleMCPRequestContext(ctx context.Context, req MCPRequest) MCPResponse {
+	return MCPResponse{JSONRPC: "2.0", Result: map[string]any{"session": SessionFromContext(ctx)}, ID: req.ID}
+}
+
+func TestHTTPTransport_HandleMCP_Session(t *testing.T) {
+	transport := NewHTTPTransport(&sessionMCPHandler{})
+
+	send := func(method, session string) (string, string) {
+		requestBody, _ := json.Marshal(MCPRequest{JSONRPC: "2.0", Method: method, ID: 1})
+		req := httptest.NewRequest("POST", "/mcp", bytes.NewReader(requestBody))
+		if session != "" {
+			req.Header.Set(SessionHeader, session)
+		}
+		w := httptest.NewRecorder()
+		transport.HandleMCP(w, req)
+
+		var response MCPResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return w.Header().Get(SessionHeader), response.Result.(map[string]any)["session"].(string)
+	}
+
+	assigned, seen := send("initialize", "")
+	if len(assigned) != 32 || seen != assigned {
+		t.Errorf("Expected initialize to start a session, got header %q and context %q", assigned, seen)
+	}
+	if header, seen := send("tools/list", assigned); header != "" || seen != assigned {
+		t.Errorf("Expected the client's session to be used, got header %q and context %q", header, seen)
+	}
+	if _, seen := send("tools/list", ""); seen != "" {
+		t.Errorf("Expected no session without the header, got %q", seen)
+	}
+}
+
+func TestSessionTracker(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	tracker := newSessionTracker()
+	tracker.now = func() time.Time { return now }
+
+	a := tracker.start()
+	b := tracker.start()
+	tracker.start()
+	tracker.end(b)
+	if active := tracker.active(); active != 2 {
+		t.Errorf("Expected 2 active sessions, got %d", active)
+	}
+	if tracker.touch(b) || tracker.touch("never-issued") {
+		t.Error("Expected ended and unissued sessions to be unknown")
+	}
+
+	now = now.Add(20 * time.Minute)
+	if !tracker.touch(a) {
+		t.Error("Expected an issued session to be known")
+	}
+	now = now.Add(20 * time.Minute)
+	if active := tracker.active(); active != 1 {
+		t.Errorf("Expected the idle session to expire, got %d active", active)
+	}
+}
+
+func TestHTTPTransport_HandleMCP_UnknownSession(t *testing.T) {
+	transport := NewHTTPTransport(createMockHandler())
+	transport.SetMaxSessions(1)
+
+	// Made-up session IDs are refused and don't use up capacity
+	for i := range 5 {
+		requestBody, _ := json.Marshal(MCPRequest{JSONRPC: "2.0", Method: "tools/list", ID: 1})
+		req := httptest.NewRequest("POST", "/mcp", bytes.NewReader(requestBody))
+		req.Header.Set(SessionHeader, fmt.Sprintf("made-up-%d", i))
+		w := httptest.NewRecorder()
+		transport.HandleMCP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for an unknown session, got %d", w.Code)
+		}
+	}
+	if active := transport.sessions.active(); active != 0 {
+		t.Errorf("Expected no active sessions, got %d", active)
+	}
+
+	w := httptest.NewRecorder()
+	transport.HandleReadyz(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected unknown sessions not to change readiness, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req := httptest.NewRequest("DELETE", "/mcp", nil)
+	req.Header.Set(SessionHeader, "made-up-0")
+	w = httptest.NewRecorder()
+	transport.HandleMCP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 ending an unknown session, got %d", w.Code)
+	}
+}
+
+func TestHTTPTransport_HandleMCP_DeleteSession(t *testing.T) {
+	transport := NewHTTPTransport(createMockHandler())
+	session := transport.sessions.start()
+
+	req := httptest.NewRequest("DELETE", "/mcp", nil)
+	req.Header.Set(SessionHeader, session)
+	w := httptest.NewRecorder()
+	transport.HandleMCP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if active := transport.sessions.active(); active != 0 {
+		t.Errorf("Expected the session to end, got %d active", active)
+	}
+}
+
+func TestHTTPTransport_HandleMCP_RequestID(t *testing.T) {
+	transport := NewHTTPTransport(createMockHandler())
+
+	send := func(body []byte) (string, MCPResponse) {
+		req := httptest.NewRequest("POST", "/mcp", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		transport.HandleMCP(w, req)
+
+		var response MCPResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return w.Header().Get(requestid.Header), response
+	}
+
+	unknown, _ := json.Marshal(MCPRequest{JSONRPC: "2.0", Method: "tools/unknown", ID: 1})
+	valid, _ := json.Marshal(MCPRequest{JSONRPC: "2.0", Method: "tools/list", ID: 2})
+	tests := []struct {
+		name      string
+		body      []byte
+		expectErr bool
+	}{
+		{"handler error", unknown, true},
+		{"parse error", []byte("invalid json"), true},
+		{"success", valid, false},
+	}
+
+	seen := map[string]bool{}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, response := send(test.body)
+			if id == "" || seen[id] {
+				t.Fatalf("Expected a new request ID header, got %q", id)
+			}
+			seen[id] = true
+
+			if !test.expectErr {
+				if response.Error != nil {
+					t.Errorf("Expected no error, got %v", response.Error)
+				}
+				return
+			}
+			if response.Error == nil {
+				t.Fatal("Expected an error")
+			}
+			data, _ := response.Error.Data.(map[string]any)
+			if data[requestid.LogKey] != id {
+				t.Errorf("Expected error data to carry request ID %s, got %v", id, response.Error.Data)
+			}
+		})
+	}
+}
+
+func TestWithRequestID(t *testing.T) {
+	ctx := requestid.WithID(context.Background(), "abc123")
+
+	existing := &MCPError{Code: -1, Message: "failed", Data: map[string]any{"retry": true}}
+	response := withRequestID(ctx, MCPResponse{Error: existing})
+	data := response.Error.Data.(map[string]any)
+	if data["request_id"] != "abc123" || data["retry"] != true {
+		t.Errorf("Expected request ID added to existing data, got %v", data)
+	}
+	if _, ok := existing.Data.(map[string]any)["request_id"]; ok {
+		t.Error("Expected the handler's error to be left unchanged")
+	}
+
+	response = withRequestID(ctx, MCPResponse{Error: &MCPError{Code: -1, Data: "opaque"}})
+	if response.Error.Data != "opaque" {
+		t.Errorf("Expected data of another shape to be kept, got %v", response.Error.Data)
+	}
+
+	response = withRequestID(context.Background(), MCPResponse{Error: &MCPError{Code: -1}})
+	if response.Error.Data != nil {
+		t.Errorf("Expected no data outside a request, got %v", response.Error.Data)
+	}
+}
+
+func TestHTTPTransport_HandleMCP_MaxBodyBytes(t *testing.T) {
+	transport := NewHTTPTransport(createMockHandler())
+	requestBody, _ := json.Marshal(MCPRequest{JSONRPC: "2.0", Method: "tools/list", ID: 1})
+	transport.SetMaxBodyBytes(int64(len(requestBody)))
+
+	tests := []struct {
+		name         string
+		body         []byte
+		expectedCode int
+	}{
+		{"within limit", requestBody, http.StatusOK},
+		{"over limit", append(requestBody, ' '), http.StatusRequestEntityTooLarge},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			transport.HandleMCP(w, httptest.NewRequest("POST", "/mcp", bytes.NewReader(test.body)))
+			if w.Code != test.expectedCode {
+				t.Errorf("Expected status %d, got %d", test.expectedCode, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package transport
+
+import (
+	"slices"
+
+	"loanpro-mcp-server/metrics"
+)
+
+var (
+	mcpRequests = metrics.Default.NewCounter("mcp_requests_total",
+		"MCP requests by JSON-RPC method and status (ok or error).", "method", "status")
+	activeSessions = metrics.Default.NewGauge("mcp_active_sessions",
+		"Open MCP sessions by transport.", "transport")
+)
+
+// knownMethods are the JSON-RPC methods counted under their own name; others are
+// counted as "other" so clients can't create unbounded label values
+var knownMethods = []string{
+	"initialize", "initialized", "notifications/initialized", "ping",
+	"tools/list", "tools/call", "resources/list", "prompts/list",
+}
+
+// methodLabel returns the metric label for a JSON-RPC method
+func methodLabel(method string) string {
+	if slices.Contains(knownMethods, method) {
+		return method
+	}
+	return "other"
+}
+
+// observeRequest counts a handled MCP request
+func observeRequest(req MCPRequest, response MCPResponse) {
+	status := "ok"
+	if response.Error != nil {
+		status = "error"
+	}
+	mcpRequests.Inc(methodLabel(req.Method), status)
+}
+
+// observeParseError counts a message that couldn't be parsed as a request
+func observeParseError() {
+	mcpRequests.Inc("invalid", "error")
+}
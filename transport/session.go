@@ -0,0 +1,107 @@
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// SessionHeader carries the MCP session ID over streamable HTTP. The server assigns
+// one in its initialize response and clients send it with later requests.
+const SessionHeader = "Mcp-Session-Id"
+
+// sessionContextKey is the context key for the MCP session ID
+type sessionContextKey struct{}
+
+// WithSession attaches an MCP session ID to ctx
+func WithSession(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, id)
+}
+
+// SessionFromContext returns the MCP session ID of a request, or an empty string
+// when the client didn't send one
+func SessionFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(sessionContextKey{}).(string)
+	return id
+}
+
+// NewSessionID returns a random session ID
+func NewSessionID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// sessionIdleTimeout is how long an HTTP session stays active without requests.
+// Streamable HTTP clients needn't end their sessions, so idle ones are dropped.
+const sessionIdleTimeout = 30 * time.Minute
+
+// sessionTracker keeps the HTTP sessions the server issued and that were used
+// recently, for the active sessions metric and to reject unknown session IDs
+type sessionTracker struct {
+	mu        sync.Mutex
+	lastSeen  map[string]time.Time
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+// newSessionTracker creates an empty session tracker
+func newSessionTracker() *sessionTracker {
+	return &sessionTracker{lastSeen: make(map[string]time.Time), now: time.Now}
+}
+
+// start issues a new session and marks it active
+func (st *sessionTracker) start() string {
+	id := NewSessionID()
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.lastSeen[id] = st.now()
+	return id
+}
+
+// touch marks a session as active. It reports false, recording nothing, for IDs
+// the server didn't issue or that went idle.
+func (st *sessionTracker) touch(id string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	now := st.now()
+	if now.Sub(st.lastPrune) > time.Minute {
+		st.prune(now)
+	}
+	seen, ok := st.lastSeen[id]
+	if !ok || now.Sub(seen) > sessionIdleTimeout {
+		delete(st.lastSeen, id)
+		return false
+	}
+	st.lastSeen[id] = now
+	return true
+}
+
+// end forgets a session the client ended. It reports whether the session was active.
+func (st *sessionTracker) end(id string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	_, ok := st.lastSeen[id]
+	delete(st.lastSeen, id)
+	return ok
+}
+
+// active returns the number of sessions with requests in the idle timeout
+func (st *sessionTracker) active() int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.prune(st.now())
+	return len(st.lastSeen)
+}
+
+// prune drops idle sessions. st.mu must be held.
+func (st *sessionTracker) prune(now time.Time) {
+	for id, seen := range st.lastSeen {
+		if now.Sub(seen) > sessionIdleTimeout {
+			delete(st.lastSeen, id)
+		}
+	}
+	st.lastPrune = now
+}
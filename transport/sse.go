@@ -1,25 +1,67 @@
 package transport
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"loanpro-mcp-server/requestid"
 )
 
 // SSETransport handles MCP communication over Server-Sent Events
 type SSETransport struct {
-	handler MCPHandler
+	handler     MCPHandler
+	connections atomic.Int64
+	maxSessions int
+	maxMessage  int64
+
+	// shutdown is closed to end every stream when the server shuts down
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
 }
 
 // NewSSETransport creates a new SSE transport
 func NewSSETransport(handler MCPHandler) *SSETransport {
 	return &SSETransport{
-		handler: handler,
+		handler:  handler,
+		shutdown: make(chan struct{}),
 	}
 }
 
+// SetMaxSessions sets the number of connections at which /readyz reports the
+// server full. 0, the default, means no limit.
+func (t *SSETransport) SetMaxSessions(max int) {
+	t.maxSessions = max
+}
+
+// SetMaxBodyBytes limits the size of each request read from a stream's body. A
+// larger request stops reading the stream. 0, the default, means no limit.
+func (t *SSETransport) SetMaxBodyBytes(max int64) {
+	t.maxMessage = max
+}
+
+// Shutdown sends a shutdown event on every open stream and ends it once its
+// current request, if any, has been answered. Later connections are refused.
+func (t *SSETransport) Shutdown() {
+	t.shutdownOnce.Do(func() { close(t.shutdown) })
+}
+
 // HandleSSE handles SSE connections for MCP communication
 func (t *SSETransport) HandleSSE(w http.ResponseWriter, r *http.Request) {
+	select {
+	case <-t.shutdown:
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+		return
+	default:
+	}
+
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
@@ -31,30 +73,103 @@ func (t *SSETransport) HandleSSE(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Streams stay open past the server's read and write timeouts, and read
+	// requests from the body while responses are written
+	controller := http.NewResponseController(w)
+	controller.EnableFullDuplex()
+	controller.SetReadDeadline(time.Time{})
+	controller.SetWriteDeadline(time.Time{})
+
+	// Each connection is a session
+	ctx := WithSession(r.Context(), NewSessionID())
+	t.connections.Add(1)
+	defer t.connections.Add(-1)
+	activeSessions.Add(1, "sse")
+	defer activeSessions.Add(-1, "sse")
+
 	fmt.Fprintf(w, "event: ready\n")
 	fmt.Fprintf(w, "data: {\"type\":\"ready\"}\n\n")
 	flusher.Flush()
 
+	// Requests are read in the background so the stream can end while waiting for
+	// one. Reading starts once the headers are written, which needs the body.
+	requests := make(chan MCPRequest)
+	go readRequests(ctx, r.Body, t.maxMessage, requests)
+
 	for {
 		select {
-		case <-r.Context().Done():
+		case <-ctx.Done():
 			return
-		default:
-			var req MCPRequest
-			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-				continue
-			}
-
-			response := t.handler.HandleMCPRequest(req)
+		case <-t.shutdown:
+			fmt.Fprintf(w, "event: shutdown\n")
+			fmt.Fprintf(w, "data: {\"type\":\"shutdown\"}\n\n")
+			flusher.Flush()
+			return
+		case req := <-requests:
+			requestCtx, span := startRequestSpan(requestid.WithID(ctx, requestid.New()), "sse", nil, req)
+			response := handle(requestCtx, t.handler, req)
 			data, _ := json.Marshal(response)
 
 			fmt.Fprintf(w, "event: message\n")
 			fmt.Fprintf(w, "data: %s\n\n", data)
 			flusher.Flush()
+			span.End()
+		}
+	}
+}
+
+// readRequests decodes requests from a stream's body until it ends, is invalid,
+// a request exceeds maxMessage bytes, or the stream closes
+func readRequests(ctx context.Context, body io.Reader, maxMessage int64, requests chan<- MCPRequest) {
+	limited := &messageLimitReader{r: body, max: maxMessage}
+	decoder := json.NewDecoder(limited)
+	for {
+		limited.reset()
+		var req MCPRequest
+		if err := decoder.Decode(&req); err != nil {
+			if errors.Is(err, errMessageTooLarge) {
+				slog.WarnContext(ctx, "SSE request too large, closing the request stream", "max_bytes", maxMessage)
+			}
+			return
+		}
+		select {
+		case requests <- req:
+		case <-ctx.Done():
+			return
 		}
 	}
 }
 
+var errMessageTooLarge = errors.New("request too large")
+
+// messageLimitReader fails once more than max bytes are read since the last reset,
+// which bounds how much a single decode buffers. The decoder may have read ahead
+// into the next request, so its buffer stays under twice max. 0 means no limit.
+type messageLimitReader struct {
+	r    io.Reader
+	max  int64
+	read int64
+}
+
+func (m *messageLimitReader) reset() {
+	m.read = 0
+}
+
+func (m *messageLimitReader) Read(p []byte) (int, error) {
+	if m.max <= 0 {
+		return m.r.Read(p)
+	}
+	if m.read >= m.max {
+		return 0, errMessageTooLarge
+	}
+	if remaining := m.max - m.read; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := m.r.Read(p)
+	m.read += int64(n)
+	return n, err
+}
+
 // HandleRoot handles the root endpoint for server info
 func (t *SSETransport) HandleRoot(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -64,3 +179,14 @@ func (t *SSETransport) HandleRoot(w http.ResponseWriter, r *http.Request) {
 		"transport": "sse",
 	})
 }
+
+// HandleLivez handles the liveness probe, which passes while the process serves requests
+func (t *SSETransport) HandleLivez(w http.ResponseWriter, r *http.Request) {
+	serveLivez(w, "sse")
+}
+
+// HandleReadyz handles the readiness probe, which fails with 503 while the server
+// can't serve tool calls or has no room for more connections
+func (t *SSETransport) HandleReadyz(w http.ResponseWriter, r *http.Request) {
+	serveReadyz(w, r, t.handler, "sse", sessionCheck(int(t.connections.Load()), t.maxSessions))
+}
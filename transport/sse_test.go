@@ -0,0 +1,114 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readEvent reads the next event from an SSE stream and returns its name and data
+func readEvent(t *testing.T, reader *bufio.Reader) (string, string) {
+	t.Helper()
+	var event, data string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read event: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			return event, data
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestSSETransport_Shutdown(t *testing.T) {
+	sseTransport := NewSSETransport(createMockHandler())
+	server := httptest.NewServer(http.HandlerFunc(sseTransport.HandleSSE))
+	defer server.Close()
+
+	// Requests are streamed in the body of the GET request
+	body, requests := io.Pipe()
+	defer requests.Close()
+	req, _ := http.NewRequest("GET", server.URL, body)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	stream := bufio.NewReader(resp.Body)
+
+	if event, _ := readEvent(t, stream); event != "ready" {
+		t.Fatalf("Expected ready event, got %s", event)
+	}
+
+	// A request is answered on the stream
+	request, _ := json.Marshal(MCPRequest{JSONRPC: "2.0", Method: "tools/list", ID: 1})
+	requests.Write(request)
+	event, data := readEvent(t, stream)
+	if event != "message" || !strings.Contains(data, "test_tool") {
+		t.Errorf("Expected tools/list response, got %s %s", event, data)
+	}
+
+	// Shutdown tells the client and ends the stream while it waits for a request
+	sseTransport.Shutdown()
+	if event, _ := readEvent(t, stream); event != "shutdown" {
+		t.Errorf("Expected shutdown event, got %s", event)
+	}
+	if rest, _ := io.ReadAll(stream); len(rest) != 0 {
+		t.Errorf("Expected the stream to end, got %q", rest)
+	}
+
+	// New streams are refused
+	resp, err = http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 after shutdown, got %d", resp.StatusCode)
+	}
+}
+
+func TestReadRequests_MaxMessage(t *testing.T) {
+	small := `{"jsonrpc":"2.0","method":"tools/list","id":1}`
+	large := `{"jsonrpc":"2.0","method":"tools/call","params":{"padding":"` + strings.Repeat("x", 200) + `"},"id":2}`
+	tests := []struct {
+		name       string
+		body       string
+		maxMessage int64
+		expected   []any
+	}{
+		{"no limit", small + large + small, 0, []any{float64(1), float64(2), float64(1)}},
+		{"each request under the limit", small + small + small, 64, []any{float64(1), float64(1), float64(1)}},
+		{"request over the limit stops reading", small + large + small, 64, []any{float64(1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := make(chan MCPRequest)
+			go func() {
+				readRequests(context.Background(), strings.NewReader(tt.body), tt.maxMessage, requests)
+				close(requests)
+			}()
+			var ids []any
+			for req := range requests {
+				ids = append(ids, req.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected requests %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
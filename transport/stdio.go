@@ -2,11 +2,15 @@ package transport
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"time"
+
+	"loanpro-mcp-server/requestid"
 )
 
 // StdioTransport handles MCP communication over stdin/stdout
@@ -25,66 +29,138 @@ func NewStdioTransport(handler MCPHandler) *StdioTransport {
 	}
 }
 
-// Run starts the stdio transport loop
-func (t *StdioTransport) Run() error {
+// stdinLine is a line read from stdin, or the error that ended reading
+type stdinLine struct {
+	data []byte
+	err  error
+}
+
+// Run reads requests from stdin and answers them until stdin closes or ctx is
+// cancelled. On cancellation it stops reading and gives the request in flight up
+// to shutdownTimeout to finish before cancelling it.
+func (t *StdioTransport) Run(ctx context.Context, shutdownTimeout time.Duration) error {
 	slog.Debug("Starting stdio transport")
+	// The process serves a single client, so the whole run is one session. Requests
+	// don't inherit ctx's cancellation so the one in flight can finish draining.
+	requestsCtx, cancelRequests := context.WithCancel(WithSession(context.WithoutCancel(ctx), NewSessionID()))
+	defer cancelRequests()
+	activeSessions.Set(1, "stdio")
+	defer activeSessions.Set(0, "stdio")
+
+	// Reads block, so they happen in the background where a shutdown can stop
+	// waiting for them
+	lines := make(chan stdinLine)
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		for {
+			data, err := t.reader.ReadBytes('\n')
+			select {
+			case lines <- stdinLine{data, err}:
+			case <-stopped:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
 	for {
-		line, err := t.reader.ReadBytes('\n')
-		if err != nil {
-			if err == io.EOF {
+		var line stdinLine
+		select {
+		case line = <-lines:
+		case <-ctx.Done():
+			slog.Info("Stopped reading stdin")
+			return nil
+		}
+		if line.err != nil {
+			if line.err == io.EOF {
 				slog.Debug("EOF received, shutting down")
 				return nil
 			}
-			slog.Error("Error reading from stdin", "error", err)
-			fmt.Fprintf(os.Stderr, "[ERROR] Failed to read from stdin: %v\n", err)
-			return fmt.Errorf("failed to read from stdin: %w", err)
+			slog.Error("Error reading from stdin", "error", line.err)
+			return fmt.Errorf("failed to read from stdin: %w", line.err)
 		}
 
-		slog.Debug("Received message", "data", string(line))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			t.handleLine(requestsCtx, line.data)
+		}()
 
-		var req MCPRequest
-		if err := json.Unmarshal(line, &req); err != nil {
-			slog.Error("JSON parse error", "error", err, "input", string(line))
-			fmt.Fprintf(os.Stderr, "[ERROR] JSON parse error: %v\nInput: %s\n", err, string(line))
-			t.sendError(-32700, "Parse error", nil)
-			continue
+		select {
+		case <-done:
+		case <-ctx.Done():
+			slog.Info("Stopped reading stdin, waiting for the request in flight", "timeout", shutdownTimeout)
+			timer := time.NewTimer(shutdownTimeout)
+			defer timer.Stop()
+			select {
+			case <-done:
+			case <-timer.C:
+				slog.Warn("Request still in flight after the shutdown timeout, cancelling it")
+				cancelRequests()
+			}
+			return nil
 		}
+	}
+}
 
-		slog.Debug("Processing request", "method", req.Method, "id", req.ID)
-		response := t.handler.HandleMCPRequest(req)
+// handleLine parses a line read from stdin and answers the request in it
+func (t *StdioTransport) handleLine(ctx context.Context, line []byte) {
+	// Every message gets an ID tying its logs, LoanPro calls and errors together
+	ctx = requestid.WithID(ctx, requestid.New())
+	slog.DebugContext(ctx, "Received message", "data", string(line))
 
-		// Don't send response for notifications (empty JSONRPC means no response)
-		if response.JSONRPC == "" {
-			slog.Debug("Notification processed, no response sent")
-			continue
-		}
+	var req MCPRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		slog.ErrorContext(ctx, "JSON parse error", "error", err, "input", string(line))
+		observeParseError()
+		t.sendError(ctx, -32700, "Parse error", nil)
+		return
+	}
 
-		responseData, err := json.Marshal(response)
-		if err != nil {
-			slog.Error("Marshal error", "error", err)
-			fmt.Fprintf(os.Stderr, "[ERROR] Failed to marshal response: %v\n", err)
-			t.sendError(-32603, "Internal error", req.ID)
-			continue
-		}
+	t.respond(ctx, req)
+}
+
+// respond handles a request and writes its response
+func (t *StdioTransport) respond(ctx context.Context, req MCPRequest) {
+	ctx, span := startRequestSpan(ctx, "stdio", nil, req)
+	defer span.End()
 
-		slog.Debug("Sending response", "data", string(responseData))
-		fmt.Fprintf(t.writer, "%s\n", responseData)
+	slog.DebugContext(ctx, "Processing request", "method", req.Method, "id", req.ID)
+	response := handle(ctx, t.handler, req)
+
+	// Don't send response for notifications (empty JSONRPC means no response)
+	if response.JSONRPC == "" {
+		slog.DebugContext(ctx, "Notification processed, no response sent")
+		return
+	}
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		slog.ErrorContext(ctx, "Marshal error", "error", err)
+		t.sendError(ctx, -32603, "Internal error", req.ID)
+		return
 	}
+
+	slog.DebugContext(ctx, "Sending response", "data", string(responseData))
+	fmt.Fprintf(t.writer, "%s\n", responseData)
 }
 
 // sendError sends an error response
-func (t *StdioTransport) sendError(code int, message string, id any) {
-	slog.Error("Sending error response", "code", code, "message", message, "id", id)
-	errorResponse := MCPResponse{
+func (t *StdioTransport) sendError(ctx context.Context, code int, message string, id any) {
+	slog.ErrorContext(ctx, "Sending error response", "code", code, "message", message, "id", id)
+	errorResponse := withRequestID(ctx, MCPResponse{
 		JSONRPC: "2.0",
 		Error: &MCPError{
 			Code:    code,
 			Message: message,
 		},
 		ID: id,
-	}
+	})
 
 	data, _ := json.Marshal(errorResponse)
-	slog.Debug("Error response", "data", string(data))
+	slog.DebugContext(ctx, "Error response", "data", string(data))
 	fmt.Fprintf(t.writer, "%s\n", data)
 }
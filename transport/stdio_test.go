@@ -0,0 +1,137 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowMCPHandler answers a request once released, reporting when it starts and
+// whether its context was cancelled
+type slowMCPHandler struct {
+	MockMCPHandler
+	started   chan struct{}
+	release   chan struct{}
+	cancelled chan struct{}
+}
+
+func newSlowMCPHandler() *slowMCPHandler {
+	return &slowMCPHandler{
+		started:   make(chan struct{}, 1),
+		release:   make(chan struct{}),
+		cancelled: make(chan struct{}, 1),
+	}
+}
+
+func (h *slowMCPHandler) HandleMCPRequestContext(ctx context.Context, req MCPRequest) MCPResponse {
+	h.started <- struct{}{}
+	select {
+	case <-h.release:
+		return MCPResponse{JSONRPC: "2.0", Result: map[string]any{"method": req.Method}, ID: req.ID}
+	case <-ctx.Done():
+		h.cancelled <- struct{}{}
+		return MCPResponse{JSONRPC: "2.0", Error: &MCPError{Code: -32603, Message: "cancelled"}, ID: req.ID}
+	}
+}
+
+// startStdio runs a stdio transport for handler and returns the pipe its requests
+// are written to and the channel Run's result is sent on
+func startStdio(ctx context.Context, handler MCPHandler, output io.Writer, shutdownTimeout time.Duration) (*io.PipeWriter, chan error) {
+	input, requests := io.Pipe()
+	transport := &StdioTransport{handler: handler, reader: bufio.NewReader(input), writer: output}
+	result := make(chan error, 1)
+	go func() { result <- transport.Run(ctx, shutdownTimeout) }()
+	return requests, result
+}
+
+func TestStdioTransport_Run(t *testing.T) {
+	var output bytes.Buffer
+	requests, result := startStdio(context.Background(), createMockHandler(), &output, time.Second)
+
+	request, _ := json.Marshal(MCPRequest{JSONRPC: "2.0", Method: "tools/list", ID: 1})
+	requests.Write(append(request, '\n'))
+	requests.Write([]byte("not json\n"))
+	requests.Close()
+
+	if err := <-result; err != nil {
+		t.Fatalf("Expected no error at EOF, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 responses, got %d: %s", len(lines), output.String())
+	}
+	if !strings.Contains(lines[0], "test_tool") {
+		t.Errorf("Expected tools/list response, got %s", lines[0])
+	}
+	if !strings.Contains(lines[1], "-32700") {
+		t.Errorf("Expected parse error, got %s", lines[1])
+	}
+}
+
+func TestStdioTransport_Run_DrainsOnShutdown(t *testing.T) {
+	handler := newSlowMCPHandler()
+	var output bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	requests, result := startStdio(ctx, handler, &output, time.Minute)
+	defer requests.Close()
+
+	request, _ := json.Marshal(MCPRequest{JSONRPC: "2.0", Method: "tools/call", ID: 1})
+	requests.Write(append(request, '\n'))
+	<-handler.started
+
+	// The signal arrives while the tool call is running
+	cancel()
+	select {
+	case err := <-result:
+		t.Fatalf("Expected Run to wait for the request in flight, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Lines sent after the signal aren't read
+	go requests.Write(append(request, '\n'))
+
+	close(handler.release)
+	if err := <-result; err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var response MCPResponse
+	if err := json.Unmarshal(output.Bytes(), &response); err != nil {
+		t.Fatalf("Expected a single response, got %q: %v", output.String(), err)
+	}
+	if response.Error != nil || response.Result.(map[string]any)["method"] != "tools/call" {
+		t.Errorf("Expected the tool call to finish, got %+v", response)
+	}
+	select {
+	case <-handler.started:
+		t.Error("Expected no request to start after the signal")
+	default:
+	}
+}
+
+func TestStdioTransport_Run_ShutdownTimeout(t *testing.T) {
+	handler := newSlowMCPHandler()
+	ctx, cancel := context.WithCancel(context.Background())
+	requests, result := startStdio(ctx, handler, io.Discard, 10*time.Millisecond)
+	defer requests.Close()
+
+	request, _ := json.Marshal(MCPRequest{JSONRPC: "2.0", Method: "tools/call", ID: 1})
+	requests.Write(append(request, '\n'))
+	<-handler.started
+	cancel()
+
+	if err := <-result; err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	select {
+	case <-handler.cancelled:
+	case <-time.After(time.Second):
+		t.Error("Expected the request in flight to be cancelled after the timeout")
+	}
+}
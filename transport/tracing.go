@@ -0,0 +1,78 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"loanpro-mcp-server/redact"
+)
+
+// tracer creates the spans of MCP requests
+var tracer = otel.Tracer("loanpro-mcp-server/transport")
+
+// metaCarrier reads and writes trace context in the _meta object of a request's
+// params, as sent by MCP clients that have no headers, e.g. over stdio
+type metaCarrier map[string]any
+
+// Get returns the value of a _meta key
+func (c metaCarrier) Get(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+
+// Set sets a _meta key
+func (c metaCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// Keys returns the _meta keys
+func (c metaCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// startRequestSpan starts the span of a request received by a transport. Its parent
+// is the W3C trace context sent in the request's _meta, or failing that in the HTTP
+// headers, if any.
+func startRequestSpan(ctx context.Context, transport string, header http.Header, req MCPRequest) (context.Context, trace.Span) {
+	propagator := otel.GetTextMapPropagator()
+	if header != nil {
+		ctx = propagator.Extract(ctx, propagation.HeaderCarrier(header))
+	}
+	if meta, ok := req.Params["_meta"].(map[string]any); ok {
+		ctx = propagator.Extract(ctx, metaCarrier(meta))
+	}
+
+	attributes := []attribute.KeyValue{attribute.String("mcp.transport", transport)}
+	if session := SessionFromContext(ctx); session != "" {
+		attributes = append(attributes, attribute.String("mcp.session.id", session))
+	}
+	return tracer.Start(ctx, transport+" "+methodLabel(req.Method),
+		trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attributes...))
+}
+
+// startHandlerSpan starts the span of a request's handling by the MCP handler
+func startHandlerSpan(ctx context.Context, req MCPRequest) (context.Context, trace.Span) {
+	return tracer.Start(ctx, methodLabel(req.Method), trace.WithAttributes(
+		attribute.String("rpc.system", "jsonrpc"),
+		attribute.String("rpc.method", methodLabel(req.Method)),
+	))
+}
+
+// endHandlerSpan records a response's error, if any, and ends the handler's span
+func endHandlerSpan(span trace.Span, response MCPResponse) {
+	if response.Error != nil {
+		span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", response.Error.Code))
+		span.SetStatus(codes.Error, redact.Default().String(response.Error.Message))
+	}
+	span.End()
+}
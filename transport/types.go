@@ -1,5 +1,12 @@
 package transport
 
+import (
+	"context"
+	"maps"
+
+	"loanpro-mcp-server/requestid"
+)
+
 // MCPRequest represents a request in the MCP protocol
 type MCPRequest struct {
 	JSONRPC string         `json:"jsonrpc"`
@@ -20,9 +27,59 @@ type MCPResponse struct {
 type MCPError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
 }
 
 // MCPHandler interface for handling MCP requests
 type MCPHandler interface {
 	HandleMCPRequest(req MCPRequest) MCPResponse
 }
+
+// ContextHandler is implemented by handlers that use the request context, e.g. for
+// values set by HTTP middleware. Transports prefer it over HandleMCPRequest.
+type ContextHandler interface {
+	HandleMCPRequestContext(ctx context.Context, req MCPRequest) MCPResponse
+}
+
+// handle dispatches a request to the handler, passing ctx when the handler accepts
+// it, and counts and traces the request
+func handle(ctx context.Context, handler MCPHandler, req MCPRequest) MCPResponse {
+	ctx, span := startHandlerSpan(ctx, req)
+	var response MCPResponse
+	if h, ok := handler.(ContextHandler); ok {
+		response = h.HandleMCPRequestContext(ctx, req)
+	} else {
+		response = handler.HandleMCPRequest(req)
+	}
+	observeRequest(req, response)
+	endHandlerSpan(span, response)
+	return withRequestID(ctx, response)
+}
+
+// withRequestID adds the request ID in ctx to the data of an error response, so a
+// failure reported by a client can be found in the logs
+func withRequestID(ctx context.Context, response MCPResponse) MCPResponse {
+	id := requestid.FromContext(ctx)
+	if response.Error == nil || id == "" {
+		return response
+	}
+	data := map[string]any{}
+	switch existing := response.Error.Data.(type) {
+	case nil:
+	case map[string]any:
+		data = maps.Clone(existing)
+	default:
+		// Data the handler set in another shape is passed on as is
+		return response
+	}
+	data[requestid.LogKey] = id
+	withID := *response.Error
+	withID.Data = data
+	response.Error = &withID
+	return response
+}
+
+// HealthReporter is implemented by handlers that add details to health checks
+type HealthReporter interface {
+	HealthDetails() map[string]any
+}